@@ -40,6 +40,7 @@ export function toResponsePreview(response?: ResponseData | null): ResponsePrevi
     timing: response.timing,
     size: response.size,
     assertions: response.assertions,
+    flow: response.flow,
     isBinary: response.isBinary,
     contentType: response.contentType
   };
@@ -101,7 +101,8 @@ export class RequestManagerComponent {
         return;
       }
 
-      if (request.depends) {
+      // @assert lines are evaluated in Go, so those requests go through the chain executor.
+      if (request.depends || request.assertions?.length) {
         await this.executeChainedRequest(requestIndex, request, envName, this.activeRequestId ?? undefined);
         return;
       }
@@ -266,7 +267,8 @@ export class RequestManagerComponent {
         variables,
         envName,
         requestId,
-        progress => this.requestProgress.emit(progress)
+        progress => this.requestProgress.emit(progress),
+        currentFile?.filePath
       );
       const metrics = this.httpService.calculateLoadTestMetrics(results);
 
@@ -6,6 +6,7 @@ import { WorkspaceStateService } from '../../services/workspace-state.service';
 import { RequestExecutionService } from '../../services/request-execution.service';
 
 import {
+  describeChainFlow,
   formatBytesForResponsePanel,
   getChainItemsForResponsePanel,
   getPreferredExpandedEntryId,
@@ -209,6 +210,10 @@ export class ResponsePanelComponent implements OnDestroy {
     return getStatusLabelForEntry(entry);
   }
 
+  describeFlow(entry: ChainEntryPreview): string {
+    return describeChainFlow(entry.response?.flow);
+  }
+
   formatSize(bytes: number): string {
     return formatBytesForResponsePanel(bytes);
   }
@@ -1,5 +1,6 @@
 import { ChainEntryPreview, Request, ResponseData } from '../../models/http.models';
 import {
+  describeChainFlow,
   formatBytesForResponsePanel,
   getChainItemsForResponsePanel,
   getPreferredExpandedEntryId,
@@ -157,6 +158,16 @@ describe('response-panel.logic', () => {
     });
   });
 
+  describe('describeChainFlow', () => {
+    it('renders flow decisions, runs and errors', () => {
+      expect(describeChainFlow(undefined)).toBe('');
+      expect(describeChainFlow({ skipped: true, skipReason: 'user exists' })).toBe('skipped: user exists');
+      expect(describeChainFlow({ stopped: true, next: 'b' })).toBe('chain stopped');
+      expect(describeChainFlow({ next: 'cleanup', runs: 3 })).toBe('next: cleanup · ran 3×');
+      expect(describeChainFlow({ error: 'chain.next(): no request named "x"' })).toBe('chain.next(): no request named "x"');
+    });
+  });
+
   describe('binary response preview', () => {
     it('passes isBinary and contentType through buildResponsePreview', () => {
       const responseData: ResponseData = {
@@ -1,4 +1,4 @@
-import { ChainEntryPreview, Request, RequestPreview, ResponseData, ResponsePreview } from '../../models/http.models';
+import { ChainEntryPreview, ChainFlow, Request, RequestPreview, ResponseData, ResponsePreview } from '../../models/http.models';
 
 export type EntryTab = 'response' | 'request';
 
@@ -75,6 +75,32 @@ export function getStatusLabelForEntry(entry: ChainEntryPreview): string {
   return `${entry.response.status} ${entry.response.statusText}`.trim();
 }
 
+// describeChainFlow renders a chain entry's flow state, e.g.
+// "skipped: user exists" or "ran 3× · next: cleanup".
+export function describeChainFlow(flow: ChainFlow | null | undefined): string {
+  if (!flow) {
+    return '';
+  }
+  const withReason = (label: string, reason?: string) => (reason?.trim() ? `${label}: ${reason.trim()}` : label);
+  const parts: string[] = [];
+  if (flow.skipped) {
+    parts.push(withReason('skipped', flow.skipReason));
+  }
+  if (flow.stopped) {
+    parts.push(withReason('chain stopped', flow.stopReason));
+  }
+  if (flow.next && !flow.stopped) {
+    parts.push(`next: ${flow.next}`);
+  }
+  if (flow.runs && flow.runs > 1) {
+    parts.push(`ran ${flow.runs}×`);
+  }
+  if (flow.error) {
+    parts.push(flow.error);
+  }
+  return parts.join(' · ');
+}
+
 export function formatBytesForResponsePanel(bytes: number): string {
   if (bytes === 0) return '0 B';
   const units = ['B', 'KB', 'MB', 'GB'];
@@ -115,6 +141,7 @@ function buildResponsePreview(response: ResponseData): ResponsePreview {
     timing: response.timing,
     size: response.size,
     assertions: response.assertions,
+    flow: response.flow,
     isBinary: response.isBinary,
     contentType: response.contentType
   };
@@ -11,9 +11,11 @@ export interface Request {
   loadTest?: LoadTestConfig;
   noHistory?: boolean;
   isMock?: boolean;
+  assertions?: string[];  // @assert expressions, evaluated by the backend
   options?: {
     timeout?: number;
     noRedirect?: boolean;
+    maxRuns?: number;  // @max-runs: chain.next() run limit for this request
   }
 }
 
@@ -44,6 +46,7 @@ export interface ResponsePreview {
   timing?: TimingBreakdown;
   size?: number;  // Response body size in bytes
   assertions?: AssertionResult[];
+  flow?: ChainFlow;
   isBinary?: boolean;
   contentType?: string;
 }
@@ -54,6 +57,18 @@ export interface AssertionResult {
   stage?: 'pre' | 'post' | 'custom' | string;
 }
 
+// Flow decisions a chain request's scripts made (request.skip, chain.stop,
+// chain.next), how often it ran and the error that ended the chain there.
+export interface ChainFlow {
+  skipped?: boolean;
+  skipReason?: string;
+  stopped?: boolean;
+  stopReason?: string;
+  next?: string;
+  runs?: number;
+  error?: string;
+}
+
 export interface ChainEntryPreview {
   id: string;
   label: string;
@@ -116,7 +131,8 @@ export interface LoadTestResults {
   totalRequests: number;
   successfulRequests: number;
   failedRequests: number;
-  responseTimes: number[];
+  responseTimes: number[];  // sample of at most 10,000 response times, for charts
+  latency?: LatencySummary;  // exact latency statistics for the whole run
   errors: any[];
   failureStatusCounts?: { [statusCode: string]: number };
   startTime: number;
@@ -130,6 +146,25 @@ export interface LoadTestResults {
   adaptive?: AdaptiveLoadTestSummary;
 }
 
+// Latency statistics computed by the backend from every response, in ms.
+export interface LatencySummary {
+  count: number;
+  avg: number;
+  min: number;
+  p50: number;
+  p90: number;
+  p95: number;
+  p99: number;
+  max: number;
+  histogram?: {
+    count: number;
+    sum: number;
+    min: number;
+    max: number;
+    buckets?: Array<[number, number]>;
+  };
+}
+
 export interface LoadTestMetrics {
   totalRequests: number;
   successfulRequests: number;
@@ -191,6 +226,7 @@ export interface ResponseData {
   requestPreview?: RequestPreview;
   chainItems?: ChainEntryPreview[];
   assertions?: AssertionResult[];
+  flow?: ChainFlow;
   isBinary?: boolean;
   contentType?: string;
 }
@@ -21,7 +21,10 @@ export interface BackendClientContract {
     url: string,
     headersJson: string,
     body: string,
-    loadConfigJson: string
+    loadConfigJson: string,
+    postScript?: string,
+    assertions?: string[],
+    requestFilePath?: string
   ): Promise<void>;
   setVariable(key: string, value: string): Promise<void>;
   getVariable(key: string): Promise<string>;
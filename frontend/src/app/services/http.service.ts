@@ -95,12 +95,13 @@ export class HttpService {
     variables: { [key: string]: string } = {},
     env?: string,
     requestId?: string,
-    onProgress?: (progress: ActiveRunProgress) => void
+    onProgress?: (progress: ActiveRunProgress) => void,
+    requestFilePath?: string
   ): Promise<LoadTestResults> {
-    return await executeLoadTestViaBackendHelper(request, variables, env, requestId, onProgress, {
+    return await executeLoadTestViaBackendHelper(request, variables, env, requestId, onProgress, requestFilePath, {
       backend: {
-        startLoadTest: (id, method, url, headersJson, body, loadTestJson) =>
-          this.backend.startLoadTest(id, method, url, headersJson, body, loadTestJson),
+        startLoadTest: (id, method, url, headersJson, body, loadTestJson, postScript, assertions, filePath) =>
+          this.backend.startLoadTest(id, method, url, headersJson, body, loadTestJson, postScript, assertions, filePath),
       },
       eventsOn: (event, callback) => this.events.on(event, callback),
       normalizeEnvName: (e) => this.normalizeEnvName(e),
@@ -225,12 +226,13 @@ export class HttpService {
     variables: { [key: string]: string } = {},
     env?: string,
     requestId?: string,
-    onProgress?: (progress: ActiveRunProgress) => void
+    onProgress?: (progress: ActiveRunProgress) => void,
+    requestFilePath?: string
   ): Promise<LoadTestResults> {
     if (!requestId) {
       throw new Error('Load testing requires a requestId');
     }
-    return await this.executeLoadTestViaBackend(request, variables, env, requestId, onProgress);
+    return await this.executeLoadTestViaBackend(request, variables, env, requestId, onProgress, requestFilePath);
   }
 
   // Calculate load test metrics
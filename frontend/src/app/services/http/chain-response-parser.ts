@@ -8,6 +8,8 @@ export type ChainResponseParserLogger = {
   error?: (...args: any[]) => void;
 };
 
+// The backend emits one entry per request in chain order (a request that
+// loops keeps only its latest run), so entries pair with previews by index.
 export function parseConcatenatedChainResponses(
   responseStr: string,
   previews: RequestPreview[],
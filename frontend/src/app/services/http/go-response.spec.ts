@@ -46,6 +46,19 @@ describe('go-response', () => {
     ]);
   });
 
+  it('parses chain flow state when present', () => {
+    const responseStr = [
+      'Status: 0 Skipped',
+      'Flow: {"skipped":true,"skipReason":"user exists","runs":2}',
+      'Body: skipped: user exists'
+    ].join('\n');
+
+    const r = parseGoResponse(responseStr, 0);
+    expect(r.statusText).toBe('Skipped');
+    expect(r.flow).toEqual({ skipped: true, skipReason: 'user exists', runs: 2 });
+    expect(r.body).toBe('skipped: user exists');
+  });
+
   it('treats unparseable content as Parse Error', () => {
     const r = parseGoResponse('totally not in the expected format', 1);
     expect(r.status).toBe(0);
@@ -1,4 +1,4 @@
-import { AssertionResult, ResponseData } from '../../models/http.models';
+import { AssertionResult, ChainFlow, ResponseData } from '../../models/http.models';
 
 export function parseGoResponse(responseStr: string, responseTime: number): ResponseData {
   // Check if this is an error response from Go backend
@@ -22,6 +22,7 @@ export function parseGoResponse(responseStr: string, responseTime: number): Resp
   let size: number | undefined;
   let requestPreview: { method: string; url: string; headers: { [key: string]: string }; body?: string } | undefined;
   let assertions: AssertionResult[] | undefined;
+  let flow: ChainFlow | undefined;
   let isBinary = false;
   let binaryContentType = '';
 
@@ -93,6 +94,15 @@ export function parseGoResponse(responseStr: string, responseTime: number): Resp
       } catch {
         // Ignore assertion parse errors.
       }
+    } else if (line.startsWith('Flow: ')) {
+      try {
+        const parsed = JSON.parse(line.substring(6).trim());
+        if (parsed && typeof parsed === 'object') {
+          flow = parsed as ChainFlow;
+        }
+      } catch {
+        // Ignore flow parse errors.
+      }
     }
   }
 
@@ -126,6 +136,10 @@ export function parseGoResponse(responseStr: string, responseTime: number): Resp
     responseData.assertions = assertions;
   }
 
+  if (flow) {
+    responseData.flow = flow;
+  }
+
 
   if (requestPreview?.method && requestPreview?.url) {
     responseData.requestPreview = requestPreview as any;
@@ -45,6 +45,7 @@ describe('load-test-backend', () => {
       'prod',
       'rid',
       (p) => progress.push(p),
+      '/work/api.http',
       {
         backend: { startLoadTest },
         eventsOn: ev.eventsOn,
@@ -81,6 +82,7 @@ describe('load-test-backend', () => {
         successfulRequests: 2,
         failedRequests: 1,
         responseTimes: [10, 20],
+        latency: { count: 3, avg: 20, min: 10, p50: 20, p90: 30, p95: 30, p99: 30, max: 30 },
         errors: ['oops'],
         failureStatusCounts: { '500': 1 },
         startTime: 1,
@@ -101,6 +103,7 @@ describe('load-test-backend', () => {
     expect(results.plannedDurationMs).toBe(1234);
     expect(results.aborted).toBe(true);
     expect(results.abortReason).toBe('x');
+    expect(results.latency?.max).toBe(30);
 
     expect(startLoadTest).toHaveBeenCalledWith(
       'rid',
@@ -108,7 +111,10 @@ describe('load-test-backend', () => {
       'https://example.com/s3cr3t',
       JSON.stringify({ A: 'SECRET', X: '1' }),
       's3cr3t',
-      JSON.stringify({ duration: '1s' })
+      JSON.stringify({ duration: '1s' }),
+      undefined,
+      undefined,
+      '/work/api.http'
     );
 
     // cleanup called for done
@@ -117,6 +123,52 @@ describe('load-test-backend', () => {
     expect(ev.unsubscribed['loadtest:error']).toBe(1);
   });
 
+  it('sends the post-script and @assert expressions for load checks', async () => {
+    const ev = createEventsOn();
+    const startLoadTest = vi.fn().mockResolvedValue(undefined);
+
+    const promise = executeLoadTestViaBackend(
+      {
+        name: 't',
+        method: 'GET',
+        url: 'u',
+        headers: {},
+        body: undefined,
+        options: {},
+        postScript: '  assert(response.status === 200);\n',
+        assertions: ['status == 200', 'body.id exists'],
+        loadTest: { iterations: 1, assert: true },
+      } as any,
+      {},
+      undefined,
+      'rid',
+      undefined,
+      undefined,
+      {
+        backend: { startLoadTest },
+        eventsOn: ev.eventsOn,
+        hydrateText: async (t) => t,
+        hydrateHeaders: async (h) => h || {},
+        normalizeEnvName: (e) => e || '',
+      }
+    );
+
+    const deadline = Date.now() + 250;
+    while (startLoadTest.mock.calls.length === 0) {
+      if (Date.now() > deadline) {
+        throw new Error('Timed out waiting for startLoadTest to be called');
+      }
+      await new Promise((r) => setTimeout(r, 0));
+    }
+
+    const args = startLoadTest.mock.calls[0];
+    expect(args[6]).toBe('assert(response.status === 200);');
+    expect(args[7]).toEqual(['status == 200', 'body.id exists']);
+
+    ev.emit('loadtest:done', { requestId: 'rid', results: {} });
+    await promise;
+  });
+
   it('rejects on loadtest:error and cleans up', async () => {
     const ev = createEventsOn();
     const startLoadTest = vi.fn().mockResolvedValue(undefined);
@@ -135,6 +187,7 @@ describe('load-test-backend', () => {
       undefined,
       'rid',
       undefined,
+      undefined,
       {
         backend: { startLoadTest },
         eventsOn: ev.eventsOn,
@@ -7,7 +7,10 @@ export type LoadTestBackend = {
     url: string,
     headersJson: string,
     body: string,
-    loadTestJson: string
+    loadTestJson: string,
+    postScript?: string,
+    assertions?: string[],
+    requestFilePath?: string
   ) => Promise<void>;
 };
 
@@ -31,6 +34,7 @@ export async function executeLoadTestViaBackend(
   env: string | undefined,
   requestId: string | undefined,
   onProgress: ((progress: ActiveRunProgress) => void) | undefined,
+  requestFilePath: string | undefined,
   deps: ExecuteLoadTestViaBackendDeps
 ): Promise<LoadTestResults> {
   if (!requestId) {
@@ -82,6 +86,7 @@ export async function executeLoadTestViaBackend(
         successfulRequests: r.successfulRequests ?? 0,
         failedRequests: r.failedRequests ?? 0,
         responseTimes: Array.isArray(r.responseTimes) ? r.responseTimes : [],
+        latency: r.latency ?? undefined,
         errors: Array.isArray(r.errors) ? r.errors : [],
         failureStatusCounts: r.failureStatusCounts ?? {},
         startTime: r.startTime ?? Date.now(),
@@ -107,7 +112,13 @@ export async function executeLoadTestViaBackend(
         processedUrl,
         JSON.stringify(processedHeaders || {}),
         processedBody,
-        JSON.stringify(request.loadTest)
+        JSON.stringify(request.loadTest),
+        // The backend runs the post-script and @assert lines per response
+        // to fill the run's checks.
+        request.postScript?.trim() || undefined,
+        request.assertions?.length ? request.assertions : undefined,
+        // Relative data= paths resolve against the .http file's directory.
+        requestFilePath || undefined
       );
     } catch (e) {
       cleanup();
@@ -59,4 +59,24 @@ describe('load-test-metrics', () => {
     expect(metrics.maxResponseTime).toBe(0);
     expect(metrics.requestsPerSecond).toBe(0);
   });
+
+  it('reads latency statistics from the backend summary instead of the sample', () => {
+    const metrics = calculateLoadTestMetrics({
+      totalRequests: 50000,
+      successfulRequests: 50000,
+      failedRequests: 0,
+      responseTimes: [100, 200, 300],
+      latency: { count: 50000, avg: 180, min: 3, p50: 170, p90: 260, p95: 310, p99: 900, max: 4200 },
+      errors: [],
+      startTime: 0,
+      endTime: 10000,
+    });
+
+    expect(metrics.minResponseTime).toBe(3);
+    expect(metrics.maxResponseTime).toBe(4200);
+    expect(metrics.averageResponseTime).toBe(180);
+    expect(metrics.p50).toBe(170);
+    expect(metrics.p95).toBe(310);
+    expect(metrics.p99).toBe(900);
+  });
 });
@@ -1,8 +1,8 @@
-import { LoadTestMetrics, LoadTestResults } from '../../models/http.models';
+import { LatencySummary, LoadTestMetrics, LoadTestResults } from '../../models/http.models';
 
 export function calculateLoadTestMetrics(results: LoadTestResults): LoadTestMetrics {
-  const sortedTimes = [...results.responseTimes].sort((a, b) => a - b);
   const duration = (results.endTime - results.startTime) / 1000; // seconds
+  const latency = results.latency?.count ? results.latency : latencyFromSample(results.responseTimes);
 
   return {
     totalRequests: results.totalRequests,
@@ -10,12 +10,12 @@ export function calculateLoadTestMetrics(results: LoadTestResults): LoadTestMetr
     failedRequests: results.failedRequests,
     failureStatusCounts: results.failureStatusCounts || {},
     requestsPerSecond: duration > 0 ? (results.totalRequests / duration) : 0,
-    averageResponseTime: sortedTimes.reduce((a, b) => a + b, 0) / sortedTimes.length || 0,
-    p50: sortedTimes[Math.floor(sortedTimes.length * 0.5)] || 0,
-    p95: sortedTimes[Math.floor(sortedTimes.length * 0.95)] || 0,
-    p99: sortedTimes[Math.floor(sortedTimes.length * 0.99)] || 0,
-    minResponseTime: sortedTimes[0] || 0,
-    maxResponseTime: sortedTimes[sortedTimes.length - 1] || 0,
+    averageResponseTime: latency.avg,
+    p50: latency.p50,
+    p95: latency.p95,
+    p99: latency.p99,
+    minResponseTime: latency.min,
+    maxResponseTime: latency.max,
     errorRate: (results.failedRequests / results.totalRequests) * 100 || 0,
     duration,
     cancelled: results.cancelled,
@@ -25,3 +25,20 @@ export function calculateLoadTestMetrics(results: LoadTestResults): LoadTestMetr
     adaptive: results.adaptive,
   };
 }
+
+// latencyFromSample derives the statistics from the response time sample.
+// It is only used for results without a backend latency summary (e.g. older
+// history entries), since the sample is capped on long runs.
+function latencyFromSample(responseTimes: number[] | undefined): LatencySummary {
+  const sortedTimes = [...(responseTimes ?? [])].sort((a, b) => a - b);
+  return {
+    count: sortedTimes.length,
+    avg: sortedTimes.reduce((a, b) => a + b, 0) / sortedTimes.length || 0,
+    min: sortedTimes[0] || 0,
+    p50: sortedTimes[Math.floor(sortedTimes.length * 0.5)] || 0,
+    p90: sortedTimes[Math.floor(sortedTimes.length * 0.9)] || 0,
+    p95: sortedTimes[Math.floor(sortedTimes.length * 0.95)] || 0,
+    p99: sortedTimes[Math.floor(sortedTimes.length * 0.99)] || 0,
+    max: sortedTimes[sortedTimes.length - 1] || 0,
+  };
+}
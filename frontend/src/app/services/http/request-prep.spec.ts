@@ -62,4 +62,20 @@ describe('request-prep', () => {
     expect(result.backend['options']['timeout']).toBe(123);
     expect(result.preview.body).toBeUndefined();
   });
+
+  it('sends @assert expressions with a chain request', async () => {
+    const req: Request = {
+      method: 'GET',
+      url: 'http://x',
+      headers: {},
+      assertions: ['status == 200'],
+    };
+
+    const result = await prepareBackendRequestForChain(req, 'env', {
+      hydrateTextSecretsOnly: async (v) => v,
+      hydrateHeadersSecretsOnly: async (h) => h || {}
+    });
+
+    expect(result.backend['assertions']).toEqual(['status == 200']);
+  });
 });
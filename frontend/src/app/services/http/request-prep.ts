@@ -84,6 +84,7 @@ export async function prepareBackendRequestForChain(
     preScript: req.preScript,
     postScript: req.postScript,
     options: req.options || undefined,
+    assertions: req.assertions?.length ? req.assertions : undefined,
   };
 
   const preview: RequestPreview = {
@@ -114,6 +114,43 @@ describe('parser/parse-http-file', () => {
     expect(parsed.requests[0].noHistory).toBe(true);
   });
 
+  it('parses @assert directives before and after the request line', () => {
+    const parsed = parseHttpFile([
+      '@assert status == 201',
+      'POST https://example.com/users',
+      'Content-Type: application/json',
+      '@assert jsonpath $.id exists',
+    ].join('\n'));
+
+    expect(parsed.requests).toHaveLength(1);
+    expect(parsed.requests[0].assertions).toEqual(['status == 201', 'jsonpath $.id exists']);
+    expect(parsed.variables['assert']).toBeUndefined();
+  });
+
+  it('parses @max-runs into options', () => {
+    const parsed = parseHttpFile([
+      '@max-runs 500',
+      'GET https://example.com/jobs/1',
+    ].join('\n'));
+
+    expect(parsed.requests).toHaveLength(1);
+    expect(parsed.requests[0].options?.maxRuns).toBe(500);
+    expect(parsed.variables['max']).toBeUndefined();
+  });
+
+  it('keeps @assert lines inside a body as body text', () => {
+    const parsed = parseHttpFile([
+      'POST https://example.com/notes',
+      'Content-Type: text/plain',
+      '',
+      '@assert foo',
+    ].join('\n'));
+
+    expect(parsed.requests).toHaveLength(1);
+    expect(parsed.requests[0].assertions).toBeUndefined();
+    expect(parsed.requests[0].body).toContain('@assert foo');
+  });
+
   it('noHistory defaults to undefined when not specified', () => {
     const parsed = parseHttpFile([
       'GET https://example.com/normal',
@@ -29,9 +29,10 @@ export type PendingMetadata = {
   name?: string;
   depends?: string;
   loadTest?: any;
-  options?: { timeout?: number };
+  options?: { timeout?: number; maxRuns?: number };
   noHistory?: boolean;
   isMock?: boolean;
+  assertions?: string[];
 };
 
 export type ParseHttpFileDeps = {
@@ -197,6 +198,19 @@ export function parseHttpFile(content: string, deps: ParseHttpFileDeps = {}): Pa
       continue;
     }
 
+    // @max-runs - how often chain.next() may run this request.
+    if (line.startsWith('@max-runs ')) {
+      const maxRuns = parseInt(line.substring(10).trim(), 10);
+      if (!isNaN(maxRuns) && maxRuns > 0) {
+        if (!pendingMetadata.options) {
+          pendingMetadata.options = {};
+        }
+        pendingMetadata.options.maxRuns = maxRuns;
+      }
+      i++;
+      continue;
+    }
+
     // @no-history directive - response will NOT be saved to disk (for PHI/sensitive data)
     if (line === '@no-history' || line.startsWith('@no-history ')) {
       pendingMetadata.noHistory = true;
@@ -228,6 +242,18 @@ export function parseHttpFile(content: string, deps: ParseHttpFileDeps = {}): Pa
       continue;
     }
 
+    // @assert directive - declarative response check evaluated by the backend.
+    // Like the CLI, it may sit before or after the request line, but inside a
+    // body it is body text.
+    if (line.startsWith('@assert ') && !(inRequest && inBody)) {
+      const expr = line.substring(8).trim();
+      if (expr) {
+        const target = inRequest && currentRequest ? currentRequest : pendingMetadata;
+        target.assertions = [...(target.assertions ?? []), expr];
+      }
+      i++;
+      continue;
+    }
 
     if (line.startsWith('@')) {
       const varMatch = line.match(/^@(\w+)\s*=?\s*(.*)$/);
@@ -96,7 +96,10 @@ export class ServiceBackendClientService implements BackendClientContract {
     url: string,
     headersJson: string,
     body: string,
-    loadConfigJson: string
+    loadConfigJson: string,
+    postScript?: string,
+    assertions?: string[],
+    requestFilePath?: string
   ): Promise<void> {
     return this.postVoid('/v1/start-load-test', {
       requestId,
@@ -105,6 +108,9 @@ export class ServiceBackendClientService implements BackendClientContract {
       headersJson,
       body,
       loadConfigJson,
+      postScript,
+      assertions,
+      requestFilePath,
     });
   }
 
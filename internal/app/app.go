@@ -12,9 +12,11 @@ import (
 	"sync"
 
 	"rawrequest/internal/importers"
+	"rawrequest/internal/mockserver"
 	rc "rawrequest/internal/requestchain"
 	rp "rawrequest/internal/responseparse"
 	rb "rawrequest/internal/ringbuffer"
+	sd "rawrequest/internal/scriptdebug"
 	se "rawrequest/internal/scriptexec"
 	sr "rawrequest/internal/scriptruntime"
 	tpl "rawrequest/internal/templating"
@@ -59,6 +61,7 @@ type App struct {
 	cancelMutex       sync.Mutex
 	scriptLogs        *rb.Buffer[ScriptLogEntry]
 	scriptLogMutex    sync.Mutex
+	scriptDebugger    *sd.Controller
 	eventBroker       *appEventBroker
 	secretVault       *SecretVault
 	secretVaultOnce   sync.Once
@@ -74,6 +77,8 @@ type App struct {
 	watchedFilesMu    sync.Mutex
 	shutdownOnce      sync.Once
 	shutdownErr       error
+	mockServers       []*mockserver.Server
+	mockServersMu     sync.Mutex
 	stopMockServerFn  func() error
 	stopManagedSvcFn  func() error
 	saveWindowStateFn func() error
@@ -82,6 +87,8 @@ type App struct {
 const (
 	requestCancelledResponse = "__CANCELLED__"
 	scriptLogEventName       = "script-log"
+	scriptDebugPausedEvent   = "script-debug:paused"
+	scriptDebugResumedEvent  = "script-debug:resumed"
 	maxScriptLogs            = 500
 )
 
@@ -106,6 +113,7 @@ func NewApp(examplesFS ...fs.FS) *App {
 	if len(examplesFS) > 0 {
 		a.examplesFS = examplesFS[0]
 	}
+	a.scriptDebugger = sd.NewController(a.publishScriptDebugPause, a.publishScriptDebugResume)
 	a.stopMockServerFn = a.StopMockServer
 	a.stopManagedSvcFn = a.stopManagedService
 	a.saveWindowStateFn = a.SaveWindowState
@@ -204,6 +212,7 @@ func (a *App) executeScript(rawScript string, ctx *sr.ExecutionContext, stage st
 		GetVar:            a.getVariable,
 		SetVar:            a.SetVariable,
 		AppendLog:         a.appendScriptLog,
+		Debugger:          a.scriptDebugger,
 	})
 }
 
@@ -2,9 +2,9 @@ package app
 
 import (
 	"context"
-	"math"
+	"fmt"
 	"math/rand"
-	"sort"
+	"path/filepath"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -14,6 +14,7 @@ import (
 	"rawrequest/internal/loadtestbridge"
 	lp "rawrequest/internal/loadtestpayload"
 	"rawrequest/internal/loadtestrunlogic"
+	rp "rawrequest/internal/responseparse"
 )
 
 const (
@@ -37,7 +38,10 @@ type loadTestResults struct {
 	Adaptive            *lt.AdaptiveSummary `json:"adaptive,omitempty"`
 }
 
-func (a *App) startLoadTest(requestID, method, url, headersJSON, body, loadConfigJSON string) error {
+func (a *App) startLoadTest(requestID, method, url, headersJSON, body, loadConfigJSON, postScript string, assertionExprs []string, steps []lt.Step, requestFilePath string) error {
+	if len(steps) > 0 {
+		method, url = steps[0].Method, steps[0].URL
+	}
 	requestID, method, url, err := loadtestbridge.NormalizeStartArgs(requestID, method, url)
 	if err != nil {
 		return err
@@ -48,6 +52,24 @@ func (a *App) startLoadTest(requestID, method, url, headersJSON, body, loadConfi
 		return err
 	}
 
+	single := lt.Step{Method: method, URL: url, HeadersJSON: headersJSON, Body: body, PostScript: postScript, Assertions: assertionExprs}
+	journey, scenario, err := newLoadTestSteps(single, steps, norm.AssertEnabled)
+	if err != nil {
+		return err
+	}
+
+	var feed *lt.DataFeed
+	switch {
+	case len(norm.DataRows) > 0:
+		if feed, err = lt.NewDataFeed(norm.DataRows, norm.DataMode); err != nil {
+			return err
+		}
+	case norm.DataPath != "":
+		if feed, err = lt.LoadDataFeed(resolveLoadDataPath(norm.DataPath, requestFilePath), norm.DataMode); err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	a.registerCancel(requestID, cancel)
 
@@ -62,13 +84,35 @@ func (a *App) startLoadTest(requestID, method, url, headersJSON, body, loadConfi
 			cancel()
 			a.clearCancel(requestID)
 		}()
-		a.runLoadTest(ctx, cancel, requestID, method, url, headersJSON, body, norm)
+		if norm.StartAtMs > 0 {
+			if wait := time.Until(time.UnixMilli(norm.StartAtMs)); wait > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(wait):
+				}
+			}
+		}
+		a.runLoadTest(ctx, cancel, requestID, journey, scenario, feed, norm)
 	}()
 
 	return nil
 }
 
-func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID, method, url, headersJSON, body string, cfg lt.NormalizedConfig) {
+// resolveLoadDataPath resolves a relative data file against the directory of
+// the .http file the load test came from, the way the CLI does, rather than
+// the service's working directory.
+func resolveLoadDataPath(dataPath, requestFilePath string) string {
+	if filepath.IsAbs(dataPath) || requestFilePath == "" {
+		return dataPath
+	}
+	return filepath.Join(filepath.Dir(requestFilePath), dataPath)
+}
+
+// runLoadTest drives the virtual users. Each iteration runs the journey's
+// steps in order with a fresh session holding that iteration's data row, and
+// a failed step ends the journey early. Requests are resolved per iteration
+// only when something can change between them.
+func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID string, journey []loadTestStep, scenario bool, feed *lt.DataFeed, cfg lt.NormalizedConfig) {
 	start := time.Now()
 	startMs := start.UnixMilli()
 	var plannedDurationMs *int64
@@ -112,10 +156,24 @@ func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID,
 	var totalSent atomic.Int64
 	var okSent atomic.Int64
 	var failedSent atomic.Int64
+	var assertionFailures atomic.Int64
 	statusCounts := map[string]int64{}
 	var statusMu sync.Mutex
-	responseTimes := make([]int64, 0, 1024)
-	var rtMu sync.Mutex
+	assertCounts := map[string]int64{}
+	var assertMu sync.Mutex
+	latency := lt.NewLatencyRecorder()
+	timeline := lt.NewTimeline(start)
+	var stepStats *lt.StepStats
+	var completedJourneys atomic.Int64
+	var droppedIterations atomic.Int64
+	if scenario {
+		steps := make([]lt.Step, len(journey))
+		for i, step := range journey {
+			steps[i] = step.Step
+		}
+		stepStats = lt.NewStepStats(steps)
+	}
+	resolvePerIteration := scenario || feed != nil || journeyHasTemplates(journey)
 
 	window := lt.NewAdaptiveWindowRing(cfg.AdaptiveEnabled, cfg.AdaptiveWindowSec)
 
@@ -148,42 +206,11 @@ func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID,
 	progressTicker := time.NewTicker(200 * time.Millisecond)
 	defer progressTicker.Stop()
 	emitProgress := func(force bool, done bool) {
+		timeline.ObserveUsers(time.Now(), activeUsers.Load())
 		var p50, p90, p95, p99, avgVal, minVal, maxVal int64
-		rtMu.Lock()
-		count := len(responseTimes)
-		if count > 0 {
-			sorted := make([]int64, count)
-			copy(sorted, responseTimes)
-			rtMu.Unlock()
-
-			sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
-
-			minVal = sorted[0]
-			maxVal = sorted[count-1]
-
-			var sum int64
-			for _, v := range sorted {
-				sum += v
-			}
-			avgVal = sum / int64(count)
-
-			percentile := func(p float64) int64 {
-				idx := int(math.Ceil(p/100*float64(count))) - 1
-				if idx < 0 {
-					idx = 0
-				}
-				if idx >= count {
-					idx = count - 1
-				}
-				return sorted[idx]
-			}
-
-			p50 = percentile(50)
-			p90 = percentile(90)
-			p95 = percentile(95)
-			p99 = percentile(99)
-		} else {
-			rtMu.Unlock()
+		if summary := latency.Summary(); summary != nil {
+			p50, p90, p95, p99 = summary.P50Ms, summary.P90Ms, summary.P95Ms, summary.P99Ms
+			avgVal, minVal, maxVal = summary.AvgMs, summary.MinMs, summary.MaxMs
 		}
 
 		payload := lp.BuildProgressPayload(lp.ProgressInput{
@@ -195,6 +222,7 @@ func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID,
 			TotalSent:         totalSent.Load(),
 			Successful:        okSent.Load(),
 			Failed:            failedSent.Load(),
+			AssertionFailures: assertionFailures.Load(),
 			Done:              done,
 			Cancelled:         ctx.Err() == context.Canceled,
 			Aborted:           aborted.Load(),
@@ -211,6 +239,107 @@ func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID,
 		_ = force
 	}
 
+	// iterate runs the journey once. It reports false when the run is over
+	// or a unique data feed has run out.
+	iterate := func(r *rand.Rand) bool {
+		var session *loadTestSession
+		if resolvePerIteration {
+			var row map[string]string
+			if feed != nil {
+				var ok bool
+				if row, ok = feed.Next(r); !ok {
+					return false
+				}
+			}
+			session = a.newLoadTestSession(row)
+		}
+		completed := true
+		for index, step := range journey {
+			throttle()
+			if ctx.Err() != nil || isStopped() || aborted.Load() {
+				return false
+			}
+
+			method, url, headersJSON, body := step.Method, step.URL, step.HeadersJSON, step.Body
+			if session != nil {
+				url, headersJSON, body = session.resolve(url), session.resolveHeaders(headersJSON), session.resolve(body)
+			}
+			res := a.performRequest(ctx, "", method, url, headersJSON, body, 0)
+			if res == requestCancelledResponse {
+				return false
+			}
+			receivedAt := time.Now()
+			status, timingMs := lt.ParseStatusAndTiming(res)
+			if timingMs <= 0 {
+				timingMs = time.Since(start).Milliseconds()
+			}
+
+			totalSent.Add(1)
+			latency.Record(timingMs)
+
+			statusFailure := status == 0 || status >= 400
+			assertFailure := false
+			if !statusFailure {
+				// Scenario post-scripts run on every response so later steps get
+				// their variables; only sampled checks count as failures.
+				sampled := cfg.AssertEnabled && !step.checks.empty() && lt.ShouldCheckResponse(cfg.AssertEnabled, cfg.AssertSampleRate, r)
+				var response map[string]interface{}
+				if scenario {
+					response = rp.Parse(res)
+					session.responses[fmt.Sprintf("request%d", index+1)] = response
+				}
+				if sampled || (scenario && step.checks.postScript != "") {
+					if response == nil {
+						response = rp.Parse(res)
+					}
+					if failed := a.runLoadTestChecks(step.checks, method, url, headersJSON, body, response, session); sampled && len(failed) > 0 {
+						assertFailure = true
+						assertionFailures.Add(1)
+						recordAssertionFailures(&assertMu, assertCounts, failed)
+					}
+				}
+			}
+
+			isFailure := statusFailure || assertFailure
+			window.Record(time.Now(), isFailure)
+			timeline.Record(receivedAt, timingMs, status, isFailure)
+			stepStats.Record(index, timingMs, status, statusFailure, isFailure)
+			if isFailure {
+				failedSent.Add(1)
+				if statusFailure {
+					statusMu.Lock()
+					statusCounts[strconv.Itoa(status)]++
+					statusMu.Unlock()
+				}
+			} else {
+				okSent.Add(1)
+			}
+
+			if !aborted.Load() {
+				if shouldAbort, reason := lt.FailureRateAbortDecision(totalSent.Load(), failedSent.Load(), cfg.FailureThreshold, cfg.HasFailureThresh); shouldAbort {
+					aborted.Store(true)
+					abortReason.Store(reason)
+				}
+			}
+			if aborted.Load() {
+				return false
+			}
+
+			wait := lt.UserWaitDuration(cfg.HasWaitRange, cfg.WaitMinMs, cfg.WaitMaxMs, cfg.DelayMs, r)
+			if !lt.WaitOrStop(ctx, stopCh, wait, nil) {
+				return false
+			}
+			if isFailure {
+				completed = false
+				break
+			}
+		}
+		if scenario && completed {
+			completedJourneys.Add(1)
+		}
+		return true
+	}
+
 	worker := func(userNumber int64) {
 		r := rand.New(rand.NewSource(time.Now().UnixNano() + userNumber*31))
 		active := false
@@ -252,61 +381,69 @@ func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID,
 				return
 			}
 
-			throttle()
-			if ctx.Err() != nil || isStopped() || aborted.Load() {
+			if !iterate(r) {
 				return
 			}
+		}
+	}
 
-			res := a.performRequest(ctx, "", method, url, headersJSON, body, 0)
-			if res == requestCancelledResponse {
+	// arrive starts iterations on the arrival schedule whether or not earlier
+	// ones have finished, so a slowing server can't lower the offered load
+	// and hide its own latency. An arrival that finds every user busy is
+	// dropped and counted rather than delayed.
+	arrive := func() {
+		var inflight sync.WaitGroup
+		defer inflight.Wait()
+		var exhausted atomic.Bool
+		schedule := lt.NewArrivalSchedule(cfg.ArrivalRate, cfg.Stages, cfg.DurationMs)
+		for n := int64(1); ; n++ {
+			offset, ok := schedule.Next()
+			if !ok {
 				return
 			}
-			status, timingMs := lt.ParseStatusAndTiming(res)
-			if timingMs <= 0 {
-				timingMs = time.Since(start).Milliseconds()
-			}
-
-			totalSent.Add(1)
-			rtMu.Lock()
-			responseTimes = append(responseTimes, timingMs)
-			rtMu.Unlock()
-
-			isFailure := status == 0 || status >= 400
-			window.Record(time.Now(), isFailure)
-			if isFailure {
-				failedSent.Add(1)
-				statusMu.Lock()
-				statusCounts[strconv.Itoa(status)]++
-				statusMu.Unlock()
-			} else {
-				okSent.Add(1)
-			}
-
-			if !aborted.Load() {
-				if shouldAbort, reason := lt.FailureRateAbortDecision(totalSent.Load(), failedSent.Load(), cfg.FailureThreshold, cfg.HasFailureThresh); shouldAbort {
-					aborted.Store(true)
-					abortReason.Store(reason)
-				}
+			at := start.Add(offset)
+			if cfg.HasDuration && !at.Before(stopAt) {
+				return
 			}
-			if aborted.Load() {
+			if !lt.WaitOrStop(ctx, stopCh, time.Until(at), nil) || aborted.Load() || exhausted.Load() {
 				return
 			}
-
-			wait := lt.UserWaitDuration(cfg.HasWaitRange, cfg.WaitMinMs, cfg.WaitMaxMs, cfg.DelayMs, r)
-			if !lt.WaitOrStop(ctx, stopCh, wait, nil) {
+			if !reserveSlot() {
 				return
 			}
+			if activeUsers.Add(1) > cfg.MaxUsers {
+				activeUsers.Add(-1)
+				droppedIterations.Add(1)
+				continue
+			}
+			inflight.Add(1)
+			seed := time.Now().UnixNano() + n*31
+			go func() {
+				defer inflight.Done()
+				defer activeUsers.Add(-1)
+				if !iterate(rand.New(rand.NewSource(seed))) {
+					exhausted.Store(true)
+				}
+			}()
 		}
 	}
 
 	var usersWG sync.WaitGroup
-	usersWG.Add(int(cfg.MaxUsers))
-	for i := int64(1); i <= cfg.MaxUsers; i++ {
-		userNumber := i
+	if cfg.HasArrival {
+		usersWG.Add(1)
 		go func() {
 			defer usersWG.Done()
-			worker(userNumber)
+			arrive()
 		}()
+	} else {
+		usersWG.Add(int(cfg.MaxUsers))
+		for i := int64(1); i <= cfg.MaxUsers; i++ {
+			userNumber := i
+			go func() {
+				defer usersWG.Done()
+				worker(userNumber)
+			}()
+		}
 	}
 
 	usersDone := make(chan struct{})
@@ -430,11 +567,18 @@ func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID,
 				TotalSent:         &totalSent,
 				OkSent:            &okSent,
 				FailedSent:        &failedSent,
+				AssertionFailures: &assertionFailures,
 				Cancelled:         true,
 				StatusMu:          &statusMu,
 				StatusCounts:      statusCounts,
-				RtMu:              &rtMu,
-				ResponseTimes:     responseTimes,
+				AssertMu:          &assertMu,
+				AssertCounts:      assertCounts,
+				Latency:           latency,
+				Timeline:          timeline,
+				Steps:             stepStats,
+				CompletedJourneys: &completedJourneys,
+				DroppedIterations: &droppedIterations,
+				Thresholds:        cfg.Thresholds,
 			})
 			a.emitEvent(loadTestDoneEventName, lp.DonePayload{RequestID: requestID, Results: res})
 			return
@@ -453,16 +597,35 @@ func (a *App) runLoadTest(ctx context.Context, _ context.CancelFunc, requestID,
 				TotalSent:         &totalSent,
 				OkSent:            &okSent,
 				FailedSent:        &failedSent,
+				AssertionFailures: &assertionFailures,
 				Cancelled:         false,
 				StatusMu:          &statusMu,
 				StatusCounts:      statusCounts,
-				RtMu:              &rtMu,
-				ResponseTimes:     responseTimes,
+				AssertMu:          &assertMu,
+				AssertCounts:      assertCounts,
+				Latency:           latency,
+				Timeline:          timeline,
+				Steps:             stepStats,
+				CompletedJourneys: &completedJourneys,
+				DroppedIterations: &droppedIterations,
+				Thresholds:        cfg.Thresholds,
 			})
 			a.emitEvent(loadTestDoneEventName, lp.DonePayload{RequestID: requestID, Results: res})
 			return
 		case <-progressTicker.C:
 			emitProgress(false, false)
+			if cfg.ThresholdsAbort && !aborted.Load() {
+				crossed, ok := lt.CrossedThreshold(cfg.Thresholds, lt.ThresholdMetrics{
+					Requests:   totalSent.Load(),
+					Failed:     failedSent.Load(),
+					DurationMs: time.Since(start).Milliseconds(),
+					Latency:    latency.Summary(),
+				})
+				if ok {
+					aborted.Store(true)
+					abortReason.Store(fmt.Sprintf("threshold %s crossed (%s %s)", crossed.Threshold, crossed.Metric, lt.FormatThresholdValue(crossed.Metric, crossed.Actual)))
+				}
+			}
 		}
 	}
 }
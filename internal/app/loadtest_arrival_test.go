@@ -0,0 +1,47 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	lp "rawrequest/internal/loadtestpayload"
+)
+
+func TestArrivalRateLoadTestKeepsPaceWithSlowServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(300 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	a := NewApp()
+	events, unsubscribe := a.subscribeEvents(64)
+	defer unsubscribe()
+
+	// Two users looping would manage about six requests in a second; the
+	// arrival rate asks for twenty, so arrivals beyond two in flight drop.
+	if err := a.startLoadTest("rid", "GET", server.URL, "", "", `{"rate":"20rps","duration":"1s","users":2}`, "", nil, nil, ""); err != nil {
+		t.Fatalf("startLoadTest: %v", err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case evt := <-events:
+			if evt.Event != loadTestDoneEventName {
+				continue
+			}
+			res := evt.Payload.(lp.DonePayload).Results
+			if res.TotalRequests+res.DroppedIterations != 20 {
+				t.Fatalf("sent %d and dropped %d, want 20 arrivals", res.TotalRequests, res.DroppedIterations)
+			}
+			if res.DroppedIterations < 10 {
+				t.Fatalf("dropped = %d, want the arrivals that found both users busy", res.DroppedIterations)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for loadtest:done")
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"rawrequest/internal/assertions"
+	hcl "rawrequest/internal/httpclientlogic"
+	se "rawrequest/internal/scriptexec"
+	sr "rawrequest/internal/scriptruntime"
+)
+
+// maxAssertionFailureKeys bounds how many distinct failure labels a load test
+// keeps, so scripts that embed values in assert messages cannot grow the
+// results map without limit.
+const maxAssertionFailureKeys = 20
+
+// loadTestChecks is the per-response validation a load test runs when its
+// config enables `assert`: compiled @assert lines plus the request's
+// post-script.
+type loadTestChecks struct {
+	postScript string
+	assertions []assertions.Assertion
+}
+
+func newLoadTestChecks(postScript string, exprs []string) (loadTestChecks, error) {
+	checks := loadTestChecks{postScript: strings.TrimSpace(cleanScriptContent(postScript))}
+	for _, expr := range exprs {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+		compiled, err := assertions.Compile(expr)
+		if err != nil {
+			return loadTestChecks{}, fmt.Errorf("invalid assertion %q: %w", expr, err)
+		}
+		checks.assertions = append(checks.assertions, compiled)
+	}
+	return checks, nil
+}
+
+func (c loadTestChecks) empty() bool {
+	return c.postScript == "" && len(c.assertions) == 0
+}
+
+// runLoadTestChecks evaluates the checks against one parsed response and
+// returns a label for every failed assertion. Declarative assertions are
+// checked first since they need no VM. Without a session the post-script
+// runs without SetVar, so setVar() calls stay local to the iteration instead
+// of racing on shared app variables; in a scenario they go to the virtual
+// user's session.
+func (a *App) runLoadTestChecks(c loadTestChecks, method, url, headersJSON, body string, response map[string]interface{}, session *loadTestSession) []string {
+	var failed []string
+	for _, check := range c.assertions {
+		if res := check.Check(response); !res.Passed {
+			failed = append(failed, check.Raw)
+		}
+	}
+	if c.postScript == "" {
+		return failed
+	}
+
+	ctx := &sr.ExecutionContext{
+		Request: map[string]interface{}{
+			"method":  method,
+			"url":     url,
+			"headers": hcl.ParseHeadersJSON(headersJSON),
+			"body":    body,
+		},
+		Response: response,
+	}
+	deps := se.Dependencies{
+		VariablesSnapshot: a.variablesSnapshot,
+		GetVar:            a.getVariable,
+		AppendLog: func(level, source, message string) {
+			// Only surface errors; per-iteration console output would flood the log buffer.
+			if level == "error" {
+				a.appendScriptLog(level, "load:"+source, message)
+			}
+		},
+	}
+	if session != nil {
+		ctx.ResponseStore = session.responses
+		deps.VariablesSnapshot = session.snapshot
+		deps.GetVar = session.get
+		deps.SetVar = session.set
+	}
+	se.Execute(c.postScript, ctx, "post", deps)
+	for _, res := range ctx.Assertions {
+		if !res.Passed {
+			failed = append(failed, res.Message)
+		}
+	}
+	return failed
+}
+
+func recordAssertionFailures(mu *sync.Mutex, counts map[string]int64, labels []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, label := range labels {
+		// The last slot is kept for "(other)".
+		if _, exists := counts[label]; !exists && len(counts) >= maxAssertionFailureKeys-1 {
+			label = "(other)"
+		}
+		counts[label]++
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"sync"
+	"testing"
+
+	rp "rawrequest/internal/responseparse"
+)
+
+const okJSONResult = "Status: 200 OK\nRequest: {}\nHeaders: {\"timing\":{\"total\":12},\"size\":15,\"headers\":{\"content-type\":\"application/json\"}}\nBody: {\"error\":\"boom\"}"
+
+func TestRunLoadTestChecks_DeclarativeAndPostScript(t *testing.T) {
+	a := NewApp()
+	checks, err := newLoadTestChecks(`> {
+  setVar('leak', 'x');
+  assert(!response.json.error, 'error envelope');
+}`, []string{"status == 200", "jsonpath $.error not exists"})
+	if err != nil {
+		t.Fatalf("newLoadTestChecks: %v", err)
+	}
+
+	failed := a.runLoadTestChecks(checks, "GET", "http://example.com", `{"Accept":"application/json"}`, "", rp.Parse(okJSONResult), nil)
+	if len(failed) != 2 {
+		t.Fatalf("failed=%v want 2 entries", failed)
+	}
+	if failed[0] != "jsonpath $.error not exists" || failed[1] != "error envelope" {
+		t.Fatalf("unexpected failure labels: %v", failed)
+	}
+	if _, ok := a.getVariable("leak"); ok {
+		t.Fatalf("load test post-scripts must not write app variables")
+	}
+}
+
+func TestNewLoadTestChecks_RejectsInvalidAssertion(t *testing.T) {
+	if _, err := newLoadTestChecks("", []string{"status ~~ 200"}); err == nil {
+		t.Fatalf("expected invalid assertion to be rejected")
+	}
+	checks, err := newLoadTestChecks("  ", nil)
+	if err != nil || !checks.empty() {
+		t.Fatalf("expected empty checks, got %#v, %v", checks, err)
+	}
+}
+
+func TestRecordAssertionFailures_CapsDistinctLabels(t *testing.T) {
+	var mu sync.Mutex
+	counts := map[string]int64{}
+	for i := 0; i < maxAssertionFailureKeys+5; i++ {
+		recordAssertionFailures(&mu, counts, []string{string(rune('a' + i))})
+	}
+	if counts["(other)"] != 6 {
+		t.Fatalf("expected overflow labels to collapse into (other), got %v", counts)
+	}
+	if len(counts) != maxAssertionFailureKeys {
+		t.Fatalf("kept %d labels, want %d", len(counts), maxAssertionFailureKeys)
+	}
+}
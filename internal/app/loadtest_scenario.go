@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	hcl "rawrequest/internal/httpclientlogic"
+	lt "rawrequest/internal/loadtest"
+	tpl "rawrequest/internal/templating"
+)
+
+// loadTestStep is one request a virtual user sends per iteration, with the
+// checks compiled from its post-script and @assert lines. A plain load test
+// is a journey of a single step.
+type loadTestStep struct {
+	lt.Step
+	checks loadTestChecks
+}
+
+// newLoadTestSteps builds the journey of a load test and reports whether it
+// is a scenario. Scenario steps always compile their checks, since their
+// post-scripts extract the variables later steps use; a single request only
+// needs them when assertions are enabled.
+func newLoadTestSteps(single lt.Step, steps []lt.Step, assertEnabled bool) ([]loadTestStep, bool, error) {
+	scenario := len(steps) > 0
+	if !scenario {
+		steps = []lt.Step{single}
+	}
+	journey := make([]loadTestStep, 0, len(steps))
+	for i, step := range steps {
+		step.Method = strings.TrimSpace(step.Method)
+		step.URL = strings.TrimSpace(step.URL)
+		if step.Method == "" || step.URL == "" {
+			return nil, false, fmt.Errorf("step %d: missing method or url", i+1)
+		}
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("request%d", i+1)
+		}
+		compiled := loadTestStep{Step: step}
+		if scenario || assertEnabled {
+			checks, err := newLoadTestChecks(step.PostScript, step.Assertions)
+			if err != nil {
+				return nil, false, fmt.Errorf("step %s: %w", step.Name, err)
+			}
+			compiled.checks = checks
+		}
+		journey = append(journey, compiled)
+	}
+	return journey, scenario, nil
+}
+
+// journeyHasTemplates reports whether any step still holds {{placeholders}}
+// such as data columns or dynamic functions after the caller resolved it.
+func journeyHasTemplates(journey []loadTestStep) bool {
+	for _, step := range journey {
+		if strings.Contains(step.URL, "{{") || strings.Contains(step.HeadersJSON, "{{") || strings.Contains(step.Body, "{{") {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTestSession is the state of one virtual user's journey: the data row
+// bound for the iteration, the variables its post-scripts set and the
+// responses of the steps so far, stored as request1, request2, ... like a
+// request chain. Each user runs its journey on one goroutine, so it needs no
+// locking.
+type loadTestSession struct {
+	vars      map[string]string
+	env       map[string]string
+	responses map[string]map[string]interface{}
+}
+
+func (a *App) newLoadTestSession(row map[string]string) *loadTestSession {
+	vars := a.variablesSnapshot()
+	for k, v := range row {
+		vars[k] = v
+	}
+	return &loadTestSession{
+		vars:      vars,
+		env:       a.currentEnvVarsSnapshot(),
+		responses: map[string]map[string]interface{}{},
+	}
+}
+
+func (s *loadTestSession) resolve(input string) string {
+	return tpl.Resolve(input, s.vars, s.env, s.responses)
+}
+
+func (s *loadTestSession) resolveHeaders(headersJSON string) string {
+	headers := hcl.ParseHeadersJSON(headersJSON)
+	if len(headers) == 0 {
+		return headersJSON
+	}
+	for k, v := range headers {
+		headers[k] = s.resolve(v)
+	}
+	data, err := json.Marshal(headers)
+	if err != nil {
+		return headersJSON
+	}
+	return string(data)
+}
+
+func (s *loadTestSession) get(key string) (string, bool) {
+	v, ok := s.vars[key]
+	return v, ok
+}
+
+func (s *loadTestSession) set(key, value string) {
+	s.vars[key] = value
+}
+
+func (s *loadTestSession) snapshot() map[string]string {
+	out := make(map[string]string, len(s.vars))
+	for k, v := range s.vars {
+		out[k] = v
+	}
+	return out
+}
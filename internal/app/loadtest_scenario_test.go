@@ -0,0 +1,150 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lt "rawrequest/internal/loadtest"
+	lp "rawrequest/internal/loadtestpayload"
+)
+
+func TestLoadTestScenarioCarriesPerUserVariables(t *testing.T) {
+	var logins atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			n := logins.Add(1)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"token":"t%d","item":"42"}`, n)
+		case "/items/42":
+			if auth := r.Header.Get("Authorization"); !strings.HasPrefix(auth, "Bearer t") || strings.Contains(auth, "{") {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte("ok"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	a := NewApp()
+	events, unsubscribe := a.subscribeEvents(64)
+	defer unsubscribe()
+
+	steps := []lt.Step{
+		{Name: "login", Method: "POST", URL: server.URL + "/login", PostScript: `setVar("token", response.json.token);`},
+		{Name: "detail", Method: "GET", URL: server.URL + "/items/{{request1.response.body.item}}", HeadersJSON: `{"Authorization":"Bearer {{token}}"}`},
+		{Name: "missing", Method: "GET", URL: server.URL + "/nope"},
+		{Name: "never", Method: "GET", URL: server.URL + "/items/42"},
+	}
+	if err := a.startLoadTest("rid", "", "", "", "", `{"iterations":3,"users":2}`, "", nil, steps, ""); err != nil {
+		t.Fatalf("startLoadTest: %v", err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case evt := <-events:
+			if evt.Event != loadTestDoneEventName {
+				continue
+			}
+			res := evt.Payload.(lp.DonePayload).Results
+			if res.TotalRequests != 9 || res.FailedRequests != 3 || res.CompletedJourneys != 0 {
+				t.Fatalf("results = %+v, want 9 requests, 3 failures and no completed journeys", res)
+			}
+			if len(res.Steps) != 4 {
+				t.Fatalf("steps = %+v", res.Steps)
+			}
+			for i, want := range []int64{3, 3, 3, 0} {
+				if res.Steps[i].Total != want {
+					t.Errorf("step %s total = %d, want %d", res.Steps[i].Name, res.Steps[i].Total, want)
+				}
+			}
+			if res.Steps[1].Successful != 3 || res.Steps[2].FailureStatusCounts["404"] != 3 {
+				t.Errorf("step summaries = %+v", res.Steps)
+			}
+			if _, ok := a.getVariable("token"); ok {
+				t.Errorf("scenario setVar must stay in the virtual user's session")
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for loadtest:done")
+		}
+	}
+}
+
+func TestLoadTestFeedsOneDataRowPerIteration(t *testing.T) {
+	var mu sync.Mutex
+	bodies := map[string]bool{}
+	ids := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies[string(body)] = true
+		ids[r.Header.Get("X-Request-Id")] = true
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "users.csv"), []byte("email\na@x.test\nb@x.test\nc@x.test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewApp()
+	events, unsubscribe := a.subscribeEvents(64)
+	defer unsubscribe()
+
+	// The data path is relative to the .http file, not the working directory.
+	config := `{"duration":"5s","users":2,"data":"./users.csv","dataMode":"unique"}`
+	if err := a.startLoadTest("rid", "POST", server.URL, "", "", config, "", nil, nil, ""); err == nil {
+		t.Fatal("startLoadTest resolved ./users.csv without the request file")
+	}
+	if err := a.startLoadTest("rid", "POST", server.URL, `{"X-Request-Id":"{{$uuid}}"}`, `{"email":"{{email}}"}`, config, "", nil, nil, filepath.Join(dir, "api.http")); err != nil {
+		t.Fatalf("startLoadTest: %v", err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case evt := <-events:
+			if evt.Event != loadTestDoneEventName {
+				continue
+			}
+			res := evt.Payload.(lp.DonePayload).Results
+			if res.TotalRequests != 3 {
+				t.Fatalf("total = %d, want one request per row of a unique feed", res.TotalRequests)
+			}
+			var windowed int64
+			for _, w := range res.Timeline {
+				windowed += w.Requests
+			}
+			if windowed != 3 || res.Latency == nil || res.Latency.Count != 3 {
+				t.Errorf("timeline = %+v, latency = %+v, want all 3 responses", res.Timeline, res.Latency)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, email := range []string{"a@x.test", "b@x.test", "c@x.test"} {
+				if !bodies[`{"email":"`+email+`"}`] {
+					t.Errorf("no request for %s in %v", email, bodies)
+				}
+			}
+			if len(ids) != 3 {
+				t.Errorf("request ids = %v, want a fresh {{$uuid}} per request", ids)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for loadtest:done")
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	lp "rawrequest/internal/loadtestpayload"
+)
+
+func TestLoadTestThresholdsAbortOnceCrossed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	a := NewApp()
+	events, unsubscribe := a.subscribeEvents(64)
+	defer unsubscribe()
+
+	config := `{"users":20,"duration":"20s","thresholds":"p95<5ms, rps>1000000","thresholdsAbort":true}`
+	if err := a.startLoadTest("rid", "GET", server.URL, "", "", config, "", nil, nil, ""); err != nil {
+		t.Fatalf("startLoadTest: %v", err)
+	}
+
+	timeout := time.After(15 * time.Second)
+	for {
+		select {
+		case evt := <-events:
+			if evt.Event != loadTestDoneEventName {
+				continue
+			}
+			res := evt.Payload.(lp.DonePayload).Results
+			if !res.Aborted || !strings.Contains(res.AbortReason, "p95<5ms") {
+				t.Fatalf("aborted = %v (%q), want the p95 threshold to abort the run", res.Aborted, res.AbortReason)
+			}
+			if len(res.Thresholds) != 2 || res.Thresholds[0].Passed || res.Thresholds[1].Passed {
+				t.Fatalf("thresholds = %+v, want both reported as failed", res.Thresholds)
+			}
+			return
+		case <-timeout:
+			t.Fatal("timed out waiting for loadtest:done")
+		}
+	}
+}
@@ -2,9 +2,9 @@ package app
 
 import (
 	"fmt"
+	"os"
 	"rawrequest/internal/cli"
 	"rawrequest/internal/mockserver"
-	"sync"
 	"time"
 )
 
@@ -12,6 +12,7 @@ type MockServerStatus struct {
 	Running bool   `json:"running"`
 	Port    int    `json:"port"`
 	DBPath  string `json:"dbPath"`
+	File    string `json:"file"`
 }
 
 type MockServerLogEntry struct {
@@ -19,104 +20,155 @@ type MockServerLogEntry struct {
 	Level     string `json:"level"`
 	Source    string `json:"source"`
 	Message   string `json:"message"`
+	Port      int    `json:"port"`
 }
 
-var (
-	guiMockRunning bool
-	guiMockPort    int
-	guiMockDBPath  string
-	guiMockMu      sync.RWMutex
-)
-
-// StartMockServer starts the mock server from the given file content in a background goroutine.
+// StartMockServer starts a mock server from the given file content. Several
+// servers can run at once as long as they use different ports. When filePath
+// exists on disk its routes are reloaded whenever the file is saved.
 func (a *App) StartMockServer(content string, filePath string, port int, dbPath string) error {
-	guiMockMu.Lock()
-	defer guiMockMu.Unlock()
-
-	if guiMockRunning {
-		return fmt.Errorf("a mock server is already running in the application")
-	}
-
 	parsed := cli.ParseHttpFile(content)
 	if len(parsed.Requests) == 0 {
 		return fmt.Errorf("no requests found in file")
 	}
 
-	var mockReqs []mockserver.MockRequest
-	for _, req := range parsed.Requests {
-		if req.IsMock {
-			mockReqs = append(mockReqs, mockserver.MockRequest{
-				Name:       req.Name,
-				Method:     req.Method,
-				URL:        req.URL,
-				Headers:    req.Headers,
-				Body:       req.Body,
-				PreScript:  req.PreScript,
-				PostScript: req.PostScript,
-			})
+	mockReqs := cli.MockRequests(parsed)
+	if len(mockReqs) == 0 {
+		return fmt.Errorf("no mock endpoint definitions found in file (use the @mock annotation to mark a request block as a mock endpoint)")
+	}
+
+	a.mockServersMu.Lock()
+	defer a.mockServersMu.Unlock()
+	for _, running := range a.mockServers {
+		if port != 0 && running.Port() == port {
+			return fmt.Errorf("a mock server is already running on port %d", port)
 		}
 	}
 
-	if len(mockReqs) == 0 {
-		return fmt.Errorf("no mock endpoint definitions found in file (use the @mock annotation to mark a request block as a mock endpoint)")
+	// Scripts see the file's variables overlaid with the app's, and the
+	// active environment.
+	variables := make(map[string]string, len(parsed.Variables))
+	for k, v := range parsed.Variables {
+		variables[k] = v
+	}
+	for k, v := range a.variablesSnapshot() {
+		variables[k] = v
 	}
 
-	// Register log listener to forward logs to Wails frontend
-	mockserver.LogListener = func(level, source, message string) {
-		a.emitEvent("mock-server-log", MockServerLogEntry{
-			Timestamp: time.Now().Format("15:04:05"),
-			Level:     level,
-			Source:    source,
-			Message:   message,
+	var srv *mockserver.Server
+	// Forward logs to the frontend, tagged with the port once it is known.
+	srv = mockserver.New(mockserver.Config{
+		File:      filePath,
+		Port:      port,
+		DBPath:    dbPath,
+		Requests:  mockReqs,
+		Variables: variables,
+		Env:       a.currentEnvVarsSnapshot(),
+		ScriptLog: a.appendScriptLog,
+		LogListener: func(level, source, message string) {
+			a.emitMockServerLog(srv.Port(), level, source, message)
+		},
+	})
+	if err := srv.Start(); err != nil {
+		return err
+	}
+	a.mockServers = append(a.mockServers, srv)
+	if _, err := os.Stat(filePath); err == nil {
+		srv.WatchFile(filePath, mockserver.DefaultWatchInterval, func(content []byte) []mockserver.MockRequest {
+			return cli.MockRequests(cli.ParseHttpFile(string(content)))
 		})
 	}
 
-	guiMockRunning = true
-	guiMockPort = port
-	guiMockDBPath = dbPath
-
 	go func() {
-		err := mockserver.StartMockServer(filePath, port, dbPath, mockReqs)
-		guiMockMu.Lock()
-		guiMockRunning = false
-		guiMockPort = 0
-		guiMockDBPath = ""
-		guiMockMu.Unlock()
-
+		err := srv.Wait()
+		a.removeMockServer(srv)
 		if err != nil {
-			a.emitEvent("mock-server-log", MockServerLogEntry{
-				Timestamp: time.Now().Format("15:04:05"),
-				Level:     "error",
-				Source:    "mockserver",
-				Message:   fmt.Sprintf("[Mock Server Error] Server exited: %v", err),
-			})
+			a.emitMockServerLog(srv.Port(), "error", "mockserver", fmt.Sprintf("[Mock Server Error] Server exited: %v", err))
 		}
 	}()
 
 	return nil
 }
 
-// StopMockServer stops the running mock server.
+func (a *App) emitMockServerLog(port int, level, source, message string) {
+	a.emitEvent("mock-server-log", MockServerLogEntry{
+		Timestamp: time.Now().Format("15:04:05"),
+		Level:     level,
+		Source:    source,
+		Message:   message,
+		Port:      port,
+	})
+}
+
+func (a *App) removeMockServer(srv *mockserver.Server) {
+	a.mockServersMu.Lock()
+	defer a.mockServersMu.Unlock()
+	for i, candidate := range a.mockServers {
+		if candidate == srv {
+			a.mockServers = append(a.mockServers[:i], a.mockServers[i+1:]...)
+			return
+		}
+	}
+}
+
+// StopMockServer stops every running mock server.
 func (a *App) StopMockServer() error {
-	err := mockserver.StopMockServer()
-	
-	guiMockMu.Lock()
-	guiMockRunning = false
-	guiMockPort = 0
-	guiMockDBPath = ""
-	guiMockMu.Unlock()
-
-	return err
+	a.mockServersMu.Lock()
+	servers := append([]*mockserver.Server(nil), a.mockServers...)
+	a.mockServers = nil
+	a.mockServersMu.Unlock()
+
+	var firstErr error
+	for _, srv := range servers {
+		if err := srv.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// StopMockServerOnPort stops the mock server listening on port.
+func (a *App) StopMockServerOnPort(port int) error {
+	a.mockServersMu.Lock()
+	var srv *mockserver.Server
+	for _, candidate := range a.mockServers {
+		if candidate.Port() == port {
+			srv = candidate
+			break
+		}
+	}
+	a.mockServersMu.Unlock()
+	if srv == nil {
+		return fmt.Errorf("no mock server is running on port %d", port)
+	}
+	a.removeMockServer(srv)
+	return srv.Close()
 }
 
-// GetMockServerStatus returns the status of the GUI mock server.
+// GetMockServerStatus returns the status of the most recently started mock
+// server.
 func (a *App) GetMockServerStatus() (MockServerStatus, error) {
-	guiMockMu.RLock()
-	defer guiMockMu.RUnlock()
-
-	return MockServerStatus{
-		Running: guiMockRunning,
-		Port:    guiMockPort,
-		DBPath:  guiMockDBPath,
-	}, nil
+	servers := a.ListMockServers()
+	if len(servers) == 0 {
+		return MockServerStatus{}, nil
+	}
+	return servers[len(servers)-1], nil
+}
+
+// ListMockServers returns the status of every running mock server, oldest
+// first.
+func (a *App) ListMockServers() []MockServerStatus {
+	a.mockServersMu.Lock()
+	defer a.mockServersMu.Unlock()
+	statuses := make([]MockServerStatus, 0, len(a.mockServers))
+	for _, srv := range a.mockServers {
+		cfg := srv.Config()
+		statuses = append(statuses, MockServerStatus{
+			Running: srv.Running(),
+			Port:    srv.Port(),
+			DBPath:  cfg.DBPath,
+			File:    cfg.File,
+		})
+	}
+	return statuses
 }
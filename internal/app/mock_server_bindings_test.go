@@ -9,8 +9,6 @@ import (
 	"strings"
 	"testing"
 	"time"
-
-	"rawrequest/internal/mockserver"
 )
 
 func TestStartMockServerUsesSQLiteThroughDesktopBinding(t *testing.T) {
@@ -73,8 +71,28 @@ GET /users
 		t.Fatalf("users = %v, want one Desktop Alice row", users)
 	}
 
-	if err := mockserver.StopMockServer(); err != nil {
-		t.Fatalf("mockserver.StopMockServer() error = %v", err)
+	otherPort := getFreePort(t)
+	otherDB := filepath.Join(t.TempDir(), "other-mock.db")
+	if err := app.StartMockServer(content, "other.http", otherPort, otherDB); err != nil {
+		t.Fatalf("StartMockServer() second server error = %v", err)
+	}
+	if err := app.StartMockServer(content, "dup.http", otherPort, ""); err == nil {
+		t.Fatal("expected an error starting a second server on the same port")
+	}
+	waitForServer(t, otherPort, "/users")
+	if others := getJSON[[]map[string]interface{}](t, otherPort, "/users"); len(others) != 0 {
+		t.Fatalf("second server users = %v, want its own empty table", others)
+	}
+	if servers := app.ListMockServers(); len(servers) != 2 {
+		t.Fatalf("ListMockServers() = %v, want 2 servers", servers)
+	}
+
+	if err := app.StopMockServerOnPort(port); err != nil {
+		t.Fatalf("StopMockServerOnPort() error = %v", err)
+	}
+	servers := app.ListMockServers()
+	if len(servers) != 1 || servers[0].Port != otherPort || servers[0].File != "other.http" {
+		t.Fatalf("ListMockServers() after stop = %v, want only the server on %d", servers, otherPort)
 	}
 }
 
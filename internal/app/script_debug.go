@@ -0,0 +1,42 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	sd "rawrequest/internal/scriptdebug"
+)
+
+type ScriptDebugResumed struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+}
+
+func (a *App) publishScriptDebugPause(state sd.PausedState) {
+	a.emitEvent(scriptDebugPausedEvent, state)
+}
+
+func (a *App) publishScriptDebugResume(id string, action sd.Action) {
+	a.emitEvent(scriptDebugResumedEvent, ScriptDebugResumed{ID: id, Action: string(action)})
+}
+
+// ConfigureScriptDebug enables or disables pausing on `debugger;` statements
+// and on the given breakpoints, keyed by script source ("pre:<name>",
+// "post:<name>" or "*" for every script) with 1-based script lines.
+func (a *App) ConfigureScriptDebug(enabled bool, breakpoints map[string][]int) {
+	a.scriptDebugger.Configure(enabled, breakpoints)
+}
+
+// ResumeScriptDebug resumes a paused script; action is "continue" or "step".
+func (a *App) ResumeScriptDebug(id, action string) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("pause id is required")
+	}
+	return a.scriptDebugger.Resume(id, sd.Action(strings.ToLower(strings.TrimSpace(action))))
+}
+
+// PausedScriptDebugIDs lets a reconnecting UI find scripts still waiting on it.
+func (a *App) PausedScriptDebugIDs() []string {
+	return a.scriptDebugger.PausedIDs()
+}
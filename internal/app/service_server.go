@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"rawrequest/internal/cli"
+	lt "rawrequest/internal/loadtest"
 	"rawrequest/internal/secretvaultlogic"
 )
 
@@ -70,6 +71,10 @@ func (s *httpService) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/v1/get-script-logs", s.handleGetScriptLogs)
 	mux.HandleFunc("/v1/clear-script-logs", s.handleClearScriptLogs)
 	mux.HandleFunc("/v1/record-script-log", s.handleRecordScriptLog)
+	mux.HandleFunc("/v1/script-debug/configure", s.handleConfigureScriptDebug)
+	mux.HandleFunc("/v1/script-debug/paused", s.handleScriptDebugPaused)
+	mux.HandleFunc("/v1/script-debug/continue", s.handleScriptDebugResume("continue"))
+	mux.HandleFunc("/v1/script-debug/step", s.handleScriptDebugResume("step"))
 	mux.HandleFunc("/v1/load-file-history-from-dir", s.handleLoadFileHistoryFromDir)
 	mux.HandleFunc("/v1/load-file-history-from-run-location", s.handleLoadFileHistoryFromRunLocation)
 	mux.HandleFunc("/v1/save-response-file", s.handleSaveResponseFile)
@@ -323,12 +328,19 @@ func (s *httpService) handleCancelRequest(w http.ResponseWriter, r *http.Request
 }
 
 type startLoadTestPayload struct {
-	RequestID      string `json:"requestId"`
-	Method         string `json:"method"`
-	URL            string `json:"url"`
-	HeadersJSON    string `json:"headersJson"`
-	Body           string `json:"body"`
-	LoadConfigJSON string `json:"loadConfigJson"`
+	RequestID      string   `json:"requestId"`
+	Method         string   `json:"method"`
+	URL            string   `json:"url"`
+	HeadersJSON    string   `json:"headersJson"`
+	Body           string   `json:"body"`
+	LoadConfigJSON string   `json:"loadConfigJson"`
+	PostScript     string   `json:"postScript,omitempty"`
+	Assertions     []string `json:"assertions,omitempty"`
+	// Steps makes the load test a scenario; method and url are then ignored.
+	Steps []lt.Step `json:"steps,omitempty"`
+	// RequestFilePath is the .http file the test came from; relative data
+	// files are resolved against its directory.
+	RequestFilePath string `json:"requestFilePath,omitempty"`
 }
 
 func (s *httpService) handleStartLoadTest(w http.ResponseWriter, r *http.Request) {
@@ -340,7 +352,7 @@ func (s *httpService) handleStartLoadTest(w http.ResponseWriter, r *http.Request
 		writeServiceError(w, http.StatusBadRequest, err)
 		return
 	}
-	if err := s.app.startLoadTest(payload.RequestID, payload.Method, payload.URL, payload.HeadersJSON, payload.Body, payload.LoadConfigJSON); err != nil {
+	if err := s.app.startLoadTest(payload.RequestID, payload.Method, payload.URL, payload.HeadersJSON, payload.Body, payload.LoadConfigJSON, payload.PostScript, payload.Assertions, payload.Steps, payload.RequestFilePath); err != nil {
 		writeServiceError(w, http.StatusBadRequest, err)
 		return
 	}
@@ -425,6 +437,58 @@ func (s *httpService) handleRecordScriptLog(w http.ResponseWriter, r *http.Reque
 	w.WriteHeader(http.StatusNoContent)
 }
 
+type configureScriptDebugPayload struct {
+	Enabled     bool             `json:"enabled"`
+	Breakpoints map[string][]int `json:"breakpoints"`
+}
+
+func (s *httpService) handleConfigureScriptDebug(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePost(w, r) {
+		return
+	}
+	var payload configureScriptDebugPayload
+	if err := decodeServicePayload(r, &payload); err != nil {
+		writeServiceError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.app.ConfigureScriptDebug(payload.Enabled, payload.Breakpoints)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *httpService) handleScriptDebugPaused(w http.ResponseWriter, r *http.Request) {
+	if !s.requirePost(w, r) {
+		return
+	}
+	var payload struct{}
+	if err := decodeServicePayload(r, &payload); err != nil {
+		writeServiceError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeServiceJSON(w, s.app.PausedScriptDebugIDs())
+}
+
+type resumeScriptDebugPayload struct {
+	ID string `json:"id"`
+}
+
+func (s *httpService) handleScriptDebugResume(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !s.requirePost(w, r) {
+			return
+		}
+		var payload resumeScriptDebugPayload
+		if err := decodeServicePayload(r, &payload); err != nil {
+			writeServiceError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := s.app.ResumeScriptDebug(payload.ID, action); err != nil {
+			writeServiceError(w, http.StatusNotFound, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 type loadFileHistoryFromDirPayload struct {
 	FileID string `json:"fileId"`
 	Dir    string `json:"dir"`
@@ -10,6 +10,9 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	sd "rawrequest/internal/scriptdebug"
+	sr "rawrequest/internal/scriptruntime"
 )
 
 func TestServiceEventsEndpointStreamsPublishedEvents(t *testing.T) {
@@ -133,3 +136,67 @@ func TestServiceScriptLogEndpoints(t *testing.T) {
 		t.Fatalf("logs after clear=%d, want 0", len(logs))
 	}
 }
+
+func TestServiceScriptDebugPausesAndResumes(t *testing.T) {
+	svc := &httpService{app: NewApp()}
+	mux := http.NewServeMux()
+	svc.registerRoutes(mux)
+	server := httptest.NewServer(withServiceCORS(mux))
+	defer server.Close()
+
+	postJSON := func(path string, payload any) int {
+		t.Helper()
+		body, err := json.Marshal(payload)
+		if err != nil {
+			t.Fatalf("marshal payload: %v", err)
+		}
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("post %s: %v", path, err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if status := postJSON("/v1/script-debug/configure", map[string]any{"enabled": true}); status != http.StatusNoContent {
+		t.Fatalf("configure status=%d, want %d", status, http.StatusNoContent)
+	}
+	events, unsubscribe := svc.app.subscribeEvents(8)
+	defer unsubscribe()
+
+	ctx := &sr.ExecutionContext{Request: map[string]interface{}{"name": "login"}}
+	done := make(chan struct{})
+	go func() {
+		svc.app.executeScript("const token = 'abc';\ndebugger;\nsetVar('token', token);", ctx, "post")
+		close(done)
+	}()
+
+	var paused sd.PausedState
+	select {
+	case evt := <-events:
+		if evt.Event != scriptDebugPausedEvent {
+			t.Fatalf("event=%q, want %s", evt.Event, scriptDebugPausedEvent)
+		}
+		paused = evt.Payload.(sd.PausedState)
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for pause event")
+	}
+	if paused.Line != 2 || paused.Locals["token"] != "abc" {
+		t.Fatalf("paused=%+v, want line 2 with token local", paused)
+	}
+
+	if status := postJSON("/v1/script-debug/continue", map[string]any{"id": "nope"}); status != http.StatusNotFound {
+		t.Fatalf("unknown id status=%d, want %d", status, http.StatusNotFound)
+	}
+	if status := postJSON("/v1/script-debug/continue", map[string]any{"id": paused.ID}); status != http.StatusNoContent {
+		t.Fatalf("continue status=%d, want %d", status, http.StatusNoContent)
+	}
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("script did not resume")
+	}
+	if got, _ := svc.app.getVariable("token"); got != "abc" {
+		t.Fatalf("token=%q, want abc", got)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"rawrequest/internal/filewatch"
 	"rawrequest/internal/importers"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -345,7 +346,7 @@ func (a *App) updateWatchedFileTime(filePath string) {
 			// Recompute the content hash too so a freshly-saved file does not
 			// register as "externally modified" on the next watcher tick.
 			if data, err := os.ReadFile(filePath); err == nil {
-				state.contentHash = hashFileContent(data)
+				state.contentHash = filewatch.HashContent(data)
 			}
 			a.watchedFiles[filePath] = state
 		}
@@ -401,7 +402,7 @@ func (a *App) checkWatchedFiles() {
 			continue
 		}
 
-		newHash := hashFileContent(data)
+		newHash := filewatch.HashContent(data)
 		state.modTime = modTime
 		if state.contentHash == newHash {
 			a.watchedFiles[fp] = state
@@ -0,0 +1,196 @@
+package assertions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"rawrequest/internal/jsonpath"
+	sr "rawrequest/internal/scriptruntime"
+)
+
+// Stage is the AssertionResult stage recorded for declarative assertions.
+const Stage = "assert"
+
+// Assertion is a compiled `@assert` directive. Compiling once lets hot paths
+// such as load tests evaluate the same assertion many times without
+// re-parsing it.
+type Assertion struct {
+	Expr
+}
+
+// assertGrammar lists the subjects `@assert` accepts.
+var assertGrammar = Grammar{
+	Noun: "assertion",
+	Subjects: map[string]Subject{
+		"status":       {Name: "status"},
+		"body":         {Name: "body"},
+		"size":         {Name: "size"},
+		"duration":     {Name: "duration"},
+		"time":         {Name: "duration"},
+		"responsetime": {Name: "duration"},
+		"header":       {Name: "header", Arg: "header name"},
+		"jsonpath":     {Name: "jsonpath", Arg: "path"},
+		"json":         {Name: "jsonpath", Arg: "path"},
+	},
+}
+
+// Compile parses the text following `@assert`, e.g.
+//
+//	status == 201
+//	header Content-Type contains json
+//	jsonpath $.id exists
+//	duration < 500ms
+func Compile(expr string) (Assertion, error) {
+	e, err := ParseExpr(expr, assertGrammar)
+	a := Assertion{Expr: e}
+	if err != nil {
+		return a, err
+	}
+	if a.Subject == "duration" && a.Op.NeedsValue() {
+		ms, err := parseDurationMs(a.Expected)
+		if err != nil {
+			return a, err
+		}
+		a.Expected = strconv.FormatInt(ms, 10)
+	}
+	return a, nil
+}
+
+// Check evaluates the assertion against a response map shaped like the one
+// scripts see as `response` (status, headers, body, json, responseTime, size).
+func (a Assertion) Check(response map[string]interface{}) sr.AssertionResult {
+	actual, present := a.actual(response)
+	passed, err := Compare(actual, present, a.Op, a.Expected)
+	if err != nil {
+		return sr.AssertionResult{Passed: false, Message: fmt.Sprintf("%s (%v)", a.Raw, err), Stage: Stage}
+	}
+	if passed {
+		return sr.AssertionResult{Passed: true, Message: a.Raw, Stage: Stage}
+	}
+	got := "missing"
+	if present {
+		got = Stringify(actual)
+		if a.Subject == "duration" {
+			got += "ms"
+		}
+	}
+	return sr.AssertionResult{Passed: false, Message: fmt.Sprintf("%s (got %s)", a.Raw, truncate(got, 120)), Stage: Stage}
+}
+
+// Evaluate compiles and checks each expression in order. Expressions that do
+// not compile are reported as failed results rather than dropped.
+func Evaluate(exprs []string, response map[string]interface{}) []sr.AssertionResult {
+	if len(exprs) == 0 {
+		return nil
+	}
+	results := make([]sr.AssertionResult, 0, len(exprs))
+	for _, expr := range exprs {
+		a, err := Compile(expr)
+		if err != nil {
+			results = append(results, sr.AssertionResult{
+				Passed:  false,
+				Message: fmt.Sprintf("invalid assertion %q: %v", strings.TrimSpace(expr), err),
+				Stage:   Stage,
+			})
+			continue
+		}
+		results = append(results, a.Check(response))
+	}
+	return results
+}
+
+// FromValue reads the assertion list stored on a request map. Depending on
+// how the request reached Go it may be a []string or a JSON-decoded
+// []interface{}.
+func FromValue(v interface{}) []string {
+	switch t := v.(type) {
+	case []string:
+		return t
+	case []interface{}:
+		out := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return nil
+		}
+		return []string{t}
+	}
+	return nil
+}
+
+func (a Assertion) actual(response map[string]interface{}) (interface{}, bool) {
+	if response == nil {
+		return nil, false
+	}
+	switch a.Subject {
+	case "status":
+		v, ok := response["status"]
+		return v, ok
+	case "duration":
+		v, ok := response["responseTime"]
+		return v, ok
+	case "size":
+		v, ok := response["size"]
+		return v, ok
+	case "body":
+		v, ok := response["body"]
+		return v, ok
+	case "header":
+		return lookupHeader(response["headers"], a.Arg)
+	case "jsonpath":
+		data, ok := response["json"]
+		if !ok {
+			body, _ := response["body"].(string)
+			if json.Unmarshal([]byte(body), &data) != nil {
+				return nil, false
+			}
+		}
+		return jsonpath.Lookup(data, a.Arg)
+	}
+	return nil, false
+}
+
+func lookupHeader(headers interface{}, name string) (interface{}, bool) {
+	switch h := headers.(type) {
+	case map[string]string:
+		for k, v := range h {
+			if strings.EqualFold(k, name) {
+				return v, true
+			}
+		}
+	case map[string]interface{}:
+		for k, v := range h {
+			if strings.EqualFold(k, name) {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func parseDurationMs(raw string) (int64, error) {
+	raw = strings.TrimSpace(raw)
+	if ms, err := strconv.ParseFloat(raw, 64); err == nil {
+		return int64(ms), nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", raw)
+	}
+	return d.Milliseconds(), nil
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
@@ -0,0 +1,97 @@
+package assertions
+
+import (
+	"strings"
+	"testing"
+)
+
+func sampleResponse() map[string]interface{} {
+	return map[string]interface{}{
+		"status":       201,
+		"headers":      map[string]string{"content-type": "application/json; charset=utf-8"},
+		"body":         `{"id":42,"name":"Ada Lovelace","items":[1,2,3]}`,
+		"responseTime": int64(120),
+		"size":         int64(48),
+	}
+}
+
+func TestEvaluate_Passes(t *testing.T) {
+	exprs := []string{
+		"status == 201",
+		"status < 300",
+		"header Content-Type contains json",
+		"jsonpath $.id exists",
+		"jsonpath $.id == 42",
+		`jsonpath $.name == "Ada Lovelace"`,
+		"jsonpath $.items contains 2",
+		"jsonpath $.items.length >= 3",
+		"jsonpath $.missing not exists",
+		"duration < 500ms",
+		"duration <= 1s",
+		"body matches ^\\{",
+		"size > 0",
+	}
+	for _, res := range Evaluate(exprs, sampleResponse()) {
+		if !res.Passed {
+			t.Errorf("expected pass: %s", res.Message)
+		}
+		if res.Stage != Stage {
+			t.Errorf("stage=%q want %q", res.Stage, Stage)
+		}
+	}
+}
+
+func TestEvaluate_FailuresReportActualValue(t *testing.T) {
+	results := Evaluate([]string{
+		"status == 200",
+		"duration < 100ms",
+		"header X-Request-Id exists",
+	}, sampleResponse())
+	if len(results) != 3 {
+		t.Fatalf("results=%d want 3", len(results))
+	}
+	for _, res := range results {
+		if res.Passed {
+			t.Errorf("expected failure: %s", res.Message)
+		}
+	}
+	if results[0].Message != "status == 200 (got 201)" {
+		t.Errorf("message=%q", results[0].Message)
+	}
+	if results[1].Message != "duration < 100ms (got 120ms)" {
+		t.Errorf("message=%q", results[1].Message)
+	}
+}
+
+func TestEvaluate_InvalidExpressionFails(t *testing.T) {
+	for _, expr := range []string{"", "latency < 5", "status", "status ~~ 1", "jsonpath", "status exists 1", "duration < soon"} {
+		results := Evaluate([]string{expr}, sampleResponse())
+		if len(results) != 1 || results[0].Passed {
+			t.Errorf("expected %q to produce a failed result, got %#v", expr, results)
+			continue
+		}
+		if !strings.HasPrefix(results[0].Message, "invalid assertion") {
+			t.Errorf("message=%q", results[0].Message)
+		}
+	}
+}
+
+func TestCompile_KeepsQuotedValueSpacing(t *testing.T) {
+	a, err := Compile(`header X-Contains contains "two  words"`)
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if a.Arg != "X-Contains" || a.Op != OpContains || a.Expected != `"two  words"` {
+		t.Fatalf("unexpected assertion: %#v", a)
+	}
+}
+
+func TestFromValue(t *testing.T) {
+	got := FromValue([]interface{}{"status == 200", 3, " "})
+	if len(got) != 1 || got[0] != "status == 200" {
+		t.Fatalf("FromValue=%v", got)
+	}
+	if FromValue(nil) != nil {
+		t.Fatalf("expected nil for missing value")
+	}
+}
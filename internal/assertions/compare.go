@@ -0,0 +1,194 @@
+package assertions
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Operator string
+
+const (
+	OpEqual       Operator = "=="
+	OpNotEqual    Operator = "!="
+	OpLess        Operator = "<"
+	OpLessEq      Operator = "<="
+	OpGreater     Operator = ">"
+	OpGreaterEq   Operator = ">="
+	OpContains    Operator = "contains"
+	OpNotContains Operator = "!contains"
+	OpMatches     Operator = "matches"
+	OpExists      Operator = "exists"
+	OpNotExists   Operator = "!exists"
+)
+
+// ParseOperator normalizes the operator spellings accepted in directives.
+func ParseOperator(raw string) (Operator, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "==", "=", "eq", "is":
+		return OpEqual, true
+	case "!=", "<>", "ne":
+		return OpNotEqual, true
+	case "<", "lt":
+		return OpLess, true
+	case "<=", "le", "lte":
+		return OpLessEq, true
+	case ">", "gt":
+		return OpGreater, true
+	case ">=", "ge", "gte":
+		return OpGreaterEq, true
+	case "contains", "includes":
+		return OpContains, true
+	case "!contains", "not contains", "not-contains":
+		return OpNotContains, true
+	case "matches", "~=":
+		return OpMatches, true
+	case "exists":
+		return OpExists, true
+	case "!exists", "not exists", "not-exists", "missing":
+		return OpNotExists, true
+	}
+	return "", false
+}
+
+// NeedsValue reports whether the operator takes a right-hand operand.
+func (op Operator) NeedsValue() bool {
+	return op != OpExists && op != OpNotExists
+}
+
+// Compare applies op to an actual value (as decoded from JSON or taken from
+// the response map) and the literal expected text from the directive.
+// present is false when the subject could not be found at all.
+func Compare(actual interface{}, present bool, op Operator, expected string) (bool, error) {
+	switch op {
+	case OpExists:
+		return present && actual != nil, nil
+	case OpNotExists:
+		return !present || actual == nil, nil
+	}
+	if !present {
+		return op == OpNotEqual || op == OpNotContains, nil
+	}
+
+	want := ParseLiteral(expected)
+	switch op {
+	case OpEqual:
+		return valuesEqual(actual, want), nil
+	case OpNotEqual:
+		return !valuesEqual(actual, want), nil
+	case OpLess, OpLessEq, OpGreater, OpGreaterEq:
+		a, okA := toNumber(actual)
+		b, okB := toNumber(want)
+		if !okA || !okB {
+			return false, fmt.Errorf("%s requires numeric operands", op)
+		}
+		switch op {
+		case OpLess:
+			return a < b, nil
+		case OpLessEq:
+			return a <= b, nil
+		case OpGreater:
+			return a > b, nil
+		default:
+			return a >= b, nil
+		}
+	case OpContains:
+		return contains(actual, want), nil
+	case OpNotContains:
+		return !contains(actual, want), nil
+	case OpMatches:
+		re, err := regexp.Compile(Stringify(want))
+		if err != nil {
+			return false, fmt.Errorf("invalid regex: %w", err)
+		}
+		return re.MatchString(Stringify(actual)), nil
+	}
+	return false, fmt.Errorf("unsupported operator %q", op)
+}
+
+// ParseLiteral interprets directive text the way a JSON value would be read:
+// quoted strings, numbers, booleans and null. Anything else stays a string.
+func ParseLiteral(raw string) interface{} {
+	raw = strings.TrimSpace(raw)
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return raw[1 : len(raw)-1]
+	}
+	switch raw {
+	case "true":
+		return true
+	case "false":
+		return false
+	case "null":
+		return nil
+	}
+	if n, err := strconv.ParseFloat(raw, 64); err == nil {
+		return n
+	}
+	return raw
+}
+
+// Stringify renders a value for comparison and messages. Objects and arrays
+// are rendered as compact JSON.
+func Stringify(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		if b, err := json.Marshal(t); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+func valuesEqual(actual, want interface{}) bool {
+	if a, ok := toNumber(actual); ok {
+		if b, ok := toNumber(want); ok {
+			return a == b
+		}
+	}
+	if want == nil {
+		return actual == nil
+	}
+	return Stringify(actual) == Stringify(want)
+}
+
+func contains(actual, want interface{}) bool {
+	if items, ok := actual.([]interface{}); ok {
+		for _, item := range items {
+			if valuesEqual(item, want) {
+				return true
+			}
+		}
+		return false
+	}
+	return strings.Contains(Stringify(actual), Stringify(want))
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case float32:
+		return float64(t), true
+	case int:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
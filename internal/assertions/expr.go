@@ -0,0 +1,107 @@
+package assertions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is a parsed `subject [arg] operator [value]` expression. `@assert`
+// and the mock server's `@match` share this grammar and differ only in the
+// subjects they accept.
+type Expr struct {
+	Raw      string
+	Subject  string
+	Arg      string
+	Op       Operator
+	Expected string
+}
+
+// Subject describes one subject a Grammar accepts.
+type Subject struct {
+	// Name is the canonical subject; aliases map to it.
+	Name string
+	// Arg labels the argument the subject takes, e.g. "header name". It is
+	// empty for subjects without an argument.
+	Arg string
+	// KeyValue also accepts the compact `subject key=value` form, read as
+	// `subject key == value`.
+	KeyValue bool
+}
+
+// Grammar is the set of subjects an expression may start with, keyed by the
+// lower-case word used in the source. Noun names the expression in errors.
+type Grammar struct {
+	Noun     string
+	Subjects map[string]Subject
+}
+
+// ParseExpr parses expr against g.
+func ParseExpr(expr string, g Grammar) (Expr, error) {
+	raw := strings.TrimSpace(expr)
+	e := Expr{Raw: raw}
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return e, fmt.Errorf("empty %s", g.Noun)
+	}
+
+	subject, ok := g.Subjects[strings.ToLower(fields[0])]
+	if !ok {
+		return e, fmt.Errorf("unknown %s subject %q", g.Noun, fields[0])
+	}
+	e.Subject = subject.Name
+	rest := fields[1:]
+	if subject.Arg != "" {
+		if len(rest) == 0 {
+			return e, fmt.Errorf("%s %s needs a %s", e.Subject, g.Noun, subject.Arg)
+		}
+		e.Arg = rest[0]
+		rest = rest[1:]
+		if len(rest) == 0 && subject.KeyValue {
+			if key, value, ok := strings.Cut(e.Arg, "="); ok && key != "" {
+				e.Arg, e.Op, e.Expected = key, OpEqual, value
+				return e, nil
+			}
+		}
+	}
+
+	if len(rest) == 0 {
+		return e, fmt.Errorf("missing operator")
+	}
+	opText := rest[0]
+	rest = rest[1:]
+	if strings.EqualFold(opText, "not") && len(rest) > 0 {
+		opText += " " + rest[0]
+		rest = rest[1:]
+	}
+	op, ok := ParseOperator(opText)
+	if !ok {
+		return e, fmt.Errorf("unknown operator %q", opText)
+	}
+	e.Op = op
+
+	if op.NeedsValue() {
+		if len(rest) == 0 {
+			return e, fmt.Errorf("operator %s needs a value", op)
+		}
+		// Re-slice the original text so quoted values keep their inner spacing.
+		e.Expected = afterFields(raw, len(fields)-len(rest))
+	} else if len(rest) > 0 {
+		return e, fmt.Errorf("operator %s takes no value", op)
+	}
+	return e, nil
+}
+
+// afterFields returns the remainder of s once the first n whitespace-separated
+// fields have been skipped. Expected values are re-sliced from the original
+// text with it so quoted values keep their inner spacing.
+func afterFields(s string, n int) string {
+	for i := 0; i < n; i++ {
+		s = strings.TrimLeft(s, " \t")
+		if idx := strings.IndexAny(s, " \t"); idx >= 0 {
+			s = s[idx:]
+		} else {
+			return ""
+		}
+	}
+	return strings.TrimSpace(s)
+}
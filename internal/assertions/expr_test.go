@@ -0,0 +1,32 @@
+package assertions
+
+import "testing"
+
+func TestParseExpr_Grammar(t *testing.T) {
+	g := Grammar{
+		Noun: "check",
+		Subjects: map[string]Subject{
+			"q":     {Name: "query", Arg: "name", KeyValue: true},
+			"query": {Name: "query", Arg: "name", KeyValue: true},
+			"path":  {Name: "path", Arg: "path"},
+		},
+	}
+
+	e, err := ParseExpr("Q page=2", g)
+	if err != nil || e.Subject != "query" || e.Arg != "page" || e.Op != OpEqual || e.Expected != "2" {
+		t.Fatalf("key=value form: %#v, %v", e, err)
+	}
+	e, err = ParseExpr("path a=b exists", g)
+	if err != nil || e.Arg != "a=b" || e.Op != OpExists {
+		t.Fatalf("operator form: %#v, %v", e, err)
+	}
+	if _, err := ParseExpr("path a=b", g); err == nil || err.Error() != "missing operator" {
+		t.Fatalf("key=value without KeyValue: err=%v", err)
+	}
+	if _, err := ParseExpr("cookie x", g); err == nil || err.Error() != `unknown check subject "cookie"` {
+		t.Fatalf("unknown subject: err=%v", err)
+	}
+	if _, err := ParseExpr("query", g); err == nil || err.Error() != "query check needs a name" {
+		t.Fatalf("missing arg: err=%v", err)
+	}
+}
@@ -31,6 +31,8 @@ const (
 	OutputBody  OutputFormat = "body"
 	OutputFull  OutputFormat = "full"
 	OutputQuiet OutputFormat = "quiet"
+	// OutputCSV prints the per-second series of a load test.
+	OutputCSV OutputFormat = "csv"
 )
 
 // Options holds all CLI configuration
@@ -47,22 +49,50 @@ type Options struct {
 	ServiceAddr  string
 	ShowHelp     bool
 	// Load test options
-	LoadUsers    int
-	LoadDuration string  // e.g. "30s", "2m"
-	LoadRPS      int
-	LoadRampUp   string  // e.g. "10s"
-	LoadFailRate float64
-	LoadAdaptive bool
-	LoadUsersSet    bool
-	LoadDurationSet bool
-	LoadRPSSet      bool
-	LoadRampUpSet   bool
-	LoadFailRateSet bool
-	LoadAdaptiveSet bool
-	Workspace    string  // MCP workspace root
+	LoadUsers              int
+	LoadDuration           string // e.g. "30s", "2m"
+	LoadRPS                int
+	LoadRampUp             string // e.g. "10s"
+	LoadRate               string // arrival rate, e.g. "50rps"
+	LoadStages             string // arrival-rate profile, e.g. "30s:50rps, 2m:200rps"
+	LoadThresholds         string // e.g. "p95<300ms, errorRate<1%"
+	LoadThresholdsAbort    bool
+	LoadFailRate           float64
+	LoadAdaptive           bool
+	LoadAssert             bool
+	LoadAssertSample       float64
+	LoadOut                string   // file for the per-second series
+	LoadWorkers            []string // service addresses sharing the load
+	LoadCompare            string   // saved run or -o json results to compare with
+	LoadTolerance          float64  // allowed relative change before a regression
+	LoadNoSave             bool
+	LoadUsersSet           bool
+	LoadDurationSet        bool
+	LoadRPSSet             bool
+	LoadRampUpSet          bool
+	LoadRateSet            bool
+	LoadStagesSet          bool
+	LoadThresholdsSet      bool
+	LoadThresholdsAbortSet bool
+	LoadFailRateSet        bool
+	LoadAdaptiveSet        bool
+	LoadAssertSet          bool
+	LoadAssertSampleSet    bool
+	Workspace              string // MCP workspace root
 	// Mock options
 	MockPort     int
 	MockDB       string
+	MockNoWatch  bool
+	MockProxy    string
+	MockRecord   bool
+	MockRecordTo string
+	MockBind     string
+	MockTLS      bool
+	MockCert     string
+	MockKey      string
+	MockExportCA string
+	MockOpenAPI  string
+	MockValidate bool
 
 	// Secret vault resolver
 	SecretResolver SecretResolver
@@ -143,10 +173,22 @@ func Parse(args []string) *Options {
 		fs.StringVar(&opts.LoadDuration, "duration", "30s", "Test duration (e.g. 30s, 2m)")
 		fs.IntVar(&opts.LoadRPS, "rps", 0, "Target requests per second (0=unlimited)")
 		fs.StringVar(&opts.LoadRampUp, "ramp-up", "", "Ramp-up time (e.g. 10s)")
+		fs.StringVar(&opts.LoadRate, "rate", "", "Start iterations at this arrival rate (e.g. 50rps)")
+		fs.StringVar(&opts.LoadStages, "stages", "", "Arrival-rate stages (e.g. \"30s:50rps, 2m:200rps, 30s:0rps\")")
 		fs.Float64Var(&opts.LoadFailRate, "fail-rate", 0, "Failure rate threshold to abort (0.0-1.0)")
+		fs.StringVar(&opts.LoadThresholds, "thresholds", "", "Pass/fail limits checked at the end (e.g. \"p95<300ms, errorRate<1%\")")
+		fs.BoolVar(&opts.LoadThresholdsAbort, "thresholds-abort", false, "Abort as soon as an upper threshold is crossed")
 		fs.BoolVar(&opts.LoadAdaptive, "adaptive", false, "Enable adaptive load control")
-		fs.StringVar((*string)(&opts.Output), "output", "full", "Output format: full|json|quiet")
+		fs.BoolVar(&opts.LoadAssert, "assert", false, "Check responses with the request's @assert lines and post-script")
+		fs.Float64Var(&opts.LoadAssertSample, "assert-sample", 1, "Fraction of responses to check when --assert is on (0.0-1.0)")
+		fs.StringVar((*string)(&opts.Output), "output", "full", "Output format: full|json|csv|quiet")
 		fs.StringVar((*string)(&opts.Output), "o", "full", "Output format (shorthand)")
+		fs.StringVar(&opts.LoadOut, "out", "", "Write the per-second series to a .csv or .json file")
+		fs.StringVar(&opts.LoadCompare, "compare", "", "Compare with a saved run or -o json results")
+		fs.Float64Var(&opts.LoadTolerance, "tolerance", 0.1, "Relative change allowed by --compare before a regression (0.0-1.0)")
+		fs.BoolVar(&opts.LoadNoSave, "no-save", false, "Don't save the run next to the .http file")
+		var workers string
+		fs.StringVar(&workers, "workers", "", "Comma-separated service addresses to split the load across")
 		fs.StringVar(&opts.ServiceAddr, "service", opts.ServiceAddr, "Service URL (default: auto-start)")
 		fs.IntVar(&opts.Timeout, "timeout", 30, "Per-request timeout in seconds")
 
@@ -163,6 +205,11 @@ func Parse(args []string) *Options {
 				opts.Variables[v[:idx]] = v[idx+1:]
 			}
 		}
+		for _, worker := range strings.Split(workers, ",") {
+			if worker = strings.TrimSpace(worker); worker != "" {
+				opts.LoadWorkers = append(opts.LoadWorkers, worker)
+			}
+		}
 		fs.Visit(func(f *flag.Flag) {
 			switch f.Name {
 			case "users":
@@ -173,10 +220,22 @@ func Parse(args []string) *Options {
 				opts.LoadRPSSet = true
 			case "ramp-up":
 				opts.LoadRampUpSet = true
+			case "rate":
+				opts.LoadRateSet = true
+			case "stages":
+				opts.LoadStagesSet = true
 			case "fail-rate":
 				opts.LoadFailRateSet = true
+			case "thresholds":
+				opts.LoadThresholdsSet = true
+			case "thresholds-abort":
+				opts.LoadThresholdsAbortSet = true
 			case "adaptive":
 				opts.LoadAdaptiveSet = true
+			case "assert":
+				opts.LoadAssertSet = true
+			case "assert-sample":
+				opts.LoadAssertSampleSet = true
 			}
 		})
 
@@ -184,12 +243,16 @@ func Parse(args []string) *Options {
 	}
 
 	if opts.Command == CommandMock {
-		// Need at least a file argument
+		// Need a file argument, unless the routes come from --openapi
 		if len(args) < 3 {
 			opts.ShowHelp = true
 			return opts
 		}
-		opts.File = args[2]
+		flagArgs := args[2:]
+		if !strings.HasPrefix(args[2], "-") {
+			opts.File = args[2]
+			flagArgs = args[3:]
+		}
 
 		fs := flag.NewFlagSet("rawrequest-mock", flag.ContinueOnError)
 		fs.SetOutput(os.Stderr)
@@ -197,13 +260,35 @@ func Parse(args []string) *Options {
 		fs.IntVar(&opts.MockPort, "port", 8080, "Port to run the mock server on")
 		fs.IntVar(&opts.MockPort, "p", 8080, "Port (shorthand)")
 		fs.StringVar(&opts.MockDB, "db", "", "Path to SQLite database for dynamic CRUD/persistence")
+		fs.BoolVar(&opts.MockNoWatch, "no-watch", false, "Do not reload routes when the file changes")
+		fs.StringVar(&opts.MockProxy, "proxy", "", "Forward unmatched requests to this upstream URL")
+		fs.BoolVar(&opts.MockRecord, "record", false, "Append proxied exchanges to the file as @mock blocks")
+		fs.StringVar(&opts.MockRecordTo, "record-to", "", "Record into this .http file instead of the served file")
+		fs.StringVar(&opts.MockBind, "bind", "127.0.0.1", "Interface to listen on (0.0.0.0 for all)")
+		fs.BoolVar(&opts.MockTLS, "tls", false, "Serve HTTPS with a certificate from the generated mock CA")
+		fs.StringVar(&opts.MockCert, "cert", "", "TLS certificate file (implies --tls)")
+		fs.StringVar(&opts.MockKey, "key", "", "TLS private key file (implies --tls)")
+		fs.StringVar(&opts.MockExportCA, "export-ca", "", "Write the mock CA certificate to this path and exit")
+		fs.StringVar(&opts.MockOpenAPI, "openapi", "", "Generate routes from this OpenAPI 3 document")
+		fs.BoolVar(&opts.MockValidate, "validate", false, "With --openapi, reject requests that violate the spec with 400")
+		var vars stringSlice
+		fs.StringVar(&opts.Environment, "env", "default", "Environment exposed to mock scripts as env")
+		fs.StringVar(&opts.Environment, "e", "default", "Environment (shorthand)")
+		fs.Var(&vars, "var", "Seed a getVar value: key=value (can be repeated)")
+		fs.Var(&vars, "V", "Seed a getVar value (shorthand)")
 
-		if len(args) > 3 {
-			if err := fs.Parse(args[3:]); err != nil {
-				opts.ShowHelp = true
-				return opts
+		if err := fs.Parse(flagArgs); err != nil {
+			opts.ShowHelp = true
+			return opts
+		}
+		for _, v := range vars {
+			if idx := strings.Index(v, "="); idx > 0 {
+				opts.Variables[v[:idx]] = v[idx+1:]
 			}
 		}
+		if opts.File == "" && opts.MockOpenAPI == "" && opts.MockExportCA == "" {
+			opts.ShowHelp = true
+		}
 		return opts
 	}
 
@@ -294,6 +379,7 @@ Usage:
   rawrequest run <file> [options]     Execute requests from an .http file
   rawrequest load <file> [options]    Run load tests against requests
   rawrequest mock <file> [options]    Start an instant dynamic mock server from an .http file
+  rawrequest mock --openapi <spec>    Start a mock server from an OpenAPI 3 document
   rawrequest list <file>              List all named requests in a file
   rawrequest envs <file>              List environments defined in a file
   rawrequest mcp [options]            Start MCP server for AI assistant integration
@@ -319,14 +405,40 @@ Load Test Options:
   --duration <duration>  Test duration (e.g. 30s, 2m; default: 30s)
   --rps <n>              Target requests per second (0=unlimited)
   --ramp-up <duration>   Ramp-up time to reach max users
+  --rate <rate>          Start iterations at a fixed arrival rate (e.g. 50rps);
+                         --users then caps iterations in flight
+  --stages <profile>     Ramp the arrival rate in stages (e.g. "30s:50rps, 2m:200rps")
   --fail-rate <0.0-1.0>  Failure rate threshold to abort
+  --thresholds <list>    Limits that fail the run, e.g. "p95<300ms, errorRate<1%%, rps>150"
+  --thresholds-abort     Abort as soon as an upper threshold is crossed
   --adaptive             Enable adaptive load control
-  -o, --output <format>  Output: full|json|quiet (default: full)
+  --assert               Check each response with @assert lines and the post-script
+  --assert-sample <0-1>  Fraction of responses to check (default: 1)
+  -o, --output <format>  Output: full|json|csv|quiet (default: full)
+                         csv prints the per-second series
+  --out <file>           Also write the per-second series to a .csv or .json file
+  --workers <addrs>      Split users and RPS across these services (host:port,...)
+  --compare <file>       Compare with a saved run and fail on regressions
+  --tolerance <0.0-1.0>  Relative change allowed by --compare (default: 0.1)
+  --no-save              Don't save the run to <file>.responses/loadtests/
   --service <url>        Service URL (default: auto-start on 127.0.0.1:7345)
 
 Mock Options:
   -p, --port <n>         Port to run the mock server on (default: 8080)
   --db <path>            Path to SQLite database for dynamic CRUD/persistence
+  --no-watch             Do not reload routes when the file changes
+  --proxy <url>          Forward requests no mock matches to this upstream
+  --record               With --proxy, append each exchange to the file as @mock
+  --record-to <file>     Record into another .http file (implies --record)
+  --bind <host>          Interface to listen on (default: 127.0.0.1; 0.0.0.0 for all)
+  --tls                  Serve HTTPS with a certificate from the generated mock CA
+  --cert <file>          TLS certificate file (implies --tls, needs --key)
+  --key <file>           TLS private key file
+  --export-ca <path>     Write the mock CA certificate to <path> and exit
+  --openapi <spec>       Generate routes from an OpenAPI 3 document (file optional)
+  --validate             With --openapi, answer 400 to requests that violate the spec
+  -e, --env <env>        Environment exposed to mock scripts as env (default: "default")
+  -V, --var <key=value>  Seed a value scripts read with getVar (can be repeated)
 
 Service Options:
   --addr <host:port>     Address to bind (default: 127.0.0.1:7345)
@@ -366,6 +478,24 @@ Examples:
   # Run a load test
   rawrequest load api.http -n "getUsers" --users 50 --duration 60s --rps 200
 
+  # Load test a user journey: each user runs login, list and detail in order
+  rawrequest load api.http -n login -n list -n detail --users 20 --duration 1m
+
+  # Save per-second rps, errors and latency to graph the ramp-up
+  rawrequest load api.http -n "search" --users 200 --ramp-up 1m --out run.csv
+
+  # Generate load from two services and get one combined summary
+  rawrequest load api.http -n "search" --users 400 --rps 4000 --workers 10.0.0.5:7345,10.0.0.6:7345
+
+  # Hold an arrival rate that doesn't slow down when the server does
+  rawrequest load api.http -n "search" --stages "30s:50rps, 2m:200rps, 30s:0rps"
+
+  # Fail the build when this branch is slower than a saved run
+  rawrequest load api.http -n "search" --compare api.responses/loadtests/search-20260101-120000.json
+
+  # Fail the build when the service misses its SLOs
+  rawrequest load api.http -n "search" --users 50 --thresholds "p95<300ms, p99<1s, errorRate<1%%"
+
   # Load test with adaptive control
   rawrequest load api.http -n "search" --users 100 --duration 2m --adaptive
 
@@ -375,6 +505,16 @@ Examples:
   # Start a mock server backed by SQLite for dynamic state/CRUD
   rawrequest mock api.http -p 3000 --db users.db
 
+  # Record mocks from a staging API, then edit them
+  rawrequest mock mocks.http --proxy https://staging.example.com --record
+
+  # Serve mocks over HTTPS and export the CA for your trust store
+  rawrequest mock api.http --tls -p 8443
+  rawrequest mock api.http --export-ca rawrequest-ca.pem
+
+  # Mock an OpenAPI spec, overriding some operations with @mock blocks
+  rawrequest mock overrides.http --openapi spec.yaml --validate
+
   # Start MCP server for AI assistants (Copilot, Claude, etc.)
   rawrequest mcp
   rawrequest mcp --env dev
@@ -3,7 +3,9 @@ package cli
 import (
 	"bufio"
 	"context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -11,9 +13,13 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
+
+	lt "rawrequest/internal/loadtest"
 )
 
 // RunLoadTest executes a load test via the service backend.
@@ -30,12 +36,26 @@ func RunLoadTest(opts *Options, version string) int {
 	}
 
 	parsed := ParseHttpFile(string(content))
-	requests := parsed.FindRequestsByName(opts.RequestNames[:1])
-	if len(requests) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: no request found with name '%s'\n", opts.RequestNames[0])
+	requests, err := findLoadTestRequests(parsed, opts.RequestNames)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
 	}
 
+	// Check the baseline now rather than after a long run.
+	if opts.LoadCompare != "" {
+		if _, err := readLoadBaseline(opts.LoadCompare); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+	if opts.LoadTolerance < 0 || opts.LoadTolerance > 1 {
+		fmt.Fprintf(os.Stderr, "Error: --tolerance must be between 0.0 and 1.0, got %g\n", opts.LoadTolerance)
+		return 1
+	}
+
+	// The first request carries the load config; with several names the
+	// others follow it as steps of one user journey.
 	req := requests[0]
 
 	// Build runner for variable resolution
@@ -55,17 +75,35 @@ func RunLoadTest(opts *Options, version string) int {
 	}
 
 	// Resolve URL and headers
-	resolvedURL := runner.resolveVariables(req.URL)
-	headersMap := make(map[string]string)
-	for k, v := range req.Headers {
-		headersMap[k] = runner.resolveVariables(v)
-	}
-	headersJSON, _ := json.Marshal(headersMap)
-	resolvedBody := runner.resolveVariables(req.Body)
+	resolvedURL, headersJSON, resolvedBody := resolveLoadTestRequest(runner, req)
 
 	// Build load config from the request file first, then apply any CLI overrides.
 	loadConfig := buildLoadConfig(req, opts)
-	loadConfigJSON, _ := json.Marshal(loadConfig)
+
+	payload := map[string]interface{}{
+		"method":      req.Method,
+		"url":         resolvedURL,
+		"headersJson": headersJSON,
+		"body":        resolvedBody,
+	}
+	if req.PostScript != "" {
+		payload["postScript"] = req.PostScript
+	}
+	if len(req.Assertions) > 0 {
+		payload["assertions"] = req.Assertions
+	}
+	if len(requests) > 1 {
+		payload["steps"] = buildLoadTestSteps(runner, requests)
+	}
+
+	started := fmt.Sprintf("%s %s", req.Method, resolvedURL)
+	if len(requests) > 1 {
+		started = "scenario " + strings.Join(loadTestRequestNames(requests), " → ")
+	}
+
+	if len(opts.LoadWorkers) > 0 {
+		return runDistributedLoadTest(opts, payload, loadConfig, started)
+	}
 
 	// Ensure service is running
 	serviceURL := fmt.Sprintf("http://%s", opts.ServiceAddr)
@@ -96,42 +134,20 @@ func RunLoadTest(opts *Options, version string) int {
 	time.Sleep(100 * time.Millisecond)
 
 	// Start load test
-	payload := map[string]interface{}{
-		"requestId":      requestID,
-		"method":         req.Method,
-		"url":            resolvedURL,
-		"headersJson":    string(headersJSON),
-		"body":           resolvedBody,
-		"loadConfigJson": string(loadConfigJSON),
-	}
-	payloadJSON, _ := json.Marshal(payload)
-
-	resp, err := http.Post(serviceURL+"/v1/start-load-test", "application/json", strings.NewReader(string(payloadJSON)))
-	if err != nil {
+	if err := startLoadTestOn(serviceURL, requestID, payload, loadConfig); err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting load test: %s\n", err)
 		return 1
 	}
-	resp.Body.Close()
-	if resp.StatusCode != http.StatusNoContent {
-		fmt.Fprintf(os.Stderr, "Error starting load test: HTTP %d\n", resp.StatusCode)
-		return 1
-	}
 
 	if opts.Output == OutputFull {
-		fmt.Fprintf(os.Stderr, "Load test started: %s %s\n", req.Method, resolvedURL)
-		fmt.Fprintf(os.Stderr, "Users: %s | Duration: %s | Ctrl+C to cancel\n\n", summarizeLoadUsers(loadConfig), summarizeLoadDuration(loadConfig))
+		fmt.Fprintf(os.Stderr, "Load test started: %s\n", started)
+		fmt.Fprintf(os.Stderr, "%s | Ctrl+C to cancel\n\n", summarizeLoad(loadConfig))
 	}
 
 	// Wait for completion
 	select {
 	case result := <-resultCh:
-		if result != nil {
-			printLoadTestSummary(result, opts.Output)
-			if result.Results.Aborted {
-				return 1
-			}
-		}
-		return 0
+		return finishLoadTest(result, opts, loadConfig)
 	case err := <-errCh:
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
@@ -141,21 +157,162 @@ func RunLoadTest(opts *Options, version string) int {
 	}
 }
 
+// startLoadTestOn posts one load test to a service. payload holds the
+// request; the load config is sent separately so distributed runs can give
+// each service its own share.
+func startLoadTestOn(serviceURL, requestID string, payload, loadConfig map[string]interface{}) error {
+	loadConfigJSON, _ := json.Marshal(loadConfig)
+	body := make(map[string]interface{}, len(payload)+2)
+	for k, v := range payload {
+		body[k] = v
+	}
+	body["requestId"] = requestID
+	body["loadConfigJson"] = string(loadConfigJSON)
+	payloadJSON, _ := json.Marshal(body)
+
+	resp, err := http.Post(serviceURL+"/v1/start-load-test", "application/json", strings.NewReader(string(payloadJSON)))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// finishLoadTest writes the requested outputs for a finished run and
+// returns the exit code.
+func finishLoadTest(result *loadTestDonePayload, opts *Options, loadConfig map[string]interface{}) int {
+	if result == nil {
+		return 0
+	}
+	if opts.LoadOut != "" {
+		if err := writeLoadTimelineFile(opts.LoadOut, result.Results.Timeline); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %s\n", opts.LoadOut, err)
+			return 1
+		}
+	}
+	if opts.File != "" && !opts.LoadNoSave {
+		path, err := saveLoadTestRun(opts, loadConfig, result.Results, time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not save the run: %s\n", err)
+		} else if opts.Output == OutputFull {
+			fmt.Fprintf(os.Stderr, "\nSaved run to %s\n", path)
+		}
+	}
+	if opts.LoadCompare != "" {
+		baseline, err := readLoadBaseline(opts.LoadCompare)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		result.Results.Comparison = compareLoadResults(opts.LoadCompare, *baseline, result.Results, opts.LoadTolerance)
+	}
+	printLoadTestSummary(result, opts.Output)
+	if result.Results.Aborted || !thresholdsPassed(result.Results.Thresholds) {
+		return 1
+	}
+	if c := result.Results.Comparison; c != nil && c.Regressed {
+		return 1
+	}
+	return 0
+}
+
+func thresholdsPassed(results []thresholdResult) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// findLoadTestRequests looks up every --name in the order given, so the
+// command line decides the order of scenario steps.
+func findLoadTestRequests(parsed *ParsedHttpFile, names []string) ([]Request, error) {
+	requests := make([]Request, 0, len(names))
+	for _, name := range names {
+		found := parsed.FindRequestsByName([]string{name})
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no request found with name '%s'", name)
+		}
+		requests = append(requests, found[0])
+	}
+	return requests, nil
+}
+
+// resolveLoadTestRequest resolves file, environment and secret variables.
+// Variables the runner does not know stay as {{placeholders}} for the
+// service to fill per virtual user from earlier scenario steps.
+func resolveLoadTestRequest(runner *Runner, req Request) (string, string, string) {
+	headersMap := make(map[string]string)
+	for k, v := range req.Headers {
+		headersMap[k] = runner.resolveVariables(v)
+	}
+	headersJSON, _ := json.Marshal(headersMap)
+	return runner.resolveVariables(req.URL), string(headersJSON), runner.resolveVariables(req.Body)
+}
+
+func buildLoadTestSteps(runner *Runner, requests []Request) []map[string]interface{} {
+	names := loadTestRequestNames(requests)
+	steps := make([]map[string]interface{}, 0, len(requests))
+	for i, req := range requests {
+		url, headersJSON, body := resolveLoadTestRequest(runner, req)
+		step := map[string]interface{}{
+			"name":        names[i],
+			"method":      req.Method,
+			"url":         url,
+			"headersJson": headersJSON,
+			"body":        body,
+		}
+		if req.PostScript != "" {
+			step["postScript"] = req.PostScript
+		}
+		if len(req.Assertions) > 0 {
+			step["assertions"] = req.Assertions
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+func loadTestRequestNames(requests []Request) []string {
+	names := make([]string, len(requests))
+	for i, req := range requests {
+		names[i] = req.Name
+		if names[i] == "" {
+			names[i] = fmt.Sprintf("request%d", i+1)
+		}
+	}
+	return names
+}
+
 func buildLoadConfig(req Request, opts *Options) map[string]any {
 	cfg := cloneLoadConfig(req.LoadConfig)
 	if cfg == nil {
 		cfg = map[string]any{}
 	}
 
+	if opts.LoadRateSet {
+		cfg["rate"] = opts.LoadRate
+	}
+	if opts.LoadStagesSet {
+		cfg["stages"] = opts.LoadStages
+	}
+	// An arrival-rate test without users lets the service pick its cap on
+	// iterations in flight, and stages set their own duration.
+	arrival := hasAnyLoadKey(cfg, "rate", "stages")
+
 	if opts.LoadUsersSet {
 		cfg["concurrent"] = opts.LoadUsers
-	} else if !hasAnyLoadKey(cfg, "concurrent", "start", "startUsers", "max", "maxUsers") {
+	} else if !arrival && !hasAnyLoadKey(cfg, "concurrent", "start", "startUsers", "max", "maxUsers") {
 		cfg["concurrent"] = 10
 	}
 
 	if opts.LoadDurationSet {
 		cfg["duration"] = opts.LoadDuration
-	} else if !hasAnyLoadKey(cfg, "duration", "iterations") {
+	} else if !hasAnyLoadKey(cfg, "duration", "iterations", "stages") {
 		cfg["duration"] = "30s"
 	}
 
@@ -168,9 +325,28 @@ func buildLoadConfig(req Request, opts *Options) map[string]any {
 	if opts.LoadFailRateSet {
 		cfg["failureRateThreshold"] = opts.LoadFailRate
 	}
+	if opts.LoadThresholdsSet {
+		cfg["thresholds"] = opts.LoadThresholds
+	}
+	if opts.LoadThresholdsAbortSet {
+		cfg["thresholdsAbort"] = opts.LoadThresholdsAbort
+	}
 	if opts.LoadAdaptiveSet {
 		cfg["adaptive"] = opts.LoadAdaptive
 	}
+	if opts.LoadAssertSet {
+		cfg["assert"] = opts.LoadAssert
+	}
+	if opts.LoadAssertSampleSet {
+		cfg["assertSample"] = opts.LoadAssertSample
+	}
+	// The service may run in another directory, so data files are sent as
+	// absolute paths resolved against the .http file.
+	if data, ok := cfg["data"].(string); ok && data != "" && !filepath.IsAbs(data) && opts.File != "" {
+		if abs, err := filepath.Abs(filepath.Join(filepath.Dir(opts.File), data)); err == nil {
+			cfg["data"] = abs
+		}
+	}
 
 	return cfg
 }
@@ -184,6 +360,17 @@ func hasAnyLoadKey(cfg map[string]any, keys ...string) bool {
 	return false
 }
 
+func summarizeLoad(cfg map[string]any) string {
+	line := fmt.Sprintf("Users: %s | Duration: %s", summarizeLoadUsers(cfg), summarizeLoadDuration(cfg))
+	if stages, ok := cfg["stages"]; ok {
+		return fmt.Sprintf("Stages: %v | %s", stages, line)
+	}
+	if rate, ok := cfg["rate"]; ok {
+		return fmt.Sprintf("Rate: %v | %s", rate, line)
+	}
+	return line
+}
+
 func summarizeLoadUsers(cfg map[string]any) string {
 	switch value := cfg["concurrent"].(type) {
 	case int:
@@ -195,6 +382,9 @@ func summarizeLoadUsers(cfg map[string]any) string {
 	case string:
 		return value
 	default:
+		if hasAnyLoadKey(cfg, "rate", "stages") {
+			return "auto"
+		}
 		return "10"
 	}
 }
@@ -206,6 +396,9 @@ func summarizeLoadDuration(cfg map[string]any) string {
 	if iterations, ok := cfg["iterations"]; ok {
 		return fmt.Sprintf("%v iterations", iterations)
 	}
+	if _, ok := cfg["stages"]; ok {
+		return "stages"
+	}
 	return "30s"
 }
 
@@ -261,33 +454,96 @@ func cancelLoadTest(serviceURL, requestID string) {
 }
 
 type loadTestProgress struct {
-	RequestID   string `json:"requestId"`
-	Type        string `json:"type"`
-	StartedAt   int64  `json:"startedAt"`
-	ActiveUsers int64  `json:"activeUsers"`
-	MaxUsers    int64  `json:"maxUsers"`
-	TotalSent   int64  `json:"totalSent"`
-	Successful  int64  `json:"successful"`
-	Failed      int64  `json:"failed"`
-	Done        bool   `json:"done"`
-	Cancelled   bool   `json:"cancelled"`
-	Aborted     bool   `json:"aborted"`
-	AbortReason string `json:"abortReason"`
+	RequestID         string `json:"requestId"`
+	Type              string `json:"type"`
+	StartedAt         int64  `json:"startedAt"`
+	ActiveUsers       int64  `json:"activeUsers"`
+	MaxUsers          int64  `json:"maxUsers"`
+	TotalSent         int64  `json:"totalSent"`
+	Successful        int64  `json:"successful"`
+	Failed            int64  `json:"failed"`
+	AssertionFailures int64  `json:"assertionFailures"`
+	Done              bool   `json:"done"`
+	Cancelled         bool   `json:"cancelled"`
+	Aborted           bool   `json:"aborted"`
+	AbortReason       string `json:"abortReason"`
 }
 
 type loadTestResults struct {
-	TotalRequests       int64            `json:"totalRequests"`
-	SuccessfulRequests  int64            `json:"successfulRequests"`
-	FailedRequests      int64            `json:"failedRequests"`
-	FailureStatusCounts map[string]int64 `json:"failureStatusCounts"`
-	ResponseTimesMs     []int64          `json:"responseTimes"`
-	StartTimeMs         int64            `json:"startTime"`
-	EndTimeMs           int64            `json:"endTime"`
-	Cancelled           bool             `json:"cancelled,omitempty"`
-	Aborted             bool             `json:"aborted,omitempty"`
-	AbortReason         string           `json:"abortReason,omitempty"`
-	PlannedDurationMs   *int64           `json:"plannedDurationMs,omitempty"`
-	Adaptive            *adaptiveSummary `json:"adaptive,omitempty"`
+	TotalRequests       int64             `json:"totalRequests"`
+	SuccessfulRequests  int64             `json:"successfulRequests"`
+	FailedRequests      int64             `json:"failedRequests"`
+	FailureStatusCounts map[string]int64  `json:"failureStatusCounts"`
+	AssertionFailures   int64             `json:"assertionFailures,omitempty"`
+	AssertionCounts     map[string]int64  `json:"assertionFailureCounts,omitempty"`
+	ResponseTimesMs     []int64           `json:"responseTimes"`
+	Latency             *latencySummary   `json:"latency,omitempty"`
+	Timeline            []secondWindow    `json:"timeline,omitempty"`
+	StartTimeMs         int64             `json:"startTime"`
+	EndTimeMs           int64             `json:"endTime"`
+	Cancelled           bool              `json:"cancelled,omitempty"`
+	Aborted             bool              `json:"aborted,omitempty"`
+	AbortReason         string            `json:"abortReason,omitempty"`
+	PlannedDurationMs   *int64            `json:"plannedDurationMs,omitempty"`
+	Adaptive            *adaptiveSummary  `json:"adaptive,omitempty"`
+	Steps               []loadTestStep    `json:"steps,omitempty"`
+	CompletedJourneys   int64             `json:"completedJourneys,omitempty"`
+	DroppedIterations   int64             `json:"droppedIterations,omitempty"`
+	Thresholds          []thresholdResult `json:"thresholds,omitempty"`
+	// Comparison is filled in by --compare; the service never sends it.
+	Comparison *loadComparison `json:"comparison,omitempty"`
+}
+
+type loadTestStep struct {
+	Name                string           `json:"name"`
+	Method              string           `json:"method"`
+	URL                 string           `json:"url"`
+	Total               int64            `json:"total"`
+	Successful          int64            `json:"successful"`
+	Failed              int64            `json:"failed"`
+	FailureStatusCounts map[string]int64 `json:"failureStatusCounts,omitempty"`
+	AvgMs               int64            `json:"avg"`
+	MinMs               int64            `json:"min"`
+	P50Ms               int64            `json:"p50"`
+	P95Ms               int64            `json:"p95"`
+	P99Ms               int64            `json:"p99"`
+	MaxMs               int64            `json:"max"`
+	Histogram           json.RawMessage  `json:"histogram,omitempty"`
+}
+
+type thresholdResult struct {
+	Threshold string  `json:"threshold"`
+	Metric    string  `json:"metric"`
+	Limit     float64 `json:"limit"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+}
+
+type latencySummary struct {
+	Count     int64           `json:"count"`
+	AvgMs     int64           `json:"avg"`
+	MinMs     int64           `json:"min"`
+	P50Ms     int64           `json:"p50"`
+	P90Ms     int64           `json:"p90"`
+	P95Ms     int64           `json:"p95"`
+	P99Ms     int64           `json:"p99"`
+	MaxMs     int64           `json:"max"`
+	Histogram json.RawMessage `json:"histogram,omitempty"`
+}
+
+type secondWindow struct {
+	Second       int64            `json:"second"`
+	StartMs      int64            `json:"startTime"`
+	ActiveUsers  int64            `json:"activeUsers"`
+	Requests     int64            `json:"requests"`
+	Failed       int64            `json:"failed"`
+	ErrorRate    float64          `json:"errorRate"`
+	P50Ms        int64            `json:"p50"`
+	P95Ms        int64            `json:"p95"`
+	P99Ms        int64            `json:"p99"`
+	MaxMs        int64            `json:"max"`
+	StatusCounts map[string]int64 `json:"statusCounts,omitempty"`
+	Histogram    json.RawMessage  `json:"histogram,omitempty"`
 }
 
 type adaptiveSummary struct {
@@ -309,24 +565,80 @@ type sseEvent struct {
 }
 
 func streamLoadTestEvents(ctx context.Context, serviceURL, requestID string, output OutputFormat, resultCh chan<- *loadTestDonePayload, errCh chan<- error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", serviceURL+"/v1/events", nil)
+	progress := newLoadProgressPrinter(output)
+	done, err := followLoadTest(ctx, serviceURL, requestID, nil, progress.print)
+	progress.finish()
 	if err != nil {
-		errCh <- err
+		if ctx.Err() == nil {
+			errCh <- err
+		}
 		return
 	}
+	resultCh <- done
+}
+
+// loadProgressPrinter rewrites one progress line on stderr in full output.
+type loadProgressPrinter struct {
+	enabled  bool
+	lastLine string
+}
+
+func newLoadProgressPrinter(output OutputFormat) *loadProgressPrinter {
+	return &loadProgressPrinter{enabled: output == OutputFull}
+}
+
+func (p *loadProgressPrinter) print(progress loadTestProgress) {
+	if !p.enabled {
+		return
+	}
+	elapsed := time.Since(time.UnixMilli(progress.StartedAt))
+	progressLine := fmt.Sprintf("\rUsers: %d/%d | Sent: %d | OK: %d | Failed: %d | Elapsed: %s",
+		progress.ActiveUsers, progress.MaxUsers,
+		progress.TotalSent, progress.Successful, progress.Failed,
+		formatDuration(elapsed))
+	if progress.AssertionFailures > 0 {
+		progressLine += fmt.Sprintf(" | Assert failed: %d", progress.AssertionFailures)
+	}
+	if progress.Aborted {
+		progressLine += " [ABORTED]"
+	}
+	// Clear previous line and write new one
+	clearLen := len(p.lastLine)
+	if len(progressLine) < clearLen {
+		progressLine += strings.Repeat(" ", clearLen-len(progressLine))
+	}
+	fmt.Fprint(os.Stderr, progressLine)
+	p.lastLine = progressLine
+}
+
+func (p *loadProgressPrinter) finish() {
+	if p.enabled && p.lastLine != "" {
+		fmt.Fprintln(os.Stderr)
+		p.lastLine = ""
+	}
+}
+
+// followLoadTest reads a service's event stream until requestID finishes,
+// passing each of its progress events to onProgress. connected, when not
+// nil, is closed once the stream is open.
+func followLoadTest(ctx context.Context, serviceURL, requestID string, connected chan<- struct{}, onProgress func(loadTestProgress)) (*loadTestDonePayload, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", serviceURL+"/v1/events", nil)
+	if err != nil {
+		return nil, err
+	}
 
 	client := &http.Client{Timeout: 0} // No timeout for SSE
 	resp, err := client.Do(req)
 	if err != nil {
-		errCh <- fmt.Errorf("failed to connect to event stream: %w", err)
-		return
+		return nil, fmt.Errorf("failed to connect to event stream: %w", err)
 	}
 	defer resp.Body.Close()
+	if connected != nil {
+		close(connected)
+	}
 
 	scanner := bufio.NewScanner(resp.Body)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-	var lastProgressLine string
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
 
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -349,43 +661,19 @@ func streamLoadTestEvents(ctx context.Context, serviceURL, requestID string, out
 			if progress.RequestID != requestID {
 				continue
 			}
-			if output == OutputFull {
-				elapsed := time.Since(time.UnixMilli(progress.StartedAt))
-				progressLine := fmt.Sprintf("\rUsers: %d/%d | Sent: %d | OK: %d | Failed: %d | Elapsed: %s",
-					progress.ActiveUsers, progress.MaxUsers,
-					progress.TotalSent, progress.Successful, progress.Failed,
-					formatDuration(elapsed))
-				if progress.Aborted {
-					progressLine += " [ABORTED]"
-				}
-				// Clear previous line and write new one
-				clearLen := len(lastProgressLine)
-				if len(progressLine) < clearLen {
-					progressLine += strings.Repeat(" ", clearLen-len(progressLine))
-				}
-				fmt.Fprint(os.Stderr, progressLine)
-				lastProgressLine = progressLine
-			}
+			onProgress(progress)
 
 		case "loadtest:done":
-			if output == OutputFull && lastProgressLine != "" {
-				fmt.Fprintln(os.Stderr)
-			}
 			var done loadTestDonePayload
 			if err := json.Unmarshal(evt.Payload, &done); err != nil {
-				errCh <- fmt.Errorf("failed to parse load test results: %w", err)
-				return
+				return nil, fmt.Errorf("failed to parse load test results: %w", err)
 			}
 			if done.RequestID != requestID {
 				continue
 			}
-			resultCh <- &done
-			return
+			return &done, nil
 
 		case "loadtest:error":
-			if output == OutputFull && lastProgressLine != "" {
-				fmt.Fprintln(os.Stderr)
-			}
 			var errPayload struct {
 				RequestID string `json:"requestId"`
 				Message   string `json:"message"`
@@ -396,14 +684,17 @@ func streamLoadTestEvents(ctx context.Context, serviceURL, requestID string, out
 			if errPayload.RequestID != requestID {
 				continue
 			}
-			errCh <- fmt.Errorf("load test error: %s", errPayload.Message)
-			return
+			return nil, fmt.Errorf("load test error: %s", errPayload.Message)
 		}
 	}
 
-	if err := scanner.Err(); err != nil && ctx.Err() == nil {
-		errCh <- fmt.Errorf("event stream error: %w", err)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("event stream error: %w", err)
 	}
+	return nil, errors.New("event stream closed before the load test finished")
 }
 
 func printLoadTestSummary(done *loadTestDonePayload, output OutputFormat) {
@@ -415,6 +706,11 @@ func printLoadTestSummary(done *loadTestDonePayload, output OutputFormat) {
 		return
 	}
 
+	if output == OutputCSV {
+		_ = writeLoadTimelineCSV(os.Stdout, r.Timeline)
+		return
+	}
+
 	if output == OutputQuiet {
 		return
 	}
@@ -442,6 +738,9 @@ func printLoadTestSummary(done *loadTestDonePayload, output OutputFormat) {
 		fmt.Printf("    Successful:  %d (%.1f%%)\n", r.SuccessfulRequests, successRate)
 		fmt.Printf("    Failed:      %d (%.1f%%)\n", r.FailedRequests, 100-successRate)
 	}
+	if r.DroppedIterations > 0 {
+		fmt.Printf("    Dropped:     %d (every user busy at arrival)\n", r.DroppedIterations)
+	}
 
 	// RPS
 	if duration.Seconds() > 0 {
@@ -451,7 +750,18 @@ func printLoadTestSummary(done *loadTestDonePayload, output OutputFormat) {
 	fmt.Println()
 
 	// Response times
-	if len(r.ResponseTimesMs) > 0 {
+	if l := r.Latency; l != nil {
+		fmt.Println("  Response Times:")
+		fmt.Printf("    Min:         %dms\n", l.MinMs)
+		fmt.Printf("    Avg:         %dms\n", l.AvgMs)
+		fmt.Printf("    P50:         %dms\n", l.P50Ms)
+		fmt.Printf("    P90:         %dms\n", l.P90Ms)
+		fmt.Printf("    P95:         %dms\n", l.P95Ms)
+		fmt.Printf("    P99:         %dms\n", l.P99Ms)
+		fmt.Printf("    Max:         %dms\n", l.MaxMs)
+		fmt.Println()
+	} else if len(r.ResponseTimesMs) > 0 {
+		// Services from before latency histograms only send raw samples.
 		sorted := make([]int64, len(r.ResponseTimesMs))
 		copy(sorted, r.ResponseTimesMs)
 		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
@@ -476,6 +786,29 @@ func printLoadTestSummary(done *loadTestDonePayload, output OutputFormat) {
 		fmt.Println()
 	}
 
+	if r.AssertionFailures > 0 {
+		fmt.Println("  Assertion Failures:")
+		fmt.Printf("    Responses:   %d\n", r.AssertionFailures)
+		for label, count := range r.AssertionCounts {
+			fmt.Printf("    %s: %d\n", label, count)
+		}
+		fmt.Println()
+	}
+
+	// Scenario steps
+	if len(r.Steps) > 0 {
+		fmt.Println("  Steps:")
+		for _, step := range r.Steps {
+			fmt.Printf("    %s (%s %s)\n", step.Name, step.Method, step.URL)
+			fmt.Printf("      Total: %d | OK: %d | Failed: %d\n", step.Total, step.Successful, step.Failed)
+			if step.Total > 0 {
+				fmt.Printf("      Avg: %dms | P50: %dms | P95: %dms | P99: %dms | Max: %dms\n", step.AvgMs, step.P50Ms, step.P95Ms, step.P99Ms, step.MaxMs)
+			}
+		}
+		fmt.Printf("    Completed journeys: %d\n", r.CompletedJourneys)
+		fmt.Println()
+	}
+
 	// Adaptive summary
 	if r.Adaptive != nil && r.Adaptive.Enabled {
 		fmt.Println("  Adaptive Control:")
@@ -491,6 +824,23 @@ func printLoadTestSummary(done *loadTestDonePayload, output OutputFormat) {
 		}
 		fmt.Println()
 	}
+
+	if r.Comparison != nil {
+		printLoadComparison(r.Comparison)
+	}
+
+	// Thresholds decide the exit code, so they come last.
+	if len(r.Thresholds) > 0 {
+		fmt.Println("  Thresholds:")
+		for _, t := range r.Thresholds {
+			mark := "✓"
+			if !t.Passed {
+				mark = "✗"
+			}
+			fmt.Printf("    %s %-20s %s %s\n", mark, t.Threshold, t.Metric, lt.FormatThresholdValue(t.Metric, t.Actual))
+		}
+		fmt.Println()
+	}
 }
 
 func formatDuration(d time.Duration) string {
@@ -529,3 +879,74 @@ func percentile(sorted []int64, p float64) int64 {
 	}
 	return sorted[idx]
 }
+
+// writeLoadTimelineFile writes the per-second series as CSV when path ends
+// in .csv and as a JSON array otherwise.
+func writeLoadTimelineFile(path string, timeline []secondWindow) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = writeLoadTimelineCSV(f, timeline)
+	} else {
+		if timeline == nil {
+			timeline = []secondWindow{}
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(timeline)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeLoadTimelineCSV writes one row per second with a status_<code>
+// column for every status code seen during the run, so the file loads
+// straight into a spreadsheet or plotting tool.
+func writeLoadTimelineCSV(w io.Writer, timeline []secondWindow) error {
+	codeSet := map[string]bool{}
+	for _, window := range timeline {
+		for code := range window.StatusCounts {
+			codeSet[code] = true
+		}
+	}
+	codes := make([]string, 0, len(codeSet))
+	for code := range codeSet {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	cw := csv.NewWriter(w)
+	header := []string{"second", "timestamp", "active_users", "requests", "failed", "error_rate", "p50_ms", "p95_ms", "p99_ms", "max_ms"}
+	for _, code := range codes {
+		header = append(header, "status_"+code)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, window := range timeline {
+		row := []string{
+			strconv.FormatInt(window.Second, 10),
+			time.UnixMilli(window.StartMs).UTC().Format("2006-01-02T15:04:05.000Z07:00"),
+			strconv.FormatInt(window.ActiveUsers, 10),
+			strconv.FormatInt(window.Requests, 10),
+			strconv.FormatInt(window.Failed, 10),
+			strconv.FormatFloat(window.ErrorRate, 'f', 4, 64),
+			strconv.FormatInt(window.P50Ms, 10),
+			strconv.FormatInt(window.P95Ms, 10),
+			strconv.FormatInt(window.P99Ms, 10),
+			strconv.FormatInt(window.MaxMs, 10),
+		}
+		for _, code := range codes {
+			row = append(row, strconv.FormatInt(window.StatusCounts[code], 10))
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+)
+
+// loadComparison holds the changes of a run against a baseline run.
+type loadComparison struct {
+	Baseline  string            `json:"baseline"`
+	Tolerance float64           `json:"tolerance"`
+	Metrics   []loadMetricDelta `json:"metrics"`
+	Regressed bool              `json:"regressed"`
+}
+
+// loadMetricDelta is one metric of a comparison. Change is relative, so 0.12
+// means 12% higher, and is left out when the baseline is zero.
+type loadMetricDelta struct {
+	Metric     string   `json:"metric"`
+	Baseline   float64  `json:"baseline"`
+	Current    float64  `json:"current"`
+	Change     *float64 `json:"change,omitempty"`
+	Regression bool     `json:"regression,omitempty"`
+}
+
+type loadMetric struct {
+	name  string
+	value float64
+	// lowerIsWorse is set for throughput, where a drop is the regression.
+	lowerIsWorse bool
+}
+
+// loadResultMetrics lists the numbers a comparison looks at.
+func loadResultMetrics(r loadTestResults) []loadMetric {
+	var p50, p95, p99, avgMs int64
+	if l := r.Latency; l != nil {
+		p50, p95, p99, avgMs = l.P50Ms, l.P95Ms, l.P99Ms, l.AvgMs
+	} else if len(r.ResponseTimesMs) > 0 {
+		sorted := append([]int64(nil), r.ResponseTimesMs...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		p50, p95, p99, avgMs = percentile(sorted, 50), percentile(sorted, 95), percentile(sorted, 99), avg(sorted)
+	}
+	var rps, errorRate float64
+	if seconds := float64(r.EndTimeMs-r.StartTimeMs) / 1000; seconds > 0 {
+		rps = float64(r.TotalRequests) / seconds
+	}
+	if r.TotalRequests > 0 {
+		errorRate = float64(r.FailedRequests) / float64(r.TotalRequests)
+	}
+	return []loadMetric{
+		{name: "p50", value: float64(p50)},
+		{name: "p95", value: float64(p95)},
+		{name: "p99", value: float64(p99)},
+		{name: "avg", value: float64(avgMs)},
+		{name: "rps", value: rps, lowerIsWorse: true},
+		{name: "errorRate", value: errorRate},
+	}
+}
+
+// compareLoadResults flags a regression when latency or the error rate
+// grows, or throughput drops, by more than tolerance relative to the
+// baseline. Errors where the baseline had none always count.
+func compareLoadResults(baselinePath string, baseline, current loadTestResults, tolerance float64) *loadComparison {
+	comparison := &loadComparison{Baseline: baselinePath, Tolerance: tolerance}
+	before := loadResultMetrics(baseline)
+	for i, m := range loadResultMetrics(current) {
+		delta := loadMetricDelta{Metric: m.name, Baseline: before[i].value, Current: m.value}
+		if delta.Baseline > 0 {
+			change := (delta.Current - delta.Baseline) / delta.Baseline
+			delta.Change = &change
+			if m.lowerIsWorse {
+				delta.Regression = change < -tolerance
+			} else {
+				delta.Regression = change > tolerance
+			}
+		} else {
+			delta.Regression = !m.lowerIsWorse && delta.Current > 0
+		}
+		comparison.Regressed = comparison.Regressed || delta.Regression
+		comparison.Metrics = append(comparison.Metrics, delta)
+	}
+	return comparison
+}
+
+func printLoadComparison(c *loadComparison) {
+	fmt.Printf("  Compared with %s (tolerance %.0f%%):\n", c.Baseline, c.Tolerance*100)
+	for _, d := range c.Metrics {
+		change := "     new"
+		if d.Change != nil {
+			change = fmt.Sprintf("%+7.1f%%", *d.Change*100)
+		} else if d.Current == 0 {
+			change = "       ="
+		}
+		flag := ""
+		if d.Regression {
+			flag = "  ✗ regression"
+		}
+		fmt.Printf("    %-10s %9s → %-9s %s%s\n", d.Metric+":", formatLoadMetric(d.Metric, d.Baseline), formatLoadMetric(d.Metric, d.Current), change, flag)
+	}
+	fmt.Println()
+}
+
+func formatLoadMetric(metric string, v float64) string {
+	switch metric {
+	case "rps":
+		return fmt.Sprintf("%.1f/s", v)
+	case "errorRate":
+		return fmt.Sprintf("%.2f%%", v*100)
+	default:
+		return fmt.Sprintf("%.0fms", v)
+	}
+}
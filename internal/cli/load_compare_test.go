@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareLoadResults_FlagsChangesBeyondTolerance(t *testing.T) {
+	baseline := loadTestResults{
+		TotalRequests: 1000,
+		StartTimeMs:   0,
+		EndTimeMs:     10000,
+		Latency:       &latencySummary{P50Ms: 100, P95Ms: 200, P99Ms: 400, AvgMs: 110},
+	}
+	current := loadTestResults{
+		TotalRequests:  850,
+		FailedRequests: 2,
+		StartTimeMs:    0,
+		EndTimeMs:      10000,
+		Latency:        &latencySummary{P50Ms: 105, P95Ms: 260, P99Ms: 390, AvgMs: 115},
+	}
+
+	c := compareLoadResults("base.json", baseline, current, 0.1)
+	if !c.Regressed {
+		t.Fatal("expected a regression")
+	}
+	got := map[string]loadMetricDelta{}
+	for _, d := range c.Metrics {
+		got[d.Metric] = d
+	}
+	for metric, want := range map[string]bool{"p50": false, "p95": true, "p99": false, "avg": false, "rps": true, "errorRate": true} {
+		if got[metric].Regression != want {
+			t.Errorf("%s regression = %v, want %v (%+v)", metric, got[metric].Regression, want, got[metric])
+		}
+	}
+	if d := got["p95"]; d.Change == nil || *d.Change != 0.3 {
+		t.Errorf("p95 change = %+v, want +30%%", d)
+	}
+	if d := got["errorRate"]; d.Change != nil || d.Current != 2.0/850 {
+		t.Errorf("errorRate = %+v, want no relative change from a zero baseline", d)
+	}
+
+	if c := compareLoadResults("base.json", baseline, baseline, 0.1); c.Regressed {
+		t.Fatalf("identical runs regressed: %+v", c.Metrics)
+	}
+}
+
+func TestFinishLoadTest_FailsOnRegressionAgainstBaseline(t *testing.T) {
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "baseline.json")
+	os.WriteFile(baseline, []byte(`{"results": {"totalRequests": 100, "startTime": 0, "endTime": 1000, "latency": {"p50": 10, "p95": 20, "p99": 30, "avg": 12}}}`), 0o644)
+
+	opts := &Options{Output: OutputQuiet, File: filepath.Join(dir, "api.http"), RequestNames: []string{"search"}, LoadCompare: baseline, LoadTolerance: 0.1}
+	result := &loadTestDonePayload{Results: loadTestResults{TotalRequests: 100, StartTimeMs: 0, EndTimeMs: 1000, Latency: &latencySummary{P50Ms: 10, P95Ms: 40, P99Ms: 30, AvgMs: 12}}}
+	if code := finishLoadTest(result, opts, map[string]interface{}{}); code != 1 {
+		t.Fatalf("exit code = %d, want 1 for a slower p95", code)
+	}
+	if result.Results.Comparison == nil || result.Results.Comparison.Baseline != baseline {
+		t.Fatalf("comparison = %+v", result.Results.Comparison)
+	}
+	if runs, _ := os.ReadDir(filepath.Join(dir, "api.responses", "loadtests")); len(runs) != 1 {
+		t.Fatalf("saved runs = %d, want 1", len(runs))
+	}
+
+	result.Results.Latency.P95Ms = 21
+	opts.LoadNoSave = true
+	if code := finishLoadTest(result, opts, nil); code != 0 {
+		t.Fatalf("exit code = %d, want 0 within tolerance", code)
+	}
+}
+
+func TestRunLoadTest_RejectsToleranceOutOfRange(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "api.http")
+	os.WriteFile(file, []byte("@name search\n@load users=1\nGET http://127.0.0.1:1/search\n"), 0o644)
+
+	for _, tolerance := range []float64{-0.1, 10} {
+		opts := &Options{Output: OutputQuiet, File: file, RequestNames: []string{"search"}, LoadTolerance: tolerance}
+		if code := RunLoadTest(opts, "test"); code != 1 {
+			t.Fatalf("tolerance %g: exit code = %d, want 1", tolerance, code)
+		}
+	}
+}
@@ -0,0 +1,560 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	lt "rawrequest/internal/loadtest"
+)
+
+// splitLoadKeys are the load settings divided between workers; everything
+// else, such as duration and ramp-up, applies to each worker unchanged.
+// Arrival rates and stages are split too, as fractions where needed.
+var splitLoadKeys = []string{"concurrent", "users", "concurrency", "start", "startUsers", "max", "maxUsers", "spawnRate", "requestsPerSecond", "iterations"}
+
+// distributedStartDelay gives every worker time to receive its start call
+// before the shared start time.
+const distributedStartDelay = time.Second
+
+// maxMergedSample matches the service's cap on raw response time samples.
+const maxMergedSample = 10000
+
+// runDistributedLoadTest splits a load test across the --workers services,
+// starts them together and prints one combined summary.
+func runDistributedLoadTest(opts *Options, payload, loadConfig map[string]interface{}, started string) int {
+	// Remote workers cannot read the coordinator's data file, and a local one
+	// reading all of it would repeat rows (breaking mode=unique), so every
+	// worker gets its own share of the rows inline.
+	var rows []map[string]string
+	if path, ok := loadConfig["data"].(string); ok && strings.TrimSpace(path) != "" {
+		var err error
+		if rows, err = lt.ReadDataRows(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		if len(rows) < len(opts.LoadWorkers) {
+			fmt.Fprintf(os.Stderr, "Error: load test data has %d rows, fewer than the %d workers\n", len(rows), len(opts.LoadWorkers))
+			return 1
+		}
+	}
+
+	workers := make([]string, len(opts.LoadWorkers))
+	configs := make([]map[string]interface{}, len(opts.LoadWorkers))
+	for i, addr := range opts.LoadWorkers {
+		workers[i] = workerURL(addr)
+		cfg, err := splitLoadConfig(loadConfig, len(workers), i)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		if rows != nil {
+			delete(cfg, "data")
+			cfg["dataRows"] = shareDataRows(rows, len(workers), i)
+		}
+		configs[i] = cfg
+	}
+	for _, worker := range workers {
+		if err := checkWorker(worker); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+	}
+
+	requestID := fmt.Sprintf("cli-load-%d", time.Now().UnixNano())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cancelAll := func() {
+		for _, worker := range workers {
+			cancelLoadTest(worker, requestID)
+		}
+	}
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	defer signal.Stop(sigCh)
+	go func() {
+		select {
+		case <-sigCh:
+			cancelAll()
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	type workerResult struct {
+		index int
+		done  *loadTestDonePayload
+		err   error
+	}
+	results := make(chan workerResult, len(workers))
+	progress := newLoadProgressPrinter(opts.Output)
+	var progressMu sync.Mutex
+	latest := make([]loadTestProgress, len(workers))
+	connected := make([]chan struct{}, len(workers))
+	for i, worker := range workers {
+		connected[i] = make(chan struct{})
+		go func(i int, worker string) {
+			done, err := followLoadTest(ctx, worker, requestID, connected[i], func(p loadTestProgress) {
+				progressMu.Lock()
+				defer progressMu.Unlock()
+				latest[i] = p
+				progress.print(mergeLoadProgress(latest))
+			})
+			results <- workerResult{index: i, done: done, err: err}
+		}(i, worker)
+	}
+	for i, ch := range connected {
+		select {
+		case <-ch:
+		case r := <-results:
+			fmt.Fprintf(os.Stderr, "Error: worker %s: %s\n", opts.LoadWorkers[r.index], r.err)
+			return 1
+		case <-time.After(5 * time.Second):
+			fmt.Fprintf(os.Stderr, "Error: worker %s: event stream did not open\n", opts.LoadWorkers[i])
+			return 1
+		}
+	}
+
+	startAt := time.Now().Add(distributedStartDelay).UnixMilli()
+	startErrs := make([]error, len(workers))
+	var startWG sync.WaitGroup
+	for i, worker := range workers {
+		configs[i]["startAt"] = startAt
+		startWG.Add(1)
+		go func(i int, worker string) {
+			defer startWG.Done()
+			startErrs[i] = startLoadTestOn(worker, requestID, payload, configs[i])
+		}(i, worker)
+	}
+	startWG.Wait()
+	for i, err := range startErrs {
+		if err != nil {
+			cancelAll()
+			fmt.Fprintf(os.Stderr, "Error starting load test on %s: %s\n", opts.LoadWorkers[i], err)
+			return 1
+		}
+	}
+
+	if opts.Output == OutputFull {
+		fmt.Fprintf(os.Stderr, "Load test started: %s\n", started)
+		fmt.Fprintf(os.Stderr, "Workers: %s\n", strings.Join(opts.LoadWorkers, ", "))
+		fmt.Fprintf(os.Stderr, "%s | Ctrl+C to cancel\n\n", summarizeLoad(loadConfig))
+	}
+
+	merged := make([]loadTestResults, len(workers))
+	for range workers {
+		r := <-results
+		if r.err != nil {
+			cancelAll()
+			progressMu.Lock()
+			progress.finish()
+			progressMu.Unlock()
+			if ctx.Err() != nil {
+				fmt.Fprintln(os.Stderr, "\nLoad test cancelled.")
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: worker %s: %s\n", opts.LoadWorkers[r.index], r.err)
+			}
+			return 1
+		}
+		// One worker crossing the failure threshold stops the whole run.
+		if r.done.Results.Aborted {
+			cancelAll()
+		}
+		merged[r.index] = r.done.Results
+	}
+	progressMu.Lock()
+	progress.finish()
+	progressMu.Unlock()
+
+	combined := mergeLoadResults(opts.LoadWorkers, merged)
+	// Each worker only saw its share, so thresholds are judged again on the
+	// combined numbers.
+	if raw, ok := loadConfig["thresholds"].(string); ok {
+		thresholds, err := lt.ParseThresholds(raw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			return 1
+		}
+		combined.Thresholds = evaluateLoadThresholds(thresholds, combined)
+	}
+	return finishLoadTest(&loadTestDonePayload{RequestID: requestID, Results: combined}, opts, loadConfig)
+}
+
+func evaluateLoadThresholds(thresholds []lt.Threshold, r loadTestResults) []thresholdResult {
+	metrics := lt.ThresholdMetrics{Requests: r.TotalRequests, Failed: r.FailedRequests, DurationMs: r.EndTimeMs - r.StartTimeMs}
+	if l := r.Latency; l != nil {
+		metrics.Latency = &lt.LatencySummary{Count: l.Count, AvgMs: l.AvgMs, MinMs: l.MinMs, P50Ms: l.P50Ms, P90Ms: l.P90Ms, P95Ms: l.P95Ms, P99Ms: l.P99Ms, MaxMs: l.MaxMs}
+	}
+	var results []thresholdResult
+	for _, t := range lt.EvaluateThresholds(thresholds, metrics) {
+		results = append(results, thresholdResult(t))
+	}
+	return results
+}
+
+func workerURL(addr string) string {
+	addr = strings.TrimRight(strings.TrimSpace(addr), "/")
+	if strings.Contains(addr, "://") {
+		return addr
+	}
+	return "http://" + addr
+}
+
+// checkWorker makes sure a worker's service answers. Workers on this
+// machine are started on demand like the default service; remote ones must
+// already run `rawrequest service`.
+func checkWorker(worker string) error {
+	if u, err := url.Parse(worker); err == nil {
+		host := u.Hostname()
+		if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+			return ensureServiceRunning(worker)
+		}
+	}
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(worker + "/v1/health")
+	if err != nil {
+		return fmt.Errorf("worker %s is not reachable: %w", worker, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("worker %s is not healthy: HTTP %d", worker, resp.StatusCode)
+	}
+	return nil
+}
+
+// splitLoadConfig returns the share of cfg for worker index of n. Split
+// settings are divided with the remainder going to the first workers, and
+// must be at least n so no worker gets zero, which would mean a default.
+func splitLoadConfig(cfg map[string]interface{}, n, index int) (map[string]interface{}, error) {
+	out := make(map[string]interface{}, len(cfg)+1)
+	for k, v := range cfg {
+		out[k] = v
+	}
+	for _, key := range splitLoadKeys {
+		raw, ok := cfg[key]
+		if !ok {
+			continue
+		}
+		total, ok := loadConfigInt(raw)
+		if !ok {
+			return nil, fmt.Errorf("%s=%v cannot be split across workers", key, raw)
+		}
+		if total < n {
+			return nil, fmt.Errorf("%s=%d cannot be split across %d workers", key, total, n)
+		}
+		share := total / n
+		if index < total%n {
+			share++
+		}
+		out[key] = share
+	}
+	if raw, ok := cfg["rate"]; ok {
+		rate, err := lt.ParseRate(fmt.Sprint(raw))
+		if err != nil {
+			return nil, err
+		}
+		out["rate"] = strconv.FormatFloat(rate/float64(n), 'f', -1, 64) + "rps"
+	}
+	if raw, ok := cfg["stages"].(string); ok {
+		stages, err := lt.ParseStages(raw)
+		if err != nil {
+			return nil, err
+		}
+		for i := range stages {
+			stages[i].Target /= float64(n)
+		}
+		out["stages"] = lt.FormatStages(stages)
+	}
+	return out, nil
+}
+
+// shareDataRows returns worker index's contiguous block of rows, split like
+// the other settings with the remainder going to the first workers.
+func shareDataRows(rows []map[string]string, n, index int) []map[string]string {
+	size := len(rows) / n
+	start := index*size + min(index, len(rows)%n)
+	if index < len(rows)%n {
+		size++
+	}
+	return rows[start : start+size]
+}
+
+func loadConfigInt(v interface{}) (int, bool) {
+	switch t := v.(type) {
+	case int:
+		return t, true
+	case int64:
+		return int(t), true
+	case float64:
+		return int(t), t == float64(int(t))
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(t))
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func mergeLoadProgress(parts []loadTestProgress) loadTestProgress {
+	var merged loadTestProgress
+	for _, p := range parts {
+		if p.StartedAt == 0 {
+			continue
+		}
+		if merged.StartedAt == 0 || p.StartedAt < merged.StartedAt {
+			merged.StartedAt = p.StartedAt
+		}
+		merged.ActiveUsers += p.ActiveUsers
+		merged.MaxUsers += p.MaxUsers
+		merged.TotalSent += p.TotalSent
+		merged.Successful += p.Successful
+		merged.Failed += p.Failed
+		merged.AssertionFailures += p.AssertionFailures
+		merged.Aborted = merged.Aborted || p.Aborted
+	}
+	return merged
+}
+
+// mergeLoadResults combines the results of every worker. Totals, status
+// counts and the overall latency histogram merge exactly; per-second and
+// per-step percentiles take the highest value any worker saw.
+func mergeLoadResults(workers []string, parts []loadTestResults) loadTestResults {
+	var merged loadTestResults
+	merged.FailureStatusCounts = map[string]int64{}
+	histogram := lt.NewHistogram()
+	haveHistograms := true
+	for i, r := range parts {
+		merged.TotalRequests += r.TotalRequests
+		merged.SuccessfulRequests += r.SuccessfulRequests
+		merged.FailedRequests += r.FailedRequests
+		merged.AssertionFailures += r.AssertionFailures
+		merged.CompletedJourneys += r.CompletedJourneys
+		merged.DroppedIterations += r.DroppedIterations
+		addCounts(merged.FailureStatusCounts, r.FailureStatusCounts)
+		if len(r.AssertionCounts) > 0 {
+			if merged.AssertionCounts == nil {
+				merged.AssertionCounts = map[string]int64{}
+			}
+			addCounts(merged.AssertionCounts, r.AssertionCounts)
+		}
+		merged.ResponseTimesMs = append(merged.ResponseTimesMs, r.ResponseTimesMs...)
+		if i == 0 || r.StartTimeMs < merged.StartTimeMs {
+			merged.StartTimeMs = r.StartTimeMs
+		}
+		if r.EndTimeMs > merged.EndTimeMs {
+			merged.EndTimeMs = r.EndTimeMs
+		}
+		merged.Cancelled = merged.Cancelled || r.Cancelled
+		if r.Aborted && !merged.Aborted {
+			merged.Aborted = true
+			merged.AbortReason = fmt.Sprintf("%s: %s", workers[i], r.AbortReason)
+		}
+		if merged.PlannedDurationMs == nil {
+			merged.PlannedDurationMs = r.PlannedDurationMs
+		}
+		merged.Adaptive = mergeAdaptive(merged.Adaptive, r.Adaptive)
+		merged.Timeline = mergeTimeline(merged.Timeline, r.Timeline)
+		merged.Steps = mergeSteps(merged.Steps, r.Steps)
+
+		var snap lt.HistogramSnapshot
+		if r.Latency == nil || json.Unmarshal(r.Latency.Histogram, &snap) != nil {
+			haveHistograms = false
+			continue
+		}
+		histogram.Merge(lt.HistogramFromSnapshot(snap))
+	}
+	// An aborted worker cancels the others, which is not a user cancel.
+	if merged.Aborted {
+		merged.Cancelled = false
+	}
+	if len(merged.ResponseTimesMs) > maxMergedSample {
+		sample := make([]int64, 0, maxMergedSample)
+		stride := float64(len(merged.ResponseTimesMs)) / maxMergedSample
+		for i := 0; i < maxMergedSample; i++ {
+			sample = append(sample, merged.ResponseTimesMs[int(float64(i)*stride)])
+		}
+		merged.ResponseTimesMs = sample
+	}
+	if haveHistograms && histogram.Count() > 0 {
+		snapshot, _ := json.Marshal(histogram.Snapshot())
+		merged.Latency = &latencySummary{
+			Count:     histogram.Count(),
+			AvgMs:     histogram.Mean(),
+			MinMs:     histogram.Min(),
+			P50Ms:     histogram.Percentile(50),
+			P90Ms:     histogram.Percentile(90),
+			P95Ms:     histogram.Percentile(95),
+			P99Ms:     histogram.Percentile(99),
+			MaxMs:     histogram.Max(),
+			Histogram: snapshot,
+		}
+	}
+	return merged
+}
+
+func addCounts(dst, src map[string]int64) {
+	for k, v := range src {
+		dst[k] += v
+	}
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func mergeAdaptive(merged, next *adaptiveSummary) *adaptiveSummary {
+	if next == nil || !next.Enabled {
+		return merged
+	}
+	if merged == nil || !merged.Enabled {
+		copied := *next
+		return &copied
+	}
+	sum := func(a, b *int64) *int64 {
+		if a == nil || b == nil {
+			return nil
+		}
+		v := *a + *b
+		return &v
+	}
+	merged.PeakUsers = sum(merged.PeakUsers, next.PeakUsers)
+	merged.StableUsers = sum(merged.StableUsers, next.StableUsers)
+	if merged.Stabilized != nil && next.Stabilized != nil {
+		v := *merged.Stabilized && *next.Stabilized
+		merged.Stabilized = &v
+	}
+	return merged
+}
+
+// mergeTimeline lines windows up by second. Workers start together, so
+// their seconds cover the same wall-clock time.
+func mergeTimeline(merged, next []secondWindow) []secondWindow {
+	bySecond := make(map[int64]int, len(merged))
+	for i, w := range merged {
+		bySecond[w.Second] = i
+	}
+	for _, w := range next {
+		i, ok := bySecond[w.Second]
+		if !ok {
+			copied := w
+			copied.StatusCounts = nil
+			if len(w.StatusCounts) > 0 {
+				copied.StatusCounts = map[string]int64{}
+				addCounts(copied.StatusCounts, w.StatusCounts)
+			}
+			bySecond[w.Second] = len(merged)
+			merged = append(merged, copied)
+			continue
+		}
+		m := &merged[i]
+		if w.StartMs < m.StartMs {
+			m.StartMs = w.StartMs
+		}
+		if h, ok := mergeHistogramJSON(m.Histogram, m.Requests, w.Histogram, w.Requests); ok {
+			m.P50Ms, m.P95Ms, m.P99Ms, m.MaxMs = h.Percentile(50), h.Percentile(95), h.Percentile(99), h.Max()
+			m.Histogram, _ = json.Marshal(h.Snapshot())
+		} else {
+			m.P50Ms = maxInt64(m.P50Ms, w.P50Ms)
+			m.P95Ms = maxInt64(m.P95Ms, w.P95Ms)
+			m.P99Ms = maxInt64(m.P99Ms, w.P99Ms)
+			m.MaxMs = maxInt64(m.MaxMs, w.MaxMs)
+			m.Histogram = nil
+		}
+		m.ActiveUsers += w.ActiveUsers
+		m.Requests += w.Requests
+		m.Failed += w.Failed
+		if m.Requests > 0 {
+			m.ErrorRate = float64(m.Failed) / float64(m.Requests)
+		}
+		if len(w.StatusCounts) > 0 {
+			if m.StatusCounts == nil {
+				m.StatusCounts = map[string]int64{}
+			}
+			addCounts(m.StatusCounts, w.StatusCounts)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Second < merged[j].Second })
+	return merged
+}
+
+func mergeSteps(merged, next []loadTestStep) []loadTestStep {
+	if merged == nil {
+		for _, step := range next {
+			copied := step
+			copied.FailureStatusCounts = nil
+			if len(step.FailureStatusCounts) > 0 {
+				copied.FailureStatusCounts = map[string]int64{}
+				addCounts(copied.FailureStatusCounts, step.FailureStatusCounts)
+			}
+			merged = append(merged, copied)
+		}
+		return merged
+	}
+	for i := range merged {
+		if i >= len(next) {
+			break
+		}
+		m, s := &merged[i], next[i]
+		if total := m.Total + s.Total; total > 0 {
+			m.AvgMs = (m.AvgMs*m.Total + s.AvgMs*s.Total) / total
+		}
+		if s.Total > 0 && (m.Total == 0 || s.MinMs < m.MinMs) {
+			m.MinMs = s.MinMs
+		}
+		if h, ok := mergeHistogramJSON(m.Histogram, m.Total, s.Histogram, s.Total); ok {
+			m.P50Ms, m.P95Ms, m.P99Ms, m.MaxMs = h.Percentile(50), h.Percentile(95), h.Percentile(99), h.Max()
+			m.Histogram, _ = json.Marshal(h.Snapshot())
+		} else {
+			m.P50Ms = maxInt64(m.P50Ms, s.P50Ms)
+			m.P95Ms = maxInt64(m.P95Ms, s.P95Ms)
+			m.P99Ms = maxInt64(m.P99Ms, s.P99Ms)
+			m.MaxMs = maxInt64(m.MaxMs, s.MaxMs)
+			m.Histogram = nil
+		}
+		m.Total += s.Total
+		m.Successful += s.Successful
+		m.Failed += s.Failed
+		if len(s.FailureStatusCounts) > 0 {
+			if m.FailureStatusCounts == nil {
+				m.FailureStatusCounts = map[string]int64{}
+			}
+			addCounts(m.FailureStatusCounts, s.FailureStatusCounts)
+		}
+	}
+	return merged
+}
+
+// mergeHistogramJSON merges two serialized histograms holding aCount and
+// bCount samples into one, so percentiles come from the combined traffic.
+// An empty side needs no histogram; ok is false when a non-empty side has
+// none, as from an older worker, and the caller falls back to the larger
+// of the two values.
+func mergeHistogramJSON(a json.RawMessage, aCount int64, b json.RawMessage, bCount int64) (*lt.Histogram, bool) {
+	h := lt.NewHistogram()
+	for _, part := range []struct {
+		raw   json.RawMessage
+		count int64
+	}{{a, aCount}, {b, bCount}} {
+		if part.count == 0 {
+			continue
+		}
+		var snap lt.HistogramSnapshot
+		if len(part.raw) == 0 || json.Unmarshal(part.raw, &snap) != nil {
+			return nil, false
+		}
+		h.Merge(lt.HistogramFromSnapshot(snap))
+	}
+	return h, true
+}
@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	lt "rawrequest/internal/loadtest"
+)
+
+func TestSplitLoadConfig_DividesUsersRateAndIterations(t *testing.T) {
+	cfg := map[string]interface{}{"concurrent": 5, "requestsPerSecond": "100", "duration": "1m", "iterations": 7.0}
+	first, err := splitLoadConfig(cfg, 2, 0)
+	if err != nil {
+		t.Fatalf("splitLoadConfig: %v", err)
+	}
+	second, _ := splitLoadConfig(cfg, 2, 1)
+	if first["concurrent"] != 3 || second["concurrent"] != 2 || first["requestsPerSecond"] != 50 || first["iterations"] != 4 || second["iterations"] != 3 {
+		t.Fatalf("shares = %v / %v", first, second)
+	}
+	if first["duration"] != "1m" || cfg["concurrent"] != 5 {
+		t.Fatalf("duration must be shared and the input left alone: %v %v", first, cfg)
+	}
+	if _, err := splitLoadConfig(map[string]interface{}{"concurrent": 1}, 2, 0); err == nil {
+		t.Fatal("expected one user across two workers to be rejected")
+	}
+}
+
+func TestSplitLoadConfig_DividesArrivalRates(t *testing.T) {
+	cfg := map[string]interface{}{"rate": "25rps", "stages": "30s:50rps, 1m:0rps"}
+	share, err := splitLoadConfig(cfg, 2, 1)
+	if err != nil {
+		t.Fatalf("splitLoadConfig: %v", err)
+	}
+	if share["rate"] != "12.5rps" || share["stages"] != "30s:25rps, 1m0s:0rps" {
+		t.Fatalf("share = %v", share)
+	}
+	if _, err := splitLoadConfig(map[string]interface{}{"stages": "later"}, 2, 0); err == nil {
+		t.Fatal("expected invalid stages to be rejected")
+	}
+}
+
+func TestEvaluateLoadThresholds_UsesCombinedResults(t *testing.T) {
+	thresholds, _ := lt.ParseThresholds("p99<1s, rps>150")
+	results := evaluateLoadThresholds(thresholds, loadTestResults{
+		TotalRequests: 1000,
+		StartTimeMs:   0,
+		EndTimeMs:     5000,
+		Latency:       &latencySummary{P99Ms: 1200},
+	})
+	if len(results) != 2 || results[0].Passed || results[0].Actual != 1200 || !results[1].Passed || results[1].Actual != 200 {
+		t.Fatalf("results = %+v", results)
+	}
+}
+
+// fakeLoadService answers the service endpoints the coordinator uses and
+// finishes each load test with one response per user at latency ms.
+type fakeLoadService struct {
+	*httptest.Server
+	latency int64
+	mu      sync.Mutex
+	configs []map[string]interface{}
+	events  chan string
+}
+
+func newFakeLoadService(t *testing.T, latency int64) *fakeLoadService {
+	f := &fakeLoadService{latency: latency, events: make(chan string, 16)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/v1/cancel-request", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/v1/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case evt := <-f.events:
+				fmt.Fprintf(w, "data: %s\n\n", evt)
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+	mux.HandleFunc("/v1/start-load-test", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			RequestID      string `json:"requestId"`
+			LoadConfigJSON string `json:"loadConfigJson"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		var cfg map[string]interface{}
+		_ = json.Unmarshal([]byte(payload.LoadConfigJSON), &cfg)
+		f.mu.Lock()
+		f.configs = append(f.configs, cfg)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+		users := int64(cfg["concurrent"].(float64))
+		h := lt.NewHistogram()
+		for i := int64(0); i < users; i++ {
+			h.Record(f.latency)
+		}
+		snapshot, _ := json.Marshal(h.Snapshot())
+		done, _ := json.Marshal(map[string]interface{}{
+			"event": "loadtest:done",
+			"payload": loadTestDonePayload{RequestID: payload.RequestID, Results: loadTestResults{
+				TotalRequests:       users,
+				SuccessfulRequests:  users - 1,
+				FailedRequests:      1,
+				FailureStatusCounts: map[string]int64{"500": 1},
+				ResponseTimesMs:     []int64{f.latency},
+				Latency:             &latencySummary{Count: users, Histogram: snapshot},
+				Timeline:            []secondWindow{{Second: 0, ActiveUsers: users, Requests: users, Failed: 1, P99Ms: f.latency, StatusCounts: map[string]int64{"200": users - 1, "500": 1}}},
+				StartTimeMs:         1000,
+				EndTimeMs:           2000,
+			}},
+		})
+		f.events <- string(done)
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func TestRunDistributedLoadTest_SplitsStartsTogetherAndMerges(t *testing.T) {
+	fast := newFakeLoadService(t, 10)
+	slow := newFakeLoadService(t, 900)
+	out := filepath.Join(t.TempDir(), "timeline.json")
+	opts := &Options{
+		Output:      OutputQuiet,
+		LoadOut:     out,
+		LoadWorkers: []string{fast.Listener.Addr().String(), slow.URL},
+	}
+
+	code := runDistributedLoadTest(opts, map[string]interface{}{"method": "GET", "url": "http://api.test"}, map[string]interface{}{"concurrent": 3, "duration": "10s"}, "GET http://api.test")
+	if code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	if len(fast.configs) != 1 || len(slow.configs) != 1 {
+		t.Fatalf("configs = %v / %v", fast.configs, slow.configs)
+	}
+	if fast.configs[0]["concurrent"] != 2.0 || slow.configs[0]["concurrent"] != 1.0 {
+		t.Errorf("user shares = %v / %v, want 2 and 1", fast.configs[0]["concurrent"], slow.configs[0]["concurrent"])
+	}
+	startAt := fast.configs[0]["startAt"]
+	if startAt == nil || startAt != slow.configs[0]["startAt"] {
+		t.Errorf("startAt = %v / %v, want one shared start time", startAt, slow.configs[0]["startAt"])
+	}
+	if at, _ := startAt.(float64); time.UnixMilli(int64(at)).Before(time.Now().Add(-5 * time.Second)) {
+		t.Errorf("startAt %v is not a recent start time", startAt)
+	}
+
+	var timeline []secondWindow
+	data, _ := os.ReadFile(out)
+	if err := json.Unmarshal(data, &timeline); err != nil || len(timeline) != 1 {
+		t.Fatalf("timeline = %s (%v)", data, err)
+	}
+	if w := timeline[0]; w.Requests != 3 || w.Failed != 2 || w.ActiveUsers != 3 || w.P99Ms != 900 || w.StatusCounts["500"] != 2 {
+		t.Errorf("merged window = %+v", w)
+	}
+}
+
+func TestRunDistributedLoadTest_SplitsDataRowsBetweenWorkers(t *testing.T) {
+	first := newFakeLoadService(t, 10)
+	second := newFakeLoadService(t, 10)
+	data := filepath.Join(t.TempDir(), "users.csv")
+	if err := os.WriteFile(data, []byte("email\na@x.test\nb@x.test\nc@x.test\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts := &Options{Output: OutputQuiet, LoadWorkers: []string{first.URL, second.URL}}
+	loadConfig := map[string]interface{}{"concurrent": 2, "data": data, "dataMode": "unique"}
+
+	if code := runDistributedLoadTest(opts, map[string]interface{}{"method": "GET", "url": "http://api.test"}, loadConfig, "GET http://api.test"); code != 0 {
+		t.Fatalf("exit code = %d", code)
+	}
+
+	emails := func(cfg map[string]interface{}) []string {
+		if _, ok := cfg["data"]; ok {
+			t.Errorf("worker got the coordinator's data path: %v", cfg["data"])
+		}
+		var out []string
+		rows, _ := cfg["dataRows"].([]interface{})
+		for _, row := range rows {
+			out = append(out, row.(map[string]interface{})["email"].(string))
+		}
+		return out
+	}
+	if got := fmt.Sprint(emails(first.configs[0]), emails(second.configs[0])); got != "[a@x.test b@x.test] [c@x.test]" {
+		t.Errorf("row shares = %s, want disjoint blocks", got)
+	}
+	if loadConfig["data"] != data {
+		t.Errorf("the coordinator's config must keep its data path")
+	}
+
+	opts.LoadWorkers = append(opts.LoadWorkers, first.URL, second.URL)
+	if code := runDistributedLoadTest(opts, map[string]interface{}{"method": "GET", "url": "http://api.test"}, map[string]interface{}{"concurrent": 4, "data": data}, "GET http://api.test"); code == 0 {
+		t.Error("expected fewer rows than workers to be rejected")
+	}
+}
+
+func TestMergeLoadResults_MergesHistogramsExactly(t *testing.T) {
+	parts := make([]loadTestResults, 2)
+	for i, values := range [][]int64{{10, 20, 30}, {40, 50, 60, 70}} {
+		h := lt.NewHistogram()
+		for _, v := range values {
+			h.Record(v)
+		}
+		snapshot, _ := json.Marshal(h.Snapshot())
+		parts[i] = loadTestResults{
+			TotalRequests:       int64(len(values)),
+			SuccessfulRequests:  int64(len(values)),
+			FailureStatusCounts: map[string]int64{},
+			Latency:             &latencySummary{Histogram: snapshot},
+			StartTimeMs:         int64(100 - i),
+			EndTimeMs:           int64(200 + i),
+		}
+	}
+	parts[1].Aborted, parts[1].AbortReason = true, "failure rate 60%"
+	parts[0].Cancelled = true
+
+	merged := mergeLoadResults([]string{"a:1", "b:2"}, parts)
+	if merged.TotalRequests != 7 || merged.StartTimeMs != 99 || merged.EndTimeMs != 201 {
+		t.Fatalf("merged = %+v", merged)
+	}
+	if l := merged.Latency; l == nil || l.Count != 7 || l.P50Ms != 40 || l.MinMs != 10 || l.MaxMs != 70 || l.AvgMs != 40 {
+		t.Fatalf("latency = %+v", merged.Latency)
+	}
+	if !merged.Aborted || merged.Cancelled || merged.AbortReason != "b:2: failure rate 60%" {
+		t.Fatalf("abort = %v %v %q", merged.Aborted, merged.Cancelled, merged.AbortReason)
+	}
+}
+
+func TestMergeLoadResults_MergesWindowAndStepHistograms(t *testing.T) {
+	parts := make([]loadTestResults, 2)
+	for i, values := range [][]int64{{10, 20, 30}, {40, 50, 60, 70}} {
+		h := lt.NewHistogram()
+		for _, v := range values {
+			h.Record(v)
+		}
+		snapshot, _ := json.Marshal(h.Snapshot())
+		n := int64(len(values))
+		parts[i] = loadTestResults{
+			TotalRequests: n,
+			Timeline:      []secondWindow{{Second: 0, Requests: n, P50Ms: h.Percentile(50), P99Ms: h.Percentile(99), MaxMs: h.Max(), Histogram: snapshot}},
+			Steps:         []loadTestStep{{Name: "search", Total: n, P50Ms: h.Percentile(50), MaxMs: h.Max(), Histogram: snapshot}},
+		}
+	}
+
+	merged := mergeLoadResults([]string{"a:1", "b:2"}, parts)
+	if w := merged.Timeline[0]; w.Requests != 7 || w.P50Ms != 40 || w.P99Ms != 70 || w.MaxMs != 70 {
+		t.Fatalf("window = %+v, want p50 of the combined 7 samples", w)
+	}
+	if s := merged.Steps[0]; s.Total != 7 || s.P50Ms != 40 || s.MaxMs != 70 {
+		t.Fatalf("step = %+v, want p50 of the combined 7 samples", s)
+	}
+
+	// A worker without histograms falls back to the larger value.
+	parts[1].Timeline[0].Histogram = nil
+	merged = mergeLoadResults([]string{"a:1", "b:2"}, parts)
+	if w := merged.Timeline[0]; w.P50Ms != 50 || w.Histogram != nil {
+		t.Fatalf("fallback window = %+v", w)
+	}
+}
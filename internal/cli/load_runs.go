@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// loadTestRun is a finished load test as saved next to the .http file, and
+// what --compare reads back as a baseline.
+type loadTestRun struct {
+	Requests    []string               `json:"requests"`
+	File        string                 `json:"file"`
+	Environment string                 `json:"environment"`
+	GitCommit   string                 `json:"gitCommit,omitempty"`
+	GitDirty    bool                   `json:"gitDirty,omitempty"`
+	Workers     []string               `json:"workers,omitempty"`
+	SavedAt     string                 `json:"savedAt"`
+	Config      map[string]interface{} `json:"config"`
+	Results     loadTestResults        `json:"results"`
+}
+
+// loadRunsDir keeps load test runs in a subfolder of the file's
+// .responses folder, out of the way of the saved response history.
+func loadRunsDir(httpFile string) string {
+	base := filepath.Base(httpFile)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	return filepath.Join(filepath.Dir(httpFile), name+".responses", "loadtests")
+}
+
+// saveLoadTestRun writes the run to {file}.responses/loadtests/ and returns
+// its path.
+func saveLoadTestRun(opts *Options, loadConfig map[string]interface{}, results loadTestResults, now time.Time) (string, error) {
+	// The histogram and timeline describe the run; the raw sample is only
+	// for live charts and would make every file large.
+	results.ResponseTimesMs = nil
+
+	run := loadTestRun{
+		Requests:    opts.RequestNames,
+		File:        filepath.Base(opts.File),
+		Environment: opts.Environment,
+		Workers:     opts.LoadWorkers,
+		SavedAt:     now.UTC().Format(time.RFC3339),
+		Config:      loadConfig,
+		Results:     results,
+	}
+	run.GitCommit, run.GitDirty = gitRevision(filepath.Dir(opts.File))
+
+	dir := loadRunsDir(opts.File)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	replacer := strings.NewReplacer("/", "_", "\\", "_", ":", "_", " ", "-")
+	name := fmt.Sprintf("%s-%s.json", replacer.Replace(strings.Join(opts.RequestNames, "+")), now.Format("20060102-150405"))
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// gitRevision reports the commit checked out in dir and whether the tree
+// has uncommitted changes, or nothing when dir is not in a git repository.
+func gitRevision(dir string) (string, bool) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", false
+	}
+	status, err := exec.Command("git", "-C", dir, "status", "--porcelain").Output()
+	return strings.TrimSpace(string(out)), err == nil && len(bytes.TrimSpace(status)) > 0
+}
+
+// readLoadBaseline reads a saved run, or the results printed by -o json.
+func readLoadBaseline(path string) (*loadTestResults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read baseline: %w", err)
+	}
+	var run struct {
+		Results *loadTestResults `json:"results"`
+	}
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("baseline %s: %w", path, err)
+	}
+	results := run.Results
+	if results == nil {
+		results = &loadTestResults{}
+		if err := json.Unmarshal(data, results); err != nil {
+			return nil, fmt.Errorf("baseline %s: %w", path, err)
+		}
+	}
+	if results.TotalRequests == 0 {
+		return nil, fmt.Errorf("baseline %s has no load test results", path)
+	}
+	return results, nil
+}
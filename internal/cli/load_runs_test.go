@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadTestRun_WritesNextToHTTPFile(t *testing.T) {
+	dir := t.TempDir()
+	opts := &Options{File: filepath.Join(dir, "api.http"), Environment: "staging", RequestNames: []string{"login", "search"}}
+	results := loadTestResults{
+		TotalRequests:   10,
+		ResponseTimesMs: []int64{1, 2, 3},
+		Latency:         &latencySummary{Count: 10, P95Ms: 40, Histogram: json.RawMessage(`{"count":10}`)},
+		Timeline:        []secondWindow{{Second: 0, Requests: 10}},
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+
+	path, err := saveLoadTestRun(opts, map[string]interface{}{"concurrent": 5}, results, now)
+	if err != nil {
+		t.Fatalf("saveLoadTestRun: %v", err)
+	}
+	if want := filepath.Join(dir, "api.responses", "loadtests", "login+search-20260102-030405.json"); path != want {
+		t.Fatalf("path = %s, want %s", path, want)
+	}
+
+	data, _ := os.ReadFile(path)
+	var run loadTestRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		t.Fatalf("saved run: %v", err)
+	}
+	if run.Environment != "staging" || run.File != "api.http" || run.Config["concurrent"] != 5.0 || len(run.Requests) != 2 {
+		t.Errorf("run = %+v", run)
+	}
+	var histogram struct{ Count int64 }
+	json.Unmarshal(run.Results.Latency.Histogram, &histogram)
+	if len(run.Results.ResponseTimesMs) != 0 || len(run.Results.Timeline) != 1 || histogram.Count != 10 {
+		t.Errorf("results = %+v, want the histogram and timeline without the raw sample", run.Results)
+	}
+
+	baseline, err := readLoadBaseline(path)
+	if err != nil || baseline.TotalRequests != 10 || baseline.Latency.P95Ms != 40 {
+		t.Fatalf("readLoadBaseline = %+v, %v", baseline, err)
+	}
+}
+
+func TestReadLoadBaseline_AcceptsJSONOutputAndRejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "results.json")
+	os.WriteFile(plain, []byte(`{"totalRequests": 42, "failedRequests": 1}`), 0o644)
+	if r, err := readLoadBaseline(plain); err != nil || r.TotalRequests != 42 || r.FailedRequests != 1 {
+		t.Fatalf("readLoadBaseline(-o json output) = %+v, %v", r, err)
+	}
+
+	other := filepath.Join(dir, "other.json")
+	os.WriteFile(other, []byte(`{"status": 200}`), 0o644)
+	if _, err := readLoadBaseline(other); err == nil {
+		t.Fatal("expected a file without results to be rejected")
+	}
+	if _, err := readLoadBaseline(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("expected a missing file to be rejected")
+	}
+}
@@ -1,6 +1,12 @@
 package cli
 
-import "testing"
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
 
 func TestBuildLoadConfig_UsesRequestConfigAndCliOverrides(t *testing.T) {
 	req := Request{
@@ -43,3 +49,138 @@ func TestBuildLoadConfig_AppliesDefaultsWhenFileHasNoConfig(t *testing.T) {
 		t.Fatalf("expected default duration=30s, got %#v", got)
 	}
 }
+
+func TestBuildLoadConfig_AssertFlags(t *testing.T) {
+	req := Request{LoadConfig: map[string]any{"assert": true, "assertSample": "0.5"}}
+	cfg := buildLoadConfig(req, &Options{LoadAssertSample: 0.2, LoadAssertSampleSet: true})
+	if cfg["assert"] != true {
+		t.Fatalf("expected assert from file config, got %v", cfg["assert"])
+	}
+	if cfg["assertSample"] != 0.2 {
+		t.Fatalf("expected CLI sample override, got %v", cfg["assertSample"])
+	}
+}
+
+func TestBuildLoadTestSteps_KeepsNameOrderAndSessionPlaceholders(t *testing.T) {
+	parsed := ParseHttpFile(`@base = http://api.test
+
+###
+@name detail
+GET {{base}}/items/{{request1.response.body.id}}
+Authorization: Bearer {{token}}
+
+###
+@name login
+POST {{base}}/login
+`)
+	requests, err := findLoadTestRequests(parsed, []string{"login", "detail"})
+	if err != nil {
+		t.Fatalf("findLoadTestRequests: %v", err)
+	}
+	runner := NewRunner(&Options{Variables: map[string]string{}}, "test")
+	for k, v := range parsed.Variables {
+		runner.SetVariable(k, v)
+	}
+
+	steps := buildLoadTestSteps(runner, requests)
+	if len(steps) != 2 || steps[0]["name"] != "login" || steps[1]["name"] != "detail" {
+		t.Fatalf("steps = %v, want login then detail", steps)
+	}
+	if got := steps[1]["url"]; got != "http://api.test/items/{{request1.response.body.id}}" {
+		t.Fatalf("detail url = %v", got)
+	}
+	if got := steps[1]["headersJson"]; got != `{"Authorization":"Bearer {{token}}"}` {
+		t.Fatalf("detail headers = %v", got)
+	}
+
+	if _, err := findLoadTestRequests(parsed, []string{"login", "nope"}); err == nil {
+		t.Fatal("expected an unknown step name to be rejected")
+	}
+}
+
+func TestBuildLoadConfig_ResolvesDataFileAgainstHTTPFile(t *testing.T) {
+	parsed := ParseHttpFile("@name signup\n@load users=5 data=./users.csv mode=unique\nPOST http://api.test/signup\n\n{\"email\": \"{{email}}\"}")
+	if len(parsed.Requests) != 1 {
+		t.Fatalf("requests = %d", len(parsed.Requests))
+	}
+	cfg := buildLoadConfig(parsed.Requests[0], &Options{File: "/work/api/api.http"})
+	if got := cfg["data"]; got != "/work/api/users.csv" {
+		t.Fatalf("data = %#v, want the path next to the .http file", got)
+	}
+	if got := cfg["dataMode"]; got != "unique" {
+		t.Fatalf("dataMode = %#v", got)
+	}
+}
+
+func TestBuildLoadConfig_ArrivalStagesSkipUserAndDurationDefaults(t *testing.T) {
+	parsed := ParseHttpFile("@name search\n@load stages=\"30s:50rps, 2m:200rps, 30s:0rps\"\nGET http://api.test/search")
+	if len(parsed.Requests) != 1 {
+		t.Fatalf("requests = %d", len(parsed.Requests))
+	}
+	cfg := buildLoadConfig(parsed.Requests[0], &Options{})
+	if got := cfg["stages"]; got != "30s:50rps, 2m:200rps, 30s:0rps" {
+		t.Fatalf("stages = %#v", got)
+	}
+	if _, ok := cfg["concurrent"]; ok {
+		t.Errorf("concurrent = %#v, want the service's cap on iterations in flight", cfg["concurrent"])
+	}
+	if _, ok := cfg["duration"]; ok {
+		t.Errorf("duration = %#v, want the stages to set it", cfg["duration"])
+	}
+
+	cfg = buildLoadConfig(Request{}, &Options{LoadRate: "20rps", LoadRateSet: true, LoadUsers: 50, LoadUsersSet: true})
+	if cfg["rate"] != "20rps" || cfg["concurrent"] != 50 || cfg["duration"] != "30s" {
+		t.Fatalf("cfg = %#v", cfg)
+	}
+}
+
+func TestFinishLoadTest_FailsWhenAThresholdFails(t *testing.T) {
+	parsed := ParseHttpFile("@name search\n@load users=5 thresholds=\"p95<300ms, errorRate<1%\" abortOnFail=true\nGET http://api.test/search")
+	cfg := buildLoadConfig(parsed.Requests[0], &Options{})
+	if cfg["thresholds"] != "p95<300ms, errorRate<1%" || cfg["thresholdsAbort"] != true {
+		t.Fatalf("cfg = %#v", cfg)
+	}
+
+	opts := &Options{Output: OutputQuiet}
+	passed := &loadTestDonePayload{Results: loadTestResults{Thresholds: []thresholdResult{{Threshold: "p95<300ms", Passed: true}}}}
+	if code := finishLoadTest(passed, opts, nil); code != 0 {
+		t.Fatalf("exit code = %d with every threshold passed", code)
+	}
+	passed.Results.Thresholds = append(passed.Results.Thresholds, thresholdResult{Threshold: "errorRate<1%"})
+	if code := finishLoadTest(passed, opts, nil); code != 1 {
+		t.Fatalf("exit code = %d with a failed threshold, want 1", code)
+	}
+}
+
+func TestWriteLoadTimelineFile_CSVAndJSON(t *testing.T) {
+	timeline := []secondWindow{
+		{Second: 0, StartMs: 1700000000000, ActiveUsers: 5, Requests: 10, P50Ms: 12, P95Ms: 30, P99Ms: 31, MaxMs: 40, StatusCounts: map[string]int64{"200": 10}},
+		{Second: 1, StartMs: 1700000001000, ActiveUsers: 10, Requests: 20, Failed: 2, ErrorRate: 0.1, StatusCounts: map[string]int64{"200": 18, "503": 2}},
+	}
+	dir := t.TempDir()
+
+	csvPath := filepath.Join(dir, "run.csv")
+	if err := writeLoadTimelineFile(csvPath, timeline); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	data, _ := os.ReadFile(csvPath)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	want := []string{
+		"second,timestamp,active_users,requests,failed,error_rate,p50_ms,p95_ms,p99_ms,max_ms,status_200,status_503",
+		"0,2023-11-14T22:13:20.000Z,5,10,0,0.0000,12,30,31,40,10,0",
+		"1,2023-11-14T22:13:21.000Z,10,20,2,0.1000,0,0,0,0,18,2",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("csv =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+
+	jsonPath := filepath.Join(dir, "run.json")
+	if err := writeLoadTimelineFile(jsonPath, timeline); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	var decoded []secondWindow
+	data, _ = os.ReadFile(jsonPath)
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded) != 2 || decoded[1].StatusCounts["503"] != 2 {
+		t.Fatalf("json = %s (%v)", data, err)
+	}
+}
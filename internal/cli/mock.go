@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"rawrequest/internal/mockserver"
+)
+
+// RunMockServer starts the mock server in CLI mode and serves until the
+// process is interrupted.
+func RunMockServer(opts *Options) int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	return runMockServer(ctx, opts)
+}
+
+func runMockServer(ctx context.Context, opts *Options) int {
+	if opts.MockExportCA != "" {
+		if err := mockserver.ExportCA(mockserver.DefaultCertDir(), opts.MockExportCA); err != nil {
+			fmt.Fprintf(os.Stderr, "Error exporting mock CA: %v\n", err)
+			return 1
+		}
+		fmt.Printf("Mock CA certificate written to %s\n", opts.MockExportCA)
+		return 0
+	}
+
+	var spec *mockserver.OpenAPISpec
+	if opts.MockOpenAPI != "" {
+		loaded, err := mockserver.LoadOpenAPI(opts.MockOpenAPI)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading OpenAPI spec: %v\n", err)
+			return 1
+		}
+		spec = loaded
+	} else if opts.MockValidate {
+		fmt.Fprintf(os.Stderr, "Error: --validate needs --openapi <spec>\n")
+		return 1
+	}
+
+	recordTo := opts.MockRecordTo
+	if recordTo == "" && opts.MockRecord {
+		if opts.File == "" {
+			fmt.Fprintf(os.Stderr, "Error: --record needs an .http file or --record-to <file>\n")
+			return 1
+		}
+		recordTo = opts.File
+	}
+	if recordTo != "" && opts.MockProxy == "" {
+		fmt.Fprintf(os.Stderr, "Error: --record needs --proxy <url>\n")
+		return 1
+	}
+	proxying := opts.MockProxy != ""
+	// A proxy can start from an empty file and record its mocks, and a spec
+	// provides routes of its own.
+	fileOptional := proxying || spec != nil
+
+	var content []byte
+	if opts.File != "" {
+		var err error
+		content, err = os.ReadFile(opts.File)
+		if err != nil && !(fileOptional && errors.Is(err, os.ErrNotExist)) {
+			fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
+			return 1
+		}
+	}
+
+	parsed := ParseHttpFile(string(content))
+	if len(parsed.Requests) == 0 && !fileOptional {
+		fmt.Fprintf(os.Stderr, "Error: no requests found in %s\n", opts.File)
+		return 1
+	}
+
+	// Hand-written @mock blocks come first so they override generated routes.
+	routesFor := func(parsed *ParsedHttpFile) []mockserver.MockRequest {
+		mockReqs := MockRequests(parsed)
+		if spec != nil {
+			mockReqs = append(mockReqs, spec.MockRequests()...)
+		}
+		return mockReqs
+	}
+	mockReqs := routesFor(parsed)
+	if len(mockReqs) == 0 && !proxying {
+		if spec != nil {
+			fmt.Fprintf(os.Stderr, "Error: no operations found in %s\n", opts.MockOpenAPI)
+		} else {
+			fmt.Fprintf(os.Stderr, "Error: no mock endpoint definitions found in %s (use the @mock annotation to mark a request block as a mock endpoint)\n", opts.File)
+		}
+		return 1
+	}
+
+	source := opts.File
+	if source == "" {
+		source = opts.MockOpenAPI
+	}
+	// Command-line variables win over the file's, as they do for `run`.
+	variables := make(map[string]string, len(parsed.Variables)+len(opts.Variables))
+	for k, v := range parsed.Variables {
+		variables[k] = v
+	}
+	for k, v := range opts.Variables {
+		variables[k] = v
+	}
+	env, ok := parsed.Environments[opts.Environment]
+	if !ok && opts.Environment != "" && opts.Environment != "default" {
+		fmt.Fprintf(os.Stderr, "Warning: environment '%s' not found, using default\n", opts.Environment)
+	}
+	srv := mockserver.New(mockserver.Config{
+		File:             source,
+		Host:             opts.MockBind,
+		Port:             opts.MockPort,
+		DBPath:           opts.MockDB,
+		Requests:         mockReqs,
+		Proxy:            opts.MockProxy,
+		RecordFile:       recordTo,
+		TLS:              opts.MockTLS,
+		CertFile:         opts.MockCert,
+		KeyFile:          opts.MockKey,
+		OpenAPI:          spec,
+		ValidateRequests: opts.MockValidate,
+		Variables:        variables,
+		Env:              env,
+	})
+	if err := srv.Start(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error starting mock server: %v\n", err)
+		return 1
+	}
+
+	if !opts.MockNoWatch && opts.File != "" {
+		srv.WatchFile(opts.File, mockserver.DefaultWatchInterval, func(content []byte) []mockserver.MockRequest {
+			return routesFor(ParseHttpFile(string(content)))
+		})
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	if err := srv.Wait(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: mock server stopped: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// MockRequests converts the @mock and @mockinit blocks of a parsed file into
+// mock server requests.
+func MockRequests(parsed *ParsedHttpFile) []mockserver.MockRequest {
+	var mockReqs []mockserver.MockRequest
+	for _, req := range parsed.Requests {
+		if req.IsMock {
+			mockReqs = append(mockReqs, mockserver.MockRequest{
+				Name:       req.Name,
+				Method:     req.Method,
+				URL:        req.URL,
+				Headers:    req.Headers,
+				Body:       req.Body,
+				PreScript:  req.PreScript,
+				PostScript: req.PostScript,
+				Directives: req.MockDirectives,
+			})
+		}
+	}
+	return mockReqs
+}
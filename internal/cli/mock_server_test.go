@@ -1,10 +1,12 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -57,16 +59,15 @@ GET /users
 		MockDB:   dbPath,
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
 	exitCodeCh := make(chan int, 1)
 	go func() {
-		exitCodeCh <- RunMockServer(opts)
+		exitCodeCh <- runMockServer(ctx, opts)
 	}()
 
 	waitForServer(t, port, "/users")
 	t.Cleanup(func() {
-		if err := mockserver.StopMockServer(); err != nil {
-			t.Fatalf("StopMockServer() cleanup error = %v", err)
-		}
+		cancel()
 		select {
 		case exitCode := <-exitCodeCh:
 			if exitCode != 0 {
@@ -85,6 +86,145 @@ GET /users
 	}
 }
 
+func TestRunMockServerRecordsProxiedExchangesAsMocks(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`[{"id":7,"source":"upstream"}]`))
+	}))
+	defer upstream.Close()
+
+	filePath := filepath.Join(t.TempDir(), "recorded.http")
+	port := getFreePort(t)
+	opts := &Options{
+		Command:     CommandMock,
+		File:        filePath,
+		MockPort:    port,
+		MockProxy:   upstream.URL,
+		MockRecord:  true,
+		MockNoWatch: true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exitCodeCh := make(chan int, 1)
+	go func() {
+		exitCodeCh <- runMockServer(ctx, opts)
+	}()
+	waitForServer(t, port, "/")
+	defer func() {
+		cancel()
+		if exitCode := <-exitCodeCh; exitCode != 0 {
+			t.Fatalf("runMockServer() exit code = %d, want 0", exitCode)
+		}
+	}()
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/users?active=true", port))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("proxied status = %d, want 201", resp.StatusCode)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	mocks := MockRequests(ParseHttpFile(string(content)))
+	var users *mockserver.MockRequest
+	for i := range mocks {
+		if mocks[i].URL == "/users" {
+			users = &mocks[i]
+		}
+	}
+	if users == nil {
+		t.Fatalf("recorded file has no GET /users mock:\n%s", content)
+	}
+	if users.Method != "GET" || users.Body != `[{"id":7,"source":"upstream"}]` || users.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("recorded mock = %+v", users)
+	}
+	wantDirectives := []string{"match query active=true", "status 201"}
+	if strings.Join(users.Directives, "|") != strings.Join(wantDirectives, "|") {
+		t.Fatalf("recorded directives = %v, want %v", users.Directives, wantDirectives)
+	}
+}
+
+func TestRunMockServerServesOpenAPISpecWithOverrides(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "pets.yaml")
+	spec := `
+openapi: 3.0.0
+paths:
+  /pets:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              example: [{"id": 1, "name": "Generated"}]
+  /pets/{id}:
+    get:
+      parameters:
+        - { name: id, in: path, required: true, schema: { type: integer } }
+      responses:
+        '200': { description: ok, content: { application/json: { example: {"id": 1} } } }
+`
+	overrides := `
+###
+@mock
+GET /pets/:id
+Content-Type: application/json
+
+{"id": 42, "name": "Handwritten"}
+`
+	filePath := filepath.Join(dir, "overrides.http")
+	if err := os.WriteFile(specPath, []byte(spec), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte(overrides), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	port := getFreePort(t)
+	opts := &Options{
+		Command:      CommandMock,
+		File:         filePath,
+		MockPort:     port,
+		MockOpenAPI:  specPath,
+		MockValidate: true,
+		MockNoWatch:  true,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	exitCodeCh := make(chan int, 1)
+	go func() {
+		exitCodeCh <- runMockServer(ctx, opts)
+	}()
+	waitForServer(t, port, "/")
+	defer func() {
+		cancel()
+		if exitCode := <-exitCodeCh; exitCode != 0 {
+			t.Fatalf("runMockServer() exit code = %d, want 0", exitCode)
+		}
+	}()
+
+	if pets := getJSON[[]map[string]interface{}](t, port, "/pets"); len(pets) != 1 || pets[0]["name"] != "Generated" {
+		t.Fatalf("generated /pets = %v", pets)
+	}
+	if pet := getJSON[map[string]interface{}](t, port, "/pets/3"); pet["name"] != "Handwritten" {
+		t.Fatalf("/pets/3 = %v, want the hand-written override", pet)
+	}
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/pets/abc", port))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("/pets/abc status = %d, want 400 from --validate", resp.StatusCode)
+	}
+}
+
 func waitForServer(t *testing.T, port int, path string) {
 	t.Helper()
 	client := &http.Client{Timeout: 200 * time.Millisecond}
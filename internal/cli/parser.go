@@ -26,8 +26,14 @@ type Request struct {
 	Group      string
 	Depends    string
 	Timeout    int
+	// MaxRuns overrides requestchain.MaxRequestRuns when > 0 (`@max-runs`).
+	MaxRuns    int
 	LoadConfig map[string]any
 	IsMock     bool
+	Assertions []string
+	// MockDirectives holds mock-only directives such as "match query a=1",
+	// without the leading "@".
+	MockDirectives []string
 }
 
 var (
@@ -52,8 +58,11 @@ func ParseHttpFile(content string) *ParsedHttpFile {
 	inHeaders := false
 	var pendingName, pendingGroup, pendingDepends string
 	pendingTimeout := 0
+	pendingMaxRuns := 0
 	var pendingLoadConfig map[string]any
 	pendingIsMock := false
+	var pendingAssertions []string
+	var pendingMockDirectives []string
 	inLoadBlock := false
 
 	// Script block tracking
@@ -90,6 +99,38 @@ func ParseHttpFile(content string) *ParsedHttpFile {
 		// they are applied to a new request.
 	}
 
+	startRequest := func(method, url string, isMock bool) {
+		if currentRequest != nil {
+			finalizeRequest()
+		}
+		currentRequest = &Request{
+			Name:           pendingName,
+			Method:         method,
+			URL:            url,
+			Headers:        make(map[string]string),
+			Group:          pendingGroup,
+			Depends:        pendingDepends,
+			Timeout:        pendingTimeout,
+			MaxRuns:        pendingMaxRuns,
+			LoadConfig:     cloneLoadConfig(pendingLoadConfig),
+			IsMock:         isMock,
+			Assertions:     pendingAssertions,
+			MockDirectives: pendingMockDirectives,
+		}
+		pendingName = ""
+		pendingGroup = ""
+		pendingDepends = ""
+		pendingTimeout = 0
+		pendingMaxRuns = 0
+		pendingLoadConfig = nil
+		pendingIsMock = false
+		pendingAssertions = nil
+		pendingMockDirectives = nil
+		inHeaders = true
+		inBody = false
+		requestBody.Reset()
+	}
+
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 
@@ -228,6 +269,14 @@ func ParseHttpFile(content string) *ParsedHttpFile {
 			continue
 		}
 
+		// @max-runs directive: how often chain.next() may run this request
+		if strings.HasPrefix(trimmed, "@max-runs ") {
+			if n, err := strconv.Atoi(strings.TrimSpace(trimmed[len("@max-runs"):])); err == nil && n > 0 {
+				pendingMaxRuns = n
+			}
+			continue
+		}
+
 		// @no-history - ignore for CLI
 		if trimmed == "@no-history" || strings.HasPrefix(trimmed, "@no-history ") {
 			continue
@@ -248,6 +297,32 @@ func ParseHttpFile(content string) *ParsedHttpFile {
 			continue
 		}
 
+		// @assert directive: attaches to the current request, or to the next one
+		// when it appears before the request line. Inside a body it is body text.
+		if strings.HasPrefix(trimmed, "@assert ") {
+			if inBody && currentRequest != nil {
+				requestBody.WriteString(line + "\n")
+				continue
+			}
+			expr := strings.TrimSpace(trimmed[len("@assert"):])
+			if currentRequest != nil {
+				currentRequest.Assertions = append(currentRequest.Assertions, expr)
+			} else {
+				pendingAssertions = append(pendingAssertions, expr)
+			}
+			continue
+		}
+
+		// Mock directives (@match, @status, @delay, ...) attach like @assert.
+		if directive, ok := mockDirective(trimmed); ok {
+			if currentRequest != nil {
+				currentRequest.MockDirectives = append(currentRequest.MockDirectives, directive)
+			} else {
+				pendingMockDirectives = append(pendingMockDirectives, directive)
+			}
+			continue
+		}
+
 		// @mockinit directive
 		if trimmed == "@mockinit" || strings.HasPrefix(trimmed, "@mockinit ") {
 			if currentRequest == nil {
@@ -264,13 +339,19 @@ func ParseHttpFile(content string) *ParsedHttpFile {
 			pendingGroup = ""
 			pendingDepends = ""
 			pendingTimeout = 0
+			pendingMaxRuns = 0
 			pendingLoadConfig = nil
 			pendingIsMock = false
 			continue
 		}
 
-		// @mock directive
+		// @mock directive; "@mock ws /path" and "@mock sse /path" declare a
+		// streaming endpoint on their own line
 		if trimmed == "@mock" || strings.HasPrefix(trimmed, "@mock ") {
+			if method, path, ok := streamingMock(trimmed); ok {
+				startRequest(method, path, true)
+				continue
+			}
 			pendingIsMock = true
 			continue
 		}
@@ -325,29 +406,7 @@ func ParseHttpFile(content string) *ParsedHttpFile {
 
 		// Method line: GET https://example.com
 		if match := methodRegex.FindStringSubmatch(trimmed); match != nil {
-			if currentRequest != nil {
-				finalizeRequest()
-			}
-			currentRequest = &Request{
-				Name:       pendingName,
-				Method:     match[1],
-				URL:        match[2],
-				Headers:    make(map[string]string),
-				Group:      pendingGroup,
-				Depends:    pendingDepends,
-				Timeout:    pendingTimeout,
-				LoadConfig: cloneLoadConfig(pendingLoadConfig),
-				IsMock:     pendingIsMock,
-			}
-			pendingName = ""
-			pendingGroup = ""
-			pendingDepends = ""
-			pendingTimeout = 0
-			pendingLoadConfig = nil
-			pendingIsMock = false
-			inHeaders = true
-			inBody = false
-			requestBody.Reset()
+			startRequest(match[1], match[2], pendingIsMock)
 			continue
 		}
 
@@ -371,6 +430,47 @@ func ParseHttpFile(content string) *ParsedHttpFile {
 	return result
 }
 
+// mockDirectiveNames lists the directives that configure a mock route.
+var mockDirectiveNames = []string{
+	"match", "priority", "status",
+	"delay", "fail-rate", "drop-connection", "chunked-throttle",
+	"scenario", "body-file",
+}
+
+// mockDirective reports whether line is a mock directive and returns it
+// without the leading "@". Assignments such as `@delay = 5` stay variables.
+func mockDirective(line string) (string, bool) {
+	if !strings.HasPrefix(line, "@") {
+		return "", false
+	}
+	name, rest, _ := strings.Cut(line[1:], " ")
+	if strings.Contains(name, "=") || strings.HasPrefix(strings.TrimSpace(rest), "=") {
+		return "", false
+	}
+	for _, known := range mockDirectiveNames {
+		if name == known {
+			return strings.TrimSpace(line[1:]), true
+		}
+	}
+	return "", false
+}
+
+// streamingMock parses "@mock ws /path" or "@mock sse /path" into the
+// mock route method ("WS" or "SSE") and path.
+func streamingMock(line string) (string, string, bool) {
+	fields := strings.Fields(strings.TrimPrefix(line, "@mock"))
+	if len(fields) != 2 {
+		return "", "", false
+	}
+	switch strings.ToLower(fields[0]) {
+	case "ws":
+		return "WS", fields[1], true
+	case "sse":
+		return "SSE", fields[1], true
+	}
+	return "", "", false
+}
+
 func isSeparatorLine(line string) bool {
 	trimmed := strings.TrimSpace(line)
 	return strings.HasPrefix(trimmed, "###")
@@ -465,6 +565,22 @@ func normalizeLoadKey(raw string) string {
 		return "adaptiveCooldown"
 	case "adaptivebackoffstep", "backoffstep", "backoffusers":
 		return "adaptiveBackoffStep"
+	case "assert", "assertions", "checks", "validate":
+		return "assert"
+	case "assertsample", "assertsamplerate", "samplerate", "sample":
+		return "assertSample"
+	case "rate", "arrivalrate", "iterationrate":
+		return "rate"
+	case "stages", "stage", "profile":
+		return "stages"
+	case "thresholds", "threshold", "slo", "slos":
+		return "thresholds"
+	case "thresholdsabort", "thresholdabort", "abortonfail":
+		return "thresholdsAbort"
+	case "data", "datafile", "feed", "feeder":
+		return "data"
+	case "mode", "datamode", "feedmode":
+		return "dataMode"
 	default:
 		return strings.TrimSpace(raw)
 	}
@@ -476,7 +592,7 @@ func parseLoadValue(key, raw string) any {
 		if n, err := strconv.Atoi(raw); err == nil {
 			return n
 		}
-	case "adaptive":
+	case "adaptive", "assert", "thresholdsAbort":
 		if b, err := strconv.ParseBool(strings.ToLower(raw)); err == nil {
 			return b
 		}
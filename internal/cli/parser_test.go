@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -105,3 +106,166 @@ GET https://httpbun.com/get`
 		t.Fatalf("expected requestsPerSecond=150, got %#v", got)
 	}
 }
+
+func TestParseHttpFile_Assertions(t *testing.T) {
+	content := `### create
+@name createUser
+@assert status == 201
+POST https://httpbun.com/post
+Content-Type: application/json
+@assert header Content-Type contains json
+@assert jsonpath $.id exists
+
+{"name": "ada"}
+
+### list
+@name listUsers
+GET https://httpbun.com/get`
+
+	parsed := ParseHttpFile(content)
+	if len(parsed.Requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(parsed.Requests))
+	}
+	got := parsed.Requests[0].Assertions
+	want := []string{"status == 201", "header Content-Type contains json", "jsonpath $.id exists"}
+	if len(got) != len(want) {
+		t.Fatalf("assertions=%v want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("assertion %d=%q want %q", i, got[i], want[i])
+		}
+	}
+	if parsed.Requests[0].Body != `{"name": "ada"}` {
+		t.Errorf("unexpected body: %q", parsed.Requests[0].Body)
+	}
+	if len(parsed.Requests[1].Assertions) != 0 {
+		t.Errorf("expected no assertions on second request, got %v", parsed.Requests[1].Assertions)
+	}
+	if _, ok := parsed.Variables["assert"]; ok {
+		t.Errorf("@assert must not be treated as a variable")
+	}
+}
+
+func TestParseHttpFile_MaxRuns(t *testing.T) {
+	content := `### poll
+@name poll
+@max-runs 500
+GET https://httpbun.com/get
+
+### next
+GET https://httpbun.com/get`
+
+	parsed := ParseHttpFile(content)
+	if len(parsed.Requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(parsed.Requests))
+	}
+	if got := parsed.Requests[0].MaxRuns; got != 500 {
+		t.Errorf("MaxRuns=%d want 500", got)
+	}
+	if got := parsed.Requests[1].MaxRuns; got != 0 {
+		t.Errorf("second request MaxRuns=%d want 0", got)
+	}
+	if _, ok := parsed.Variables["max"]; ok {
+		t.Errorf("@max-runs must not be treated as a variable")
+	}
+}
+
+func TestParseHttpFile_AssertInBodyIsBodyText(t *testing.T) {
+	content := `### note
+@assert status == 200
+POST https://httpbun.com/post
+Content-Type: text/plain
+
+first line
+@assert foo
+last line`
+
+	parsed := ParseHttpFile(content)
+	if len(parsed.Requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(parsed.Requests))
+	}
+	req := parsed.Requests[0]
+	if len(req.Assertions) != 1 || req.Assertions[0] != "status == 200" {
+		t.Errorf("assertions=%v want [status == 200]", req.Assertions)
+	}
+	if want := "first line\n@assert foo\nlast line"; req.Body != want {
+		t.Errorf("body=%q want %q", req.Body, want)
+	}
+}
+
+func TestParseHttpFile_MockDirectives(t *testing.T) {
+	content := `###
+@mock
+@match header X-Tenant = acme
+@priority 10
+@delay 100ms..300ms
+@drop-connection
+GET /orders
+
+[]
+
+###
+@delay = 5
+@mock
+GET /orders`
+
+	parsed := ParseHttpFile(content)
+	if len(parsed.Requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(parsed.Requests))
+	}
+	got := parsed.Requests[0].MockDirectives
+	want := []string{"match header X-Tenant = acme", "priority 10", "delay 100ms..300ms", "drop-connection"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("mock directives=%v want %v", got, want)
+	}
+	if len(parsed.Requests[1].MockDirectives) != 0 {
+		t.Errorf("expected no directives on second request, got %v", parsed.Requests[1].MockDirectives)
+	}
+	if _, ok := parsed.Variables["priority"]; ok {
+		t.Errorf("@priority must not be treated as a variable")
+	}
+	if parsed.Variables["delay"] != "5" {
+		t.Errorf("@delay = 5 should stay a variable, got %v", parsed.Variables)
+	}
+}
+
+func TestParseHttpFile_StreamingMocks(t *testing.T) {
+	content := `@name notifications
+@mock ws /notifications/:user
+< {
+  function onConnect(socket) { socket.send("hi"); }
+}
+
+###
+@mock sse /events
+X-Stream: yes
+
+event: ready
+data: {}
+
+###
+@mock
+GET /users
+`
+	parsed := ParseHttpFile(content)
+	if len(parsed.Requests) != 3 {
+		t.Fatalf("expected 3 requests, got %d", len(parsed.Requests))
+	}
+	ws, sse := parsed.Requests[0], parsed.Requests[1]
+	if !ws.IsMock || ws.Method != "WS" || ws.URL != "/notifications/:user" || ws.Name != "notifications" {
+		t.Fatalf("ws mock = %+v", ws)
+	}
+	if !strings.Contains(ws.PreScript, "onConnect") {
+		t.Errorf("ws script not attached: %q", ws.PreScript)
+	}
+	if !sse.IsMock || sse.Method != "SSE" || sse.URL != "/events" || sse.Headers["X-Stream"] != "yes" {
+		t.Fatalf("sse mock = %+v", sse)
+	}
+	if sse.Body != "event: ready\ndata: {}" {
+		t.Errorf("sse body = %q", sse.Body)
+	}
+	if !parsed.Requests[2].IsMock || parsed.Requests[2].Method != "GET" {
+		t.Errorf("plain @mock block = %+v", parsed.Requests[2])
+	}
+}
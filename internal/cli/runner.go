@@ -14,8 +14,9 @@ import (
 	"strings"
 	"time"
 
+	"rawrequest/internal/assertions"
 	hcl "rawrequest/internal/httpclientlogic"
-	"rawrequest/internal/mockserver"
+	rc "rawrequest/internal/requestchain"
 	se "rawrequest/internal/scriptexec"
 	sr "rawrequest/internal/scriptruntime"
 )
@@ -69,21 +70,24 @@ func NewRunner(opts *Options, version string) *Runner {
 
 // ResponseResult holds the result of an HTTP request
 type ResponseResult struct {
-	RequestName  string            `json:"requestName,omitempty"`
-	Method       string            `json:"method"`
-	URL          string            `json:"url"`
-	Status       int               `json:"status"`
-	StatusText   string            `json:"statusText"`
-	Headers      map[string]string `json:"headers"`
-	Body         string            `json:"body"`
-	ResponseTime int64             `json:"responseTime"`
-	Timing       TimingInfo        `json:"timing"`
-	Size         int64             `json:"size"`
-	Error        string            `json:"error,omitempty"`
-	ScriptLogs   []ScriptLogEntry  `json:"scriptLogs,omitempty"`
-	IsBinary     bool              `json:"isBinary,omitempty"`
-	ContentType  string            `json:"contentType,omitempty"`
-	rawBody      []byte            // raw bytes for binary responses (not serialised)
+	RequestName  string               `json:"requestName,omitempty"`
+	Method       string               `json:"method"`
+	URL          string               `json:"url"`
+	Status       int                  `json:"status"`
+	StatusText   string               `json:"statusText"`
+	Headers      map[string]string    `json:"headers"`
+	Body         string               `json:"body"`
+	ResponseTime int64                `json:"responseTime"`
+	Timing       TimingInfo           `json:"timing"`
+	Size         int64                `json:"size"`
+	Error        string               `json:"error,omitempty"`
+	ScriptLogs   []ScriptLogEntry     `json:"scriptLogs,omitempty"`
+	Assertions   []sr.AssertionResult `json:"assertions,omitempty"`
+	Flow         *sr.FlowControl      `json:"flow,omitempty"`
+	Iteration    int                  `json:"iteration,omitempty"`
+	IsBinary     bool                 `json:"isBinary,omitempty"`
+	ContentType  string               `json:"contentType,omitempty"`
+	rawBody      []byte               // raw bytes for binary responses (not serialised)
 }
 
 // TimingInfo contains request timing breakdown
@@ -121,47 +125,6 @@ func Run(opts *Options, version string) int {
 	}
 }
 
-// RunMockServer starts the mock server in CLI mode
-func RunMockServer(opts *Options) int {
-	content, err := os.ReadFile(opts.File)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
-		return 1
-	}
-
-	parsed := ParseHttpFile(string(content))
-	if len(parsed.Requests) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: no requests found in %s\n", opts.File)
-		return 1
-	}
-
-	var mockReqs []mockserver.MockRequest
-	for _, req := range parsed.Requests {
-		if req.IsMock {
-			mockReqs = append(mockReqs, mockserver.MockRequest{
-				Name:       req.Name,
-				Method:     req.Method,
-				URL:        req.URL,
-				Headers:    req.Headers,
-				Body:       req.Body,
-				PreScript:  req.PreScript,
-				PostScript: req.PostScript,
-			})
-		}
-	}
-
-	if len(mockReqs) == 0 {
-		fmt.Fprintf(os.Stderr, "Error: no mock endpoint definitions found in %s (use the @mock annotation to mark a request block as a mock endpoint)\n", opts.File)
-		return 1
-	}
-
-	if err := mockserver.StartMockServer(opts.File, opts.MockPort, opts.MockDB, mockReqs); err != nil {
-		fmt.Fprintf(os.Stderr, "Error starting mock server: %v\n", err)
-		return 1
-	}
-	return 0
-}
-
 func runList(opts *Options) int {
 	content, err := os.ReadFile(opts.File)
 	if err != nil {
@@ -262,13 +225,38 @@ func runRequests(opts *Options, version string) int {
 		return 1
 	}
 
-	// Execute requests
+	// Execute requests, following request.skip/chain.stop/chain.next decisions
 	var results []ResponseResult
 	hasError := false
 
-	for _, req := range requests {
-		result := runner.ExecuteRequest(req)
+	names := make([]string, len(requests))
+	for i, req := range requests {
+		names[i] = req.Name
+	}
+	stepper := rc.NewStepper(names)
+	for i, req := range requests {
+		stepper.SetMaxRuns(i, req.MaxRuns)
+	}
+	for {
+		i, iteration, ok, err := stepper.Next()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			hasError = true
+			break
+		}
+		if !ok {
+			break
+		}
+		result := runner.executeRequest(requests[i], iteration)
 		results = append(results, result)
+		var flow sr.FlowControl
+		if result.Flow != nil {
+			flow = *result.Flow
+		}
+		if err := stepper.Advance(i, flow); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			hasError = true
+		}
 
 		if result.Error != "" {
 			hasError = true
@@ -276,6 +264,9 @@ func runRequests(opts *Options, version string) int {
 		if result.Status >= 400 {
 			hasError = true
 		}
+		if result.FailedAssertions() > 0 {
+			hasError = true
+		}
 	}
 
 	// Output results
@@ -289,11 +280,20 @@ func runRequests(opts *Options, version string) int {
 
 // ExecuteRequest performs a single HTTP request
 func (r *Runner) ExecuteRequest(req Request) ResponseResult {
+	return r.executeRequest(req, 1)
+}
+
+// executeRequest runs req as the given 1-based iteration within a run, which
+// scripts see as chain.iteration.
+func (r *Runner) executeRequest(req Request, iteration int) ResponseResult {
 	result := ResponseResult{
 		RequestName: req.Name,
 		Method:      req.Method,
 		Headers:     make(map[string]string),
 	}
+	if iteration > 1 {
+		result.Iteration = iteration
+	}
 
 	// Collect script logs during execution
 	var scriptLogs []ScriptLogEntry
@@ -341,6 +341,7 @@ func (r *Runner) ExecuteRequest(req Request) ResponseResult {
 					"name":    req.Name,
 				},
 				Variables: r.variablesSnapshot(),
+				Iteration: iteration,
 			}
 			se.Execute(cleaned, scriptCtx, "pre", se.Dependencies{
 				VariablesSnapshot: r.variablesSnapshot,
@@ -363,6 +364,14 @@ func (r *Runner) ExecuteRequest(req Request) ResponseResult {
 			if h := extractStringHeaders(scriptCtx.Request["headers"]); h != nil {
 				headers = h
 			}
+			if flow := scriptCtx.Flow; !flow.IsZero() {
+				result.Flow = &flow
+				if flow.Skipped || flow.Stopped {
+					result.Assertions = scriptCtx.Assertions
+					result.ScriptLogs = scriptLogs
+					return result
+				}
+			}
 		}
 	}
 
@@ -452,23 +461,24 @@ func (r *Runner) ExecuteRequest(req Request) ResponseResult {
 		result.Body = string(execResult.Body)
 	}
 
+	responseData := map[string]interface{}{
+		"status":       execResult.StatusCode,
+		"statusText":   execResult.StatusText,
+		"headers":      execResult.ResponseHeaders,
+		"body":         string(execResult.Body),
+		"text":         string(execResult.Body),
+		"responseTime": execResult.Timing.Total,
+		"size":         execResult.Size,
+	}
+	var jsonData interface{}
+	if json.Unmarshal(execResult.Body, &jsonData) == nil {
+		responseData["json"] = jsonData
+	}
+
 	// Execute post-script
 	if !r.noScripts && req.PostScript != "" {
 		cleaned := cleanScript(req.PostScript)
 		if cleaned != "" {
-			responseData := map[string]interface{}{
-				"status":       execResult.StatusCode,
-				"statusText":   execResult.StatusText,
-				"headers":      execResult.ResponseHeaders,
-				"body":         string(execResult.Body),
-				"text":         string(execResult.Body),
-				"responseTime": execResult.Timing.Total,
-				"size":         execResult.Size,
-			}
-			var jsonData interface{}
-			if json.Unmarshal(execResult.Body, &jsonData) == nil {
-				responseData["json"] = jsonData
-			}
 			if scriptCtx == nil {
 				scriptCtx = &sr.ExecutionContext{
 					Request: map[string]interface{}{
@@ -478,6 +488,7 @@ func (r *Runner) ExecuteRequest(req Request) ResponseResult {
 						"body":    body,
 						"name":    req.Name,
 					},
+					Iteration: iteration,
 				}
 			}
 			scriptCtx.Response = responseData
@@ -491,10 +502,29 @@ func (r *Runner) ExecuteRequest(req Request) ResponseResult {
 		}
 	}
 
+	if scriptCtx != nil {
+		result.Assertions = append(result.Assertions, scriptCtx.Assertions...)
+		if flow := scriptCtx.Flow; !flow.IsZero() {
+			result.Flow = &flow
+		}
+	}
+	result.Assertions = append(result.Assertions, assertions.Evaluate(req.Assertions, responseData)...)
+
 	result.ScriptLogs = scriptLogs
 	return result
 }
 
+// FailedAssertions returns the number of assertions that did not pass.
+func (r ResponseResult) FailedAssertions() int {
+	failed := 0
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			failed++
+		}
+	}
+	return failed
+}
+
 func (r *Runner) resolveVariables(input string) string {
 	result := input
 
@@ -663,6 +693,12 @@ func outputResults(results []ResponseResult, format OutputFormat) {
 			if i > 0 {
 				fmt.Println("---")
 			}
+			if r.Flow != nil {
+				fmt.Fprintf(os.Stderr, "[%s] %s\n", requestLabel(r), rc.DescribeFlow(*r.Flow))
+				if r.Skipped() {
+					continue
+				}
+			}
 			if r.IsBinary && r.rawBody != nil {
 				// Write raw bytes to stdout (pipe-friendly)
 				os.Stdout.Write(r.rawBody)
@@ -689,6 +725,15 @@ func outputResults(results []ResponseResult, format OutputFormat) {
 			if r.RequestName != "" {
 				fmt.Printf("Request: %s\n", r.RequestName)
 			}
+			if r.Iteration > 1 {
+				fmt.Printf("Iteration: %d\n", r.Iteration)
+			}
+			if r.Flow != nil {
+				fmt.Printf("Flow: %s\n", rc.DescribeFlow(*r.Flow))
+				if r.Skipped() {
+					continue
+				}
+			}
 			if r.Error != "" {
 				fmt.Printf("Error: %s\n", r.Error)
 				continue
@@ -696,6 +741,16 @@ func outputResults(results []ResponseResult, format OutputFormat) {
 			fmt.Printf("%s %s\n", r.Method, r.URL)
 			fmt.Printf("Status: %s\n", r.StatusText)
 			fmt.Printf("Time: %dms, Size: %d bytes\n", r.ResponseTime, r.Size)
+			if len(r.Assertions) > 0 {
+				fmt.Printf("Assertions: %d/%d passed\n", len(r.Assertions)-r.FailedAssertions(), len(r.Assertions))
+				for _, a := range r.Assertions {
+					mark := "✓"
+					if !a.Passed {
+						mark = "✗"
+					}
+					fmt.Printf("  %s %s\n", mark, a.Message)
+				}
+			}
 			fmt.Println()
 			if r.IsBinary {
 				fmt.Printf("[Binary response: %s, %s]\n",
@@ -715,6 +770,19 @@ func outputResults(results []ResponseResult, format OutputFormat) {
 	}
 }
 
+// Skipped reports whether a pre-script kept the request from being sent,
+// via request.skip() or chain.stop().
+func (r ResponseResult) Skipped() bool {
+	return r.Flow != nil && (r.Flow.Skipped || r.Flow.Stopped) && r.Status == 0 && r.Error == ""
+}
+
+func requestLabel(r ResponseResult) string {
+	if r.RequestName != "" {
+		return r.RequestName
+	}
+	return r.Method + " " + r.URL
+}
+
 func formatBinarySize(bytes int64) string {
 	if bytes == 0 {
 		return "0 B"
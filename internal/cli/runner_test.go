@@ -3,7 +3,10 @@ package cli
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -297,3 +300,108 @@ func TestCleanScript(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteRequest_EvaluatesDeclarativeAssertions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"u-1"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	runner := NewRunner(&Options{Variables: make(map[string]string), NoScripts: true}, "test")
+	result := runner.ExecuteRequest(Request{
+		Method:     http.MethodPost,
+		URL:        srv.URL,
+		Assertions: []string{"status == 201", "jsonpath $.id == u-1", "header Content-Type contains xml"},
+	})
+
+	if result.Error != "" {
+		t.Fatalf("unexpected error: %s", result.Error)
+	}
+	if len(result.Assertions) != 3 {
+		t.Fatalf("assertions=%d want 3", len(result.Assertions))
+	}
+	if !result.Assertions[0].Passed || !result.Assertions[1].Passed {
+		t.Errorf("expected first two assertions to pass: %#v", result.Assertions)
+	}
+	if result.FailedAssertions() != 1 {
+		t.Errorf("FailedAssertions=%d want 1", result.FailedAssertions())
+	}
+}
+
+func TestRunRequests_ScriptFlowControl(t *testing.T) {
+	var mu sync.Mutex
+	hits := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.Method+" "+r.URL.Path]++
+		n := hits[r.Method+" "+r.URL.Path]
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/jobs/1" {
+			state := "running"
+			if n >= 3 {
+				state = "done"
+			}
+			_, _ = w.Write([]byte(`{"state":"` + state + `"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	content := `### lookup
+@name lookupUser
+GET ` + srv.URL + `/users/1
+
+> {
+  setVar('userExists', String(response.status === 200));
+}
+
+### create
+@name createUser
+POST ` + srv.URL + `/users
+
+< {
+  if (getVar('userExists') === 'true') request.skip('user exists');
+}
+
+### poll
+@name getJobStatus
+GET ` + srv.URL + `/jobs/1
+
+> {
+  if (response.json.state !== 'done' && chain.iteration < 10) chain.next('getJobStatus');
+}
+
+### never
+@name stopHere
+GET ` + srv.URL + `/stop
+
+< {
+  chain.stop('enough');
+}
+
+### unreachable
+GET ` + srv.URL + `/unreachable
+`
+	path := filepath.Join(t.TempDir(), "flow.http")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	code := runRequests(&Options{File: path, Output: OutputQuiet, Variables: map[string]string{}, Environment: "default"}, "test")
+	if code != 0 {
+		t.Fatalf("exit code=%d want 0", code)
+	}
+	want := map[string]int{"GET /users/1": 1, "GET /jobs/1": 3}
+	if len(hits) != len(want) {
+		t.Fatalf("hits=%v want %v", hits, want)
+	}
+	for k, v := range want {
+		if hits[k] != v {
+			t.Fatalf("hits[%s]=%d want %d (all: %v)", k, hits[k], v, hits)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+// Package eventloop gives a goja runtime setTimeout, setInterval and
+// queueMicrotask. Request scripts and mock scripts share it so timers order
+// and cancel the same way everywhere.
+package eventloop
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// MinTimerDelay matches the clamp browsers and Node apply to nested timers,
+// so a setInterval(fn, 0) cannot spin the loop.
+const MinTimerDelay = time.Millisecond
+
+// ErrTimeout is returned by Run when the time budget runs out before the
+// last timer fired.
+var ErrTimeout = errors.New("script timeout")
+
+type timer struct {
+	id       int64
+	due      time.Time
+	fn       goja.Callable
+	args     []goja.Value
+	interval time.Duration
+}
+
+// Loop holds the pending timers of one runtime. goja runs promise jobs itself
+// whenever control returns from JS, so the loop only has to fire due timers.
+// It is not safe for concurrent use; like the runtime, it belongs to the
+// goroutine that runs the script.
+type Loop struct {
+	vm     *goja.Runtime
+	timers map[int64]*timer
+	nextID int64
+}
+
+// Install defines the timer globals on vm and returns the loop that owns
+// them.
+func Install(vm *goja.Runtime) *Loop {
+	l := &Loop{vm: vm, timers: map[int64]*timer{}}
+	_ = vm.Set("setTimeout", func(call goja.FunctionCall) goja.Value {
+		return l.schedule(call, false)
+	})
+	_ = vm.Set("setInterval", func(call goja.FunctionCall) goja.Value {
+		return l.schedule(call, true)
+	})
+	clear := func(call goja.FunctionCall) goja.Value {
+		delete(l.timers, call.Argument(0).ToInteger())
+		return goja.Undefined()
+	}
+	_ = vm.Set("clearTimeout", clear)
+	_ = vm.Set("clearInterval", clear)
+	_, _ = vm.RunProgram(queueMicrotaskProgram)
+	return l
+}
+
+var queueMicrotaskProgram = goja.MustCompile("queueMicrotask.js",
+	"globalThis.queueMicrotask = function(fn) { Promise.resolve().then(function() { fn(); }); };", false)
+
+func (l *Loop) schedule(call goja.FunctionCall, repeat bool) goja.Value {
+	fn, ok := goja.AssertFunction(call.Argument(0))
+	if !ok {
+		panic(l.vm.NewTypeError("timer callback must be a function"))
+	}
+	delay := time.Duration(call.Argument(1).ToFloat() * float64(time.Millisecond))
+	if delay < MinTimerDelay {
+		delay = MinTimerDelay
+	}
+	var args []goja.Value
+	if len(call.Arguments) > 2 {
+		args = append(args, call.Arguments[2:]...)
+	}
+	l.nextID++
+	t := &timer{id: l.nextID, due: time.Now().Add(delay), fn: fn, args: args}
+	if repeat {
+		t.interval = delay
+	}
+	l.timers[t.id] = t
+	return l.vm.ToValue(t.id)
+}
+
+// Pending reports whether any timer is still scheduled.
+func (l *Loop) Pending() bool {
+	return len(l.timers) > 0
+}
+
+// Wait returns how long until the next timer is due, or false when none is
+// pending. Callers that also wait on other events (e.g. socket messages)
+// sleep at most this long and then call RunDue.
+func (l *Loop) Wait() (time.Duration, bool) {
+	next := l.next()
+	if next == nil {
+		return 0, false
+	}
+	if wait := time.Until(next.due); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
+
+// RunDue fires the timers that are due now, in due order, and returns the
+// first error a callback throws.
+func (l *Loop) RunDue() error {
+	now := time.Now()
+	for {
+		next := l.next()
+		if next == nil || next.due.After(now) {
+			return nil
+		}
+		if err := l.fire(next); err != nil {
+			return err
+		}
+	}
+}
+
+// Run fires timers in due order until none are pending. remaining reports
+// the time budget left (nil means unlimited); Run returns ErrTimeout once it
+// is used up. A close of done ends the loop early without an error. Run also
+// returns the first error a timer callback throws.
+func (l *Loop) Run(remaining func() time.Duration, done <-chan struct{}) error {
+	for len(l.timers) > 0 {
+		next := l.next()
+		if wait := time.Until(next.due); wait > 0 {
+			if remaining != nil {
+				left := remaining()
+				if left <= 0 {
+					return ErrTimeout
+				}
+				if wait > left {
+					if !sleep(left, done) {
+						return nil
+					}
+					return ErrTimeout
+				}
+			}
+			if !sleep(wait, done) {
+				return nil
+			}
+		}
+		if remaining != nil && remaining() <= 0 {
+			return ErrTimeout
+		}
+		if err := l.fire(next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Loop) fire(t *timer) error {
+	if t.interval > 0 {
+		t.due = time.Now().Add(t.interval)
+	} else {
+		delete(l.timers, t.id)
+	}
+	_, err := t.fn(goja.Undefined(), t.args...)
+	return err
+}
+
+func (l *Loop) next() *timer {
+	var next *timer
+	for _, t := range l.timers {
+		if next == nil || t.due.Before(next.due) || (t.due.Equal(next.due) && t.id < next.id) {
+			next = t
+		}
+	}
+	return next
+}
+
+// sleep waits for d and reports false if done closed first.
+func sleep(d time.Duration, done <-chan struct{}) bool {
+	if done == nil {
+		time.Sleep(d)
+		return true
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-done:
+		return false
+	}
+}
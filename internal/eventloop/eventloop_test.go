@@ -0,0 +1,109 @@
+package eventloop
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+func TestRun_FiresTimersInDueOrder(t *testing.T) {
+	vm := goja.New()
+	loop := Install(vm)
+	_, err := vm.RunString(`
+var out = [];
+setTimeout(function () { out.push("b"); }, 20);
+setTimeout(function () { out.push("a"); }, 5);
+var cancelled = setTimeout(function () { out.push("x"); }, 10);
+clearTimeout(cancelled);
+var n = 0;
+var iv = setInterval(function () { out.push("i" + (++n)); if (n === 2) clearInterval(iv); }, 1);
+queueMicrotask(function () { out.push("m"); });
+`)
+	if err != nil {
+		t.Fatalf("RunString: %v", err)
+	}
+	if err := loop.Run(nil, nil); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := vm.Get("out").String(); got != "m,i1,i2,a,b" {
+		t.Fatalf("out=%s", got)
+	}
+}
+
+func TestRun_StopsAtDeadline(t *testing.T) {
+	vm := goja.New()
+	loop := Install(vm)
+	if _, err := vm.RunString(`setInterval(function () {}, 1);`); err != nil {
+		t.Fatalf("RunString: %v", err)
+	}
+	deadline := time.Now().Add(20 * time.Millisecond)
+	err := loop.Run(func() time.Duration { return time.Until(deadline) }, nil)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err=%v want ErrTimeout", err)
+	}
+}
+
+func TestRun_DoneEndsLoop(t *testing.T) {
+	vm := goja.New()
+	loop := Install(vm)
+	if _, err := vm.RunString(`setTimeout(function () {}, 10000);`); err != nil {
+		t.Fatalf("RunString: %v", err)
+	}
+	done := make(chan struct{})
+	close(done)
+	if err := loop.Run(nil, done); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestWaitAndRunDue(t *testing.T) {
+	vm := goja.New()
+	loop := Install(vm)
+	if _, ok := loop.Wait(); ok {
+		t.Fatalf("Wait reported a timer on an empty loop")
+	}
+	if _, err := vm.RunString(`var fired = 0; setTimeout(function () { fired++; }, 5);`); err != nil {
+		t.Fatalf("RunString: %v", err)
+	}
+	wait, ok := loop.Wait()
+	if !ok || wait <= 0 {
+		t.Fatalf("Wait=%v,%v want a positive wait", wait, ok)
+	}
+	if err := loop.RunDue(); err != nil || vm.Get("fired").ToInteger() != 0 {
+		t.Fatalf("RunDue fired a timer early (err=%v)", err)
+	}
+	time.Sleep(wait)
+	if err := loop.RunDue(); err != nil {
+		t.Fatalf("RunDue: %v", err)
+	}
+	if vm.Get("fired").ToInteger() != 1 || loop.Pending() {
+		t.Fatalf("fired=%d pending=%v", vm.Get("fired").ToInteger(), loop.Pending())
+	}
+}
+
+func TestWatchdog_LateTimerCallbackIsIgnored(t *testing.T) {
+	vm := goja.New()
+	w := StartWatchdog(vm, time.Hour)
+	stale := w.armed
+
+	// A callback that fires after suspend or stop must not interrupt the VM.
+	w.Suspend()
+	w.expire(stale)
+	w.Resume()
+	resumed := w.armed
+	if w.Stop() {
+		t.Fatal("Stop() reported a timeout that never happened")
+	}
+	w.expire(resumed)
+	if _, err := vm.RunString("1"); err != nil {
+		t.Fatalf("VM was interrupted after stop: %v", err)
+	}
+
+	w = StartWatchdog(vm, 0)
+	w.expire(w.armed)
+	if !w.Stop() {
+		t.Fatal("Stop() did not report the timeout, so the VM would be pooled")
+	}
+}
@@ -0,0 +1,98 @@
+package eventloop
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// DefaultTimeout bounds a script run, including the time spent waiting on
+// timers and promises after the script body returns.
+const DefaultTimeout = 30 * time.Second
+
+// Watchdog interrupts a runtime with ErrTimeout once the script has used its
+// time budget. Suspending it (while a debugger pause waits on the user)
+// stops the clock.
+type Watchdog struct {
+	mu       sync.Mutex
+	vm       *goja.Runtime
+	deadline time.Time
+	left     time.Duration
+	timer    *time.Timer
+	// armed changes whenever the timer is replaced or disarmed, so a timer
+	// callback that lost the race with Stop does nothing.
+	armed int
+	fired bool
+}
+
+// StartWatchdog starts the clock on a budget of timeout.
+func StartWatchdog(vm *goja.Runtime, timeout time.Duration) *Watchdog {
+	w := &Watchdog{vm: vm, left: timeout}
+	w.Resume()
+	return w
+}
+
+// Resume restarts the clock after Suspend.
+func (w *Watchdog) Resume() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.fired {
+		return
+	}
+	w.armed++
+	armed := w.armed
+	w.deadline = time.Now().Add(w.left)
+	w.timer = time.AfterFunc(w.left, func() { w.expire(armed) })
+}
+
+func (w *Watchdog) expire(armed int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if armed != w.armed {
+		return
+	}
+	w.fired = true
+	w.vm.Interrupt(ErrTimeout)
+}
+
+// Suspend stops the clock, keeping the unused budget for Resume.
+func (w *Watchdog) Suspend() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer == nil || w.fired {
+		return
+	}
+	w.timer.Stop()
+	w.armed++
+	// If the timer already went off, the callback is discarded above and
+	// the budget is simply used up.
+	w.left = max(time.Until(w.deadline), 0)
+}
+
+// Stop disarms the watchdog and reports whether it interrupted the runtime,
+// in which case the runtime must not be reused.
+func (w *Watchdog) Stop() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.armed++
+	return w.fired
+}
+
+// Remaining is the budget left, for Loop.Run.
+func (w *Watchdog) Remaining() time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return time.Until(w.deadline)
+}
+
+// IsTimeout reports whether err comes from a run that used up its budget,
+// either in Loop.Run or as the interrupt the Watchdog raised.
+func IsTimeout(err error) bool {
+	var interrupted *goja.InterruptedError
+	return errors.Is(err, ErrTimeout) || (errors.As(err, &interrupted) && interrupted.Value() == ErrTimeout)
+}
@@ -0,0 +1,18 @@
+// Package filewatch holds the change detection shared by the app's file
+// watcher and the mock server's hot reload.
+package filewatch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashContent returns a stable, short fingerprint of the given bytes.
+// Watchers compare it after an mtime change to tell a real edit from
+// spurious mtime churn (e.g. `touch`, filesystem snapshot tooling, or
+// sub-second mtime granularity drift after a save). It is only used for
+// equality comparisons, never persisted.
+func HashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
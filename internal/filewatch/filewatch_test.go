@@ -0,0 +1,16 @@
+package filewatch
+
+import "testing"
+
+func TestHashContent(t *testing.T) {
+	a := HashContent([]byte("GET /users"))
+	if a != HashContent([]byte("GET /users")) {
+		t.Fatalf("hash is not stable")
+	}
+	if a == HashContent([]byte("GET /users/1")) {
+		t.Fatalf("different content hashed the same")
+	}
+	if len(a) != 64 {
+		t.Fatalf("len=%d want 64 hex chars", len(a))
+	}
+}
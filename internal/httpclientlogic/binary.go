@@ -94,6 +94,45 @@ func SniffContentType(data []byte) string {
 	return http.DetectContentType(data)
 }
 
+// knownExtensions maps binary media types to their usual file extension.
+var knownExtensions = map[string]string{
+	"application/octet-stream":     ".bin",
+	"application/pdf":              ".pdf",
+	"application/zip":              ".zip",
+	"application/gzip":             ".gz",
+	"application/x-tar":            ".tar",
+	"application/x-gzip":           ".tar.gz",
+	"application/java-archive":     ".jar",
+	"application/java":             ".class",
+	"application/wasm":             ".wasm",
+	"application/x-7z-compressed":  ".7z",
+	"application/x-rar-compressed": ".rar",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":       ".xlsx",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
+	"application/vnd.ms-excel": ".xls",
+	"application/msword":       ".doc",
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"image/webp":               ".webp",
+	"image/svg+xml":            ".svg",
+	"image/bmp":                ".bmp",
+	"image/tiff":               ".tiff",
+	"image/x-icon":             ".ico",
+	"audio/mpeg":               ".mp3",
+	"audio/ogg":                ".ogg",
+	"audio/wav":                ".wav",
+	"video/mp4":                ".mp4",
+	"video/webm":               ".webm",
+	"video/ogg":                ".ogv",
+	"font/woff":                ".woff",
+	"font/woff2":               ".woff2",
+	"font/ttf":                 ".ttf",
+	"font/otf":                 ".otf",
+	"application/x-protobuf":   ".pb",
+	"application/protobuf":     ".pb",
+}
+
 // ContentTypeForFilename returns a suggested filename extension for the given
 // content type, used when proposing a save-as filename.
 func ExtensionForContentType(contentType string) string {
@@ -107,45 +146,7 @@ func ExtensionForContentType(contentType string) string {
 	}
 	mediaType = strings.ToLower(mediaType)
 
-	known := map[string]string{
-		"application/octet-stream":  ".bin",
-		"application/pdf":           ".pdf",
-		"application/zip":           ".zip",
-		"application/gzip":          ".gz",
-		"application/x-tar":         ".tar",
-		"application/x-gzip":        ".tar.gz",
-		"application/java-archive":  ".jar",
-		"application/java":          ".class",
-		"application/wasm":          ".wasm",
-		"application/x-7z-compressed": ".7z",
-		"application/x-rar-compressed": ".rar",
-		"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet": ".xlsx",
-		"application/vnd.openxmlformats-officedocument.wordprocessingml.document": ".docx",
-		"application/vnd.ms-excel":       ".xls",
-		"application/msword":             ".doc",
-		"image/png":                      ".png",
-		"image/jpeg":                     ".jpg",
-		"image/gif":                      ".gif",
-		"image/webp":                     ".webp",
-		"image/svg+xml":                  ".svg",
-		"image/bmp":                      ".bmp",
-		"image/tiff":                     ".tiff",
-		"image/x-icon":                   ".ico",
-		"audio/mpeg":                     ".mp3",
-		"audio/ogg":                      ".ogg",
-		"audio/wav":                      ".wav",
-		"video/mp4":                      ".mp4",
-		"video/webm":                     ".webm",
-		"video/ogg":                      ".ogv",
-		"font/woff":                      ".woff",
-		"font/woff2":                     ".woff2",
-		"font/ttf":                       ".ttf",
-		"font/otf":                       ".otf",
-		"application/x-protobuf":         ".pb",
-		"application/protobuf":           ".pb",
-	}
-
-	if ext, ok := known[mediaType]; ok {
+	if ext, ok := knownExtensions[mediaType]; ok {
 		return ext
 	}
 
@@ -167,3 +168,32 @@ func ExtensionForContentType(contentType string) string {
 
 	return ".bin"
 }
+
+// ContentTypeForExtension is the reverse of ExtensionForContentType: it
+// returns the content type for a file extension such as ".pdf" or "png",
+// falling back to the system MIME table and then application/octet-stream.
+func ContentTypeForExtension(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext == "" {
+		return "application/octet-stream"
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+
+	// Several types share an extension (.pb); pick the first alphabetically
+	// so the answer does not depend on map order.
+	match := ""
+	for mediaType, known := range knownExtensions {
+		if known == ext && (match == "" || mediaType < match) {
+			match = mediaType
+		}
+	}
+	if match != "" {
+		return match
+	}
+	if byExt := mime.TypeByExtension(ext); byExt != "" {
+		return byExt
+	}
+	return "application/octet-stream"
+}
@@ -172,4 +172,25 @@ func TestSniffContentType(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestContentTypeForExtension(t *testing.T) {
+	tests := []struct {
+		ext  string
+		want string
+	}{
+		{".pdf", "application/pdf"},
+		{"PNG", "image/png"},
+		{".xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"},
+		{".pb", "application/protobuf"},
+		{".json", "application/json"},
+		{".unknownext", "application/octet-stream"},
+		{"", "application/octet-stream"},
+	}
+
+	for _, tc := range tests {
+		if got := ContentTypeForExtension(tc.ext); got != tc.want {
+			t.Errorf("ContentTypeForExtension(%q) = %q, want %q", tc.ext, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Lookup walks a decoded JSON value (maps, slices and scalars as produced by
+// encoding/json) using a small JSONPath subset: `$`, `.key`, `['key']` and
+// `[index]`. Negative indexes count from the end, and `.length` on an array,
+// object or string yields its size when no real key of that name exists.
+func Lookup(data interface{}, path string) (interface{}, bool) {
+	segments, err := Split(path)
+	if err != nil {
+		return nil, false
+	}
+	current := data
+	for _, seg := range segments {
+		next, ok := step(current, seg)
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	return current, true
+}
+
+// Split breaks a path into its key/index segments. Index segments are kept as
+// their decimal string so callers can round-trip them.
+func Split(path string) ([]string, error) {
+	path = strings.TrimSpace(path)
+	path = strings.TrimPrefix(path, "$")
+	var segments []string
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("empty key at offset %d", start)
+			}
+			segments = append(segments, path[start:i])
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated bracket at offset %d", i)
+			}
+			inner := strings.TrimSpace(path[i+1 : i+end])
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				inner = inner[1 : len(inner)-1]
+			} else if _, err := strconv.Atoi(inner); err != nil {
+				return nil, fmt.Errorf("invalid index %q", inner)
+			}
+			segments = append(segments, inner)
+			i += end + 1
+		default:
+			// Allow a bare leading key such as "data.id".
+			if i > 0 {
+				return nil, fmt.Errorf("unexpected %q at offset %d", path[i], i)
+			}
+			start := i
+			for i < len(path) && path[i] != '.' && path[i] != '[' {
+				i++
+			}
+			segments = append(segments, path[start:i])
+		}
+	}
+	return segments, nil
+}
+
+func step(current interface{}, seg string) (interface{}, bool) {
+	switch v := current.(type) {
+	case map[string]interface{}:
+		if val, ok := v[seg]; ok {
+			return val, true
+		}
+		if seg == "length" {
+			return float64(len(v)), true
+		}
+	case []interface{}:
+		if seg == "length" {
+			return float64(len(v)), true
+		}
+		idx, err := strconv.Atoi(seg)
+		if err != nil {
+			return nil, false
+		}
+		if idx < 0 {
+			idx += len(v)
+		}
+		if idx < 0 || idx >= len(v) {
+			return nil, false
+		}
+		return v[idx], true
+	case string:
+		if seg == "length" {
+			return float64(len(v)), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,48 @@
+package jsonpath
+
+import "testing"
+
+func TestLookup(t *testing.T) {
+	data := map[string]interface{}{
+		"id": float64(7),
+		"user": map[string]interface{}{
+			"name": "ada",
+			"tags": []interface{}{"a", "b", "c"},
+		},
+		"odd key": true,
+	}
+
+	cases := []struct {
+		path string
+		want interface{}
+		ok   bool
+	}{
+		{"$.id", float64(7), true},
+		{"$.user.name", "ada", true},
+		{"user.name", "ada", true},
+		{"$.user.tags[1]", "b", true},
+		{"$.user.tags[-1]", "c", true},
+		{"$.user.tags.length", float64(3), true},
+		{"$['odd key']", true, true},
+		{"$.user.missing", nil, false},
+		{"$.user.tags[9]", nil, false},
+		{"$.user[", nil, false},
+	}
+	for _, tc := range cases {
+		got, ok := Lookup(data, tc.path)
+		if ok != tc.ok || got != tc.want {
+			t.Errorf("Lookup(%q) = %v, %v; want %v, %v", tc.path, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestLookup_RootReturnsWholeDocument(t *testing.T) {
+	data := []interface{}{float64(1)}
+	got, ok := Lookup(data, "$")
+	if !ok {
+		t.Fatalf("expected root lookup to succeed")
+	}
+	if arr, _ := got.([]interface{}); len(arr) != 1 {
+		t.Fatalf("unexpected root value: %#v", got)
+	}
+}
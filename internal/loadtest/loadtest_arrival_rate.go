@@ -0,0 +1,158 @@
+package loadtest
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultArrivalMaxUsers caps the iterations in flight at once for an
+// arrival-rate test that doesn't set users.
+const defaultArrivalMaxUsers = 1000
+
+// Stage is one leg of an arrival-rate profile: the rate moves linearly from
+// the previous stage's target to Target iterations per second over
+// DurationMs.
+type Stage struct {
+	DurationMs int64   `json:"durationMs"`
+	Target     float64 `json:"target"`
+}
+
+// ParseRate reads an arrival rate such as "50", "50rps", "50/s" or "600rpm"
+// and returns iterations per second.
+func ParseRate(raw string) (float64, error) {
+	s := strings.ToLower(strings.ReplaceAll(raw, " ", ""))
+	per := 1.0
+	for _, suffix := range []struct {
+		text string
+		per  float64
+	}{{"rps", 1}, {"/sec", 1}, {"/s", 1}, {"rpm", 60}, {"/min", 60}, {"/m", 60}} {
+		if strings.HasSuffix(s, suffix.text) {
+			s, per = strings.TrimSuffix(s, suffix.text), suffix.per
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, fmt.Errorf("invalid rate %q: use a number of requests per second like 50rps", raw)
+	}
+	return n / per, nil
+}
+
+// ParseStages reads a profile such as "30s:50rps, 2m:200rps, 30s:0rps".
+func ParseStages(raw string) ([]Stage, error) {
+	var stages []Stage
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		duration, target, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid stage %q: use duration:rate like 30s:50rps", part)
+		}
+		ms := parseDurationMsGo(duration)
+		if ms <= 0 {
+			return nil, fmt.Errorf("invalid stage %q: duration must be positive", part)
+		}
+		rate, err := ParseRate(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stage %q: %w", part, err)
+		}
+		stages = append(stages, Stage{DurationMs: ms, Target: rate})
+	}
+	return stages, nil
+}
+
+// FormatStages writes stages back in the form ParseStages reads.
+func FormatStages(stages []Stage) string {
+	parts := make([]string, len(stages))
+	for i, stage := range stages {
+		parts[i] = fmt.Sprintf("%s:%srps", time.Duration(stage.DurationMs)*time.Millisecond, strconv.FormatFloat(stage.Target, 'f', -1, 64))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func stagesDurationMs(stages []Stage) int64 {
+	var total int64
+	for _, stage := range stages {
+		total += stage.DurationMs
+	}
+	return total
+}
+
+// ArrivalSchedule yields the start times of an open-model load test, where
+// iterations arrive at a planned rate however long earlier ones take. The
+// n-th arrival (counting from zero) happens when the integral of the rate
+// reaches n. It is not safe for concurrent use.
+type ArrivalSchedule struct {
+	segments []arrivalSegment
+	seg      int
+	n        int64
+}
+
+type arrivalSegment struct {
+	startSec   float64
+	seconds    float64
+	from, to   float64
+	startCount float64
+}
+
+// NewArrivalSchedule starts at startRate and follows stages. Without stages
+// the rate stays at startRate for durationMs, or forever when durationMs is
+// zero.
+func NewArrivalSchedule(startRate float64, stages []Stage, durationMs int64) *ArrivalSchedule {
+	if len(stages) == 0 {
+		seconds := math.Inf(1)
+		if durationMs > 0 {
+			seconds = float64(durationMs) / 1000
+		}
+		return &ArrivalSchedule{segments: []arrivalSegment{{seconds: seconds, from: startRate, to: startRate}}}
+	}
+	s := &ArrivalSchedule{}
+	var at, count float64
+	from := startRate
+	for _, stage := range stages {
+		seconds := float64(stage.DurationMs) / 1000
+		s.segments = append(s.segments, arrivalSegment{startSec: at, seconds: seconds, from: from, to: stage.Target, startCount: count})
+		at += seconds
+		count += (from + stage.Target) / 2 * seconds
+		from = stage.Target
+	}
+	return s
+}
+
+// Next returns the offset from the start of the test of the next arrival,
+// or false once the profile has ended.
+func (s *ArrivalSchedule) Next() (time.Duration, bool) {
+	k := float64(s.n)
+	for ; s.seg < len(s.segments); s.seg++ {
+		seg := s.segments[s.seg]
+		area := (seg.from + seg.to) / 2 * seg.seconds
+		if math.IsInf(seg.seconds, 1) {
+			area = math.Inf(1)
+			if seg.from <= 0 {
+				area = 0
+			}
+		}
+		remaining := k - seg.startCount
+		if remaining >= area {
+			continue
+		}
+		// Solve from*t + slope*t²/2 = remaining for t, in a form that stays
+		// stable when the slope is zero or negative.
+		slope := 0.0
+		if !math.IsInf(seg.seconds, 1) {
+			slope = (seg.to - seg.from) / seg.seconds
+		}
+		var t float64
+		if denom := seg.from + math.Sqrt(math.Max(0, seg.from*seg.from+2*slope*remaining)); denom > 0 {
+			t = 2 * remaining / denom
+		}
+		s.n++
+		return time.Duration((seg.startSec + t) * float64(time.Second)), true
+	}
+	return 0, false
+}
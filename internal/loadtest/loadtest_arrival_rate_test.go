@@ -0,0 +1,127 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRate_Units(t *testing.T) {
+	for raw, want := range map[string]float64{"50": 50, "50rps": 50, "12.5 /s": 12.5, "600rpm": 10, "120/m": 2, "0rps": 0} {
+		got, err := ParseRate(raw)
+		if err != nil || got != want {
+			t.Errorf("ParseRate(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"", "fast", "-5rps"} {
+		if _, err := ParseRate(raw); err == nil {
+			t.Errorf("ParseRate(%q) should fail", raw)
+		}
+	}
+}
+
+func TestParseStages_RoundTrips(t *testing.T) {
+	stages, err := ParseStages("30s:50rps, 2m:200rps, 30s:0rps")
+	if err != nil {
+		t.Fatalf("ParseStages: %v", err)
+	}
+	want := []Stage{{30000, 50}, {120000, 200}, {30000, 0}}
+	if len(stages) != len(want) {
+		t.Fatalf("stages = %+v", stages)
+	}
+	for i := range want {
+		if stages[i] != want[i] {
+			t.Fatalf("stage %d = %+v, want %+v", i, stages[i], want[i])
+		}
+	}
+	if got := FormatStages(stages); got != "30s:50rps, 2m0s:200rps, 30s:0rps" {
+		t.Fatalf("FormatStages = %q", got)
+	}
+	for _, raw := range []string{"30s", "0s:5rps", "10s:lots"} {
+		if _, err := ParseStages(raw); err == nil {
+			t.Errorf("ParseStages(%q) should fail", raw)
+		}
+	}
+}
+
+func drainSchedule(s *ArrivalSchedule, limit int) []time.Duration {
+	var out []time.Duration
+	for len(out) < limit {
+		offset, ok := s.Next()
+		if !ok {
+			break
+		}
+		out = append(out, offset)
+	}
+	return out
+}
+
+func TestArrivalSchedule_ConstantRate(t *testing.T) {
+	arrivals := drainSchedule(NewArrivalSchedule(4, nil, 2000), 100)
+	if len(arrivals) != 8 {
+		t.Fatalf("arrivals = %v, want 8 over 2s at 4/s", arrivals)
+	}
+	for i, at := range arrivals {
+		if want := time.Duration(i) * 250 * time.Millisecond; at != want {
+			t.Fatalf("arrival %d at %v, want %v", i, at, want)
+		}
+	}
+
+	if n := len(drainSchedule(NewArrivalSchedule(4, nil, 0), 100)); n != 100 {
+		t.Fatalf("a rate without a duration should not end, got %d arrivals", n)
+	}
+}
+
+func TestArrivalSchedule_RampsBetweenStages(t *testing.T) {
+	// 0 -> 10/s over 2s is 10 arrivals, 10/s for 1s is 10 more, and the ramp
+	// down to 0 over 2s another 10.
+	arrivals := drainSchedule(NewArrivalSchedule(0, []Stage{{2000, 10}, {1000, 10}, {2000, 0}}, 0), 100)
+	if len(arrivals) != 30 {
+		t.Fatalf("got %d arrivals, want 30", len(arrivals))
+	}
+	for i := 1; i < len(arrivals); i++ {
+		if arrivals[i] < arrivals[i-1] {
+			t.Fatalf("arrivals out of order at %d: %v", i, arrivals)
+		}
+	}
+	// The rate at t is 5t during the ramp, so the count is 2.5t² and the
+	// 10th arrival lands on the 2s boundary.
+	if arrivals[10] != 2*time.Second || arrivals[20] != 3*time.Second {
+		t.Fatalf("stage boundaries at %v and %v", arrivals[10], arrivals[20])
+	}
+	if got, want := arrivals[5], time.Duration(1.4142135*float64(time.Second)); got < want-time.Millisecond || got > want+time.Millisecond {
+		t.Fatalf("arrival 5 at %v, want about %v", got, want)
+	}
+	var late int
+	for _, at := range arrivals[20:] {
+		if at >= 4*time.Second {
+			late++
+		}
+	}
+	if late != 2 || arrivals[29] >= 5*time.Second {
+		t.Fatalf("ramp down spread = %v", arrivals[20:])
+	}
+}
+
+func TestNormalizeConfig_ArrivalRate(t *testing.T) {
+	norm, err := NormalizeConfig(Config{Stages: "30s:50rps, 1m:100rps", RequestsPerSecond: ptrInt(20), Adaptive: true})
+	if err != nil {
+		t.Fatalf("NormalizeConfig: %v", err)
+	}
+	if !norm.HasArrival || !norm.HasDuration || norm.DurationMs != 90000 || norm.HasIterations {
+		t.Fatalf("stages should set the duration: %+v", norm)
+	}
+	if norm.MaxUsers != defaultArrivalMaxUsers || norm.StartUsers != norm.MaxUsers || norm.HasRps || norm.AdaptiveEnabled {
+		t.Fatalf("arrival config = %+v", norm)
+	}
+
+	users := 5
+	norm, _ = NormalizeConfig(Config{Rate: "10rps", Users: &users, Duration: "1m"})
+	if !norm.HasArrival || norm.ArrivalRate != 10 || norm.MaxUsers != 5 {
+		t.Fatalf("rate config = %+v", norm)
+	}
+	if _, err := NormalizeConfig(Config{Stages: "soon"}); err == nil {
+		t.Fatal("expected an invalid stage to be rejected")
+	}
+}
+
+func ptrInt(v int) *int { return &v }
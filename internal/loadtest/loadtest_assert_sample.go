@@ -0,0 +1,18 @@
+package loadtest
+
+type float64Source interface {
+	Float64() float64
+}
+
+// ShouldCheckResponse decides whether a load test response is run through the
+// request's assertions. A rate of 1 (or more) checks every response; smaller
+// rates check a random fraction so post-scripts stay affordable at high RPS.
+func ShouldCheckResponse(enabled bool, sampleRate float64, rng float64Source) bool {
+	if !enabled || sampleRate <= 0 {
+		return false
+	}
+	if sampleRate >= 1 || rng == nil {
+		return true
+	}
+	return rng.Float64() < sampleRate
+}
@@ -0,0 +1,43 @@
+package loadtest
+
+import "testing"
+
+type fixedFloat float64
+
+func (f fixedFloat) Float64() float64 { return float64(f) }
+
+func TestShouldCheckResponse(t *testing.T) {
+	if ShouldCheckResponse(false, 1, nil) {
+		t.Fatalf("expected disabled checks to be skipped")
+	}
+	if !ShouldCheckResponse(true, 1, fixedFloat(0.99)) {
+		t.Fatalf("expected full sampling to always check")
+	}
+	if !ShouldCheckResponse(true, 0.25, fixedFloat(0.1)) {
+		t.Fatalf("expected draw below rate to be checked")
+	}
+	if ShouldCheckResponse(true, 0.25, fixedFloat(0.5)) {
+		t.Fatalf("expected draw above rate to be skipped")
+	}
+	if ShouldCheckResponse(true, 0, fixedFloat(0)) {
+		t.Fatalf("expected zero rate to be skipped")
+	}
+}
+
+func TestNormalizeConfig_AssertSampling(t *testing.T) {
+	norm, err := NormalizeConfig(Config{Assert: "true", AssertSample: "10%"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !norm.AssertEnabled || norm.AssertSampleRate != 0.1 {
+		t.Fatalf("expected assert enabled at 0.1, got %v %v", norm.AssertEnabled, norm.AssertSampleRate)
+	}
+
+	norm, err = NormalizeConfig(Config{Assert: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if norm.AssertSampleRate != 1 {
+		t.Fatalf("expected default sample rate 1, got %v", norm.AssertSampleRate)
+	}
+}
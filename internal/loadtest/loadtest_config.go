@@ -1,6 +1,7 @@
 package loadtest
 
 import (
+	"fmt"
 	"math"
 	"strconv"
 	"strings"
@@ -31,6 +32,30 @@ type Config struct {
 	AdaptiveStable      any `json:"adaptiveStable"`
 	AdaptiveCooldown    any `json:"adaptiveCooldown"`
 	AdaptiveBackoffStep any `json:"adaptiveBackoffStep"`
+
+	Assert       any `json:"assert"`
+	AssertSample any `json:"assertSample"`
+
+	Data     string `json:"data"`
+	DataMode string `json:"dataMode"`
+	// DataRows carries the rows inline instead of a Data file; a distributed
+	// test sends each worker its share this way.
+	DataRows []map[string]string `json:"dataRows"`
+
+	// Rate and Stages switch to an open model: iterations start at a planned
+	// arrival rate instead of users looping, and users caps how many run at
+	// once.
+	Rate   any    `json:"rate"`
+	Stages string `json:"stages"`
+
+	// Thresholds are checked when the run ends; with ThresholdsAbort an
+	// upper limit crossed during the run aborts it.
+	Thresholds      string `json:"thresholds"`
+	ThresholdsAbort any    `json:"thresholdsAbort"`
+
+	// StartAt is a unix time in milliseconds to hold the start until, so
+	// several services of a distributed test begin together.
+	StartAt int64 `json:"startAt"`
 }
 
 type NormalizedConfig struct {
@@ -59,6 +84,22 @@ type NormalizedConfig struct {
 	AdaptiveStableSec        int64
 	AdaptiveCooldownMs       int64
 	AdaptiveBackoffStepUsers int64
+
+	AssertEnabled    bool
+	AssertSampleRate float64
+
+	DataPath string
+	DataRows []map[string]string
+	DataMode string
+
+	ArrivalRate float64
+	Stages      []Stage
+	HasArrival  bool
+
+	Thresholds      []Threshold
+	ThresholdsAbort bool
+
+	StartAtMs int64
 }
 
 func NormalizeConfig(cfg Config) (NormalizedConfig, error) {
@@ -84,7 +125,27 @@ func NormalizeConfig(cfg Config) (NormalizedConfig, error) {
 	}
 	_ = concurrent
 
+	arrivalRate, err := parseRateAnyGo(cfg.Rate)
+	if err != nil {
+		return NormalizedConfig{}, err
+	}
+	stages, err := ParseStages(cfg.Stages)
+	if err != nil {
+		return NormalizedConfig{}, err
+	}
+	hasArrival := arrivalRate > 0 || len(stages) > 0
+	if hasArrival {
+		// Users cap the iterations in flight, so they all start available.
+		if firstInt64(toInt(cfg.MaxUsers), toInt(cfg.Max), toInt(cfg.Concurrent), toInt(cfg.Users), toInt(cfg.Concurrency)) <= 0 {
+			maxUsers = defaultArrivalMaxUsers
+		}
+		startUsers = maxUsers
+	}
+
 	durationMs := parseDurationMsGo(strings.TrimSpace(cfg.Duration))
+	if durationMs <= 0 && len(stages) > 0 {
+		durationMs = stagesDurationMs(stages)
+	}
 	hasDuration := durationMs > 0
 	hasIterations := iterationsPtr != nil && *iterationsPtr > 0
 	iterations := int64(10)
@@ -140,6 +201,28 @@ func NormalizeConfig(cfg Config) (NormalizedConfig, error) {
 		backoffStep = 1
 	}
 
+	assertEnabled := parseBoolGo(cfg.Assert)
+	assertSample, hasSample := parseFailureRateThresholdGo(cfg.AssertSample)
+	if !hasSample || assertSample <= 0 {
+		assertSample = 1
+	}
+
+	dataMode, err := normalizeDataMode(cfg.DataMode)
+	if err != nil {
+		return NormalizedConfig{}, err
+	}
+
+	thresholds, err := ParseThresholds(cfg.Thresholds)
+	if err != nil {
+		return NormalizedConfig{}, err
+	}
+
+	if hasArrival {
+		// The arrival rate sets the pace, so neither user ramping nor a
+		// request limiter applies.
+		hasSpawn, hasRamp, hasRps, adaptiveEnabled = false, false, false, false
+	}
+
 	return NormalizedConfig{
 		Iterations:        iterations,
 		HasIterations:     hasIterations,
@@ -166,6 +249,22 @@ func NormalizeConfig(cfg Config) (NormalizedConfig, error) {
 		AdaptiveStableSec:        adaptiveStableSec,
 		AdaptiveCooldownMs:       adaptiveCooldownMs,
 		AdaptiveBackoffStepUsers: int64(backoffStep),
+
+		AssertEnabled:    assertEnabled,
+		AssertSampleRate: assertSample,
+
+		DataPath: strings.TrimSpace(cfg.Data),
+		DataRows: cfg.DataRows,
+		DataMode: dataMode,
+
+		ArrivalRate: arrivalRate,
+		Stages:      stages,
+		HasArrival:  hasArrival,
+
+		Thresholds:      thresholds,
+		ThresholdsAbort: len(thresholds) > 0 && parseBoolGo(cfg.ThresholdsAbort),
+
+		StartAtMs: maxInt64(0, cfg.StartAt),
 	}, nil
 }
 
@@ -227,6 +326,22 @@ func parseFailureRateThresholdGo(v any) (float64, bool) {
 	}
 }
 
+func parseRateAnyGo(v any) (float64, error) {
+	switch t := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return ParseRate(strconv.FormatFloat(t, 'f', -1, 64))
+	case string:
+		if strings.TrimSpace(t) == "" {
+			return 0, nil
+		}
+		return ParseRate(t)
+	default:
+		return 0, fmt.Errorf("invalid rate %v", v)
+	}
+}
+
 func parseSecondsGo(v any, fallback int64) int64 {
 	if v == nil {
 		return fallback
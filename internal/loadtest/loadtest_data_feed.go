@@ -0,0 +1,172 @@
+package loadtest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Data feed modes. Sequential walks the rows in order and wraps around,
+// random picks any row each time, and unique hands every row out once so
+// users stop when the data runs out.
+const (
+	DataModeSequential = "sequential"
+	DataModeRandom     = "random"
+	DataModeUnique     = "unique"
+)
+
+// DataFeed hands rows of a CSV or JSON file to virtual users, one row per
+// iteration, to bind into {{column}} placeholders. It is safe for
+// concurrent use.
+type DataFeed struct {
+	rows []map[string]string
+	mode string
+	next atomic.Int64
+}
+
+func normalizeDataMode(mode string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", DataModeSequential:
+		return DataModeSequential, nil
+	case DataModeRandom:
+		return DataModeRandom, nil
+	case DataModeUnique:
+		return DataModeUnique, nil
+	default:
+		return "", fmt.Errorf("invalid data mode %q: use sequential, random or unique", mode)
+	}
+}
+
+// LoadDataFeed reads a .csv file with a header row, or a .json file holding
+// an array of objects.
+func LoadDataFeed(path, mode string) (*DataFeed, error) {
+	rows, err := ReadDataRows(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewDataFeed(rows, mode)
+}
+
+// ReadDataRows reads the rows of a load test data file the way LoadDataFeed
+// does, so a distributed test can split them between workers.
+func ReadDataRows(path string) ([]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read load test data: %w", err)
+	}
+	var rows []map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = parseCSVRows(data)
+	case ".json":
+		rows, err = parseJSONRows(data)
+	default:
+		return nil, fmt.Errorf("load test data %s: use a .csv or .json file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load test data %s: %w", path, err)
+	}
+	return rows, nil
+}
+
+func NewDataFeed(rows []map[string]string, mode string) (*DataFeed, error) {
+	mode, err := normalizeDataMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("load test data has no rows")
+	}
+	return &DataFeed{rows: rows, mode: mode}, nil
+}
+
+func (f *DataFeed) Len() int {
+	return len(f.rows)
+}
+
+// Next returns the row for one iteration. It reports false once a unique
+// feed has handed out every row.
+func (f *DataFeed) Next(r *rand.Rand) (map[string]string, bool) {
+	switch f.mode {
+	case DataModeRandom:
+		return f.rows[r.Intn(len(f.rows))], true
+	case DataModeUnique:
+		n := f.next.Add(1) - 1
+		if n >= int64(len(f.rows)) {
+			return nil, false
+		}
+		return f.rows[n], true
+	default:
+		n := f.next.Add(1) - 1
+		return f.rows[n%int64(len(f.rows))], true
+	}
+}
+
+func parseCSVRows(data []byte) ([]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	for i, name := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+	}
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(record) && name != "" {
+				row[name] = record[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func parseJSONRows(data []byte) ([]map[string]string, error) {
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("expected an array of objects: %w", err)
+	}
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		row := make(map[string]string, len(item))
+		for k, v := range item {
+			row[k] = dataValueString(v)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// dataValueString renders a JSON value the way it would be written into a
+// request: strings bare, numbers without exponents, anything else as JSON.
+func dataValueString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	default:
+		data, _ := json.Marshal(t)
+		return string(data)
+	}
+}
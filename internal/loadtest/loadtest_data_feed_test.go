@@ -0,0 +1,72 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadDataFeedModes(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "users.csv")
+	if err := os.WriteFile(csvPath, []byte("email,password\na@x.test,one\nb@x.test,\"two, quoted\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "users.json")
+	if err := os.WriteFile(jsonPath, []byte(`[{"id":7,"admin":true},{"id":1e3,"tags":["a"]}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(1))
+
+	feed, err := LoadDataFeed(csvPath, "")
+	if err != nil {
+		t.Fatalf("LoadDataFeed(csv): %v", err)
+	}
+	var emails []string
+	for i := 0; i < 3; i++ {
+		row, ok := feed.Next(r)
+		if !ok {
+			t.Fatalf("sequential feed ran out at %d", i)
+		}
+		emails = append(emails, row["email"])
+	}
+	if emails[0] != "a@x.test" || emails[1] != "b@x.test" || emails[2] != "a@x.test" {
+		t.Fatalf("sequential rows = %v, want them in order and wrapping", emails)
+	}
+
+	feed, err = LoadDataFeed(jsonPath, "unique")
+	if err != nil {
+		t.Fatalf("LoadDataFeed(json): %v", err)
+	}
+	first, _ := feed.Next(r)
+	second, _ := feed.Next(r)
+	if first["id"] != "7" || first["admin"] != "true" || second["id"] != "1000" || second["tags"] != `["a"]` {
+		t.Fatalf("json rows = %v %v", first, second)
+	}
+	if _, ok := feed.Next(r); ok {
+		t.Fatal("unique feed should stop after every row was used")
+	}
+
+	if _, err := LoadDataFeed(csvPath, "shuffle"); err == nil {
+		t.Fatal("expected an unknown mode to be rejected")
+	}
+	if _, err := NormalizeConfig(Config{DataMode: "bogus"}); err == nil {
+		t.Fatal("expected NormalizeConfig to reject an unknown data mode")
+	}
+}
+
+func TestNormalizeConfig_KeepsInlineDataRows(t *testing.T) {
+	var cfg Config
+	if err := json.Unmarshal([]byte(`{"dataRows":[{"email":"a@x.test"}],"dataMode":"unique"}`), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	norm, err := NormalizeConfig(cfg)
+	if err != nil {
+		t.Fatalf("NormalizeConfig: %v", err)
+	}
+	if len(norm.DataRows) != 1 || norm.DataRows[0]["email"] != "a@x.test" || norm.DataMode != DataModeUnique {
+		t.Fatalf("normalized data = %v %q", norm.DataRows, norm.DataMode)
+	}
+}
@@ -0,0 +1,161 @@
+package loadtest
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// histogramSubBucketBits sets the histogram precision: values below
+// 1<<histogramSubBucketBits ms get a bucket each, and larger values share
+// buckets no wider than 0.2% of the value, like an HDR histogram with three
+// significant digits.
+const histogramSubBucketBits = 10
+
+// Histogram counts millisecond latencies in log-linear buckets. Memory grows
+// with the number of distinct buckets hit, not with the number of samples,
+// and histograms merge by adding counts. It is not safe for concurrent use.
+type Histogram struct {
+	counts map[int]int64
+	count  int64
+	sum    int64
+	min    int64
+	max    int64
+}
+
+// HistogramSnapshot is the serialized form of a Histogram. Buckets are
+// [value, count] pairs in ascending value order, where value is the middle of
+// the bucket.
+type HistogramSnapshot struct {
+	Count   int64      `json:"count"`
+	Sum     int64      `json:"sum"`
+	Min     int64      `json:"min"`
+	Max     int64      `json:"max"`
+	Buckets [][2]int64 `json:"buckets,omitempty"`
+}
+
+func NewHistogram() *Histogram {
+	return &Histogram{counts: map[int]int64{}}
+}
+
+func histogramBucket(v int64) int {
+	if v < 1<<histogramSubBucketBits {
+		return int(v)
+	}
+	shift := bits.Len64(uint64(v)) - histogramSubBucketBits
+	half := 1 << (histogramSubBucketBits - 1)
+	return 1<<histogramSubBucketBits + (shift-1)*half + int(v>>shift) - half
+}
+
+// histogramValue is the middle of a bucket, which is the value itself for
+// the exact buckets.
+func histogramValue(bucket int) int64 {
+	if bucket < 1<<histogramSubBucketBits {
+		return int64(bucket)
+	}
+	half := 1 << (histogramSubBucketBits - 1)
+	k := bucket - 1<<histogramSubBucketBits
+	shift := k/half + 1
+	low := int64(k%half+half) << shift
+	return low + int64(1)<<shift/2
+}
+
+func (h *Histogram) Record(v int64) {
+	if v < 0 {
+		v = 0
+	}
+	if h.count == 0 || v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+	h.counts[histogramBucket(v)]++
+	h.count++
+	h.sum += v
+}
+
+// Merge adds every sample of other into h.
+func (h *Histogram) Merge(other *Histogram) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	if h.count == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	for bucket, n := range other.counts {
+		h.counts[bucket] += n
+	}
+	h.count += other.count
+	h.sum += other.sum
+}
+
+func (h *Histogram) Count() int64 { return h.count }
+func (h *Histogram) Min() int64   { return h.min }
+func (h *Histogram) Max() int64   { return h.max }
+
+func (h *Histogram) Mean() int64 {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / h.count
+}
+
+// Percentile uses the nearest-rank method, clamped to the recorded min and
+// max so the extremes are exact.
+func (h *Histogram) Percentile(p float64) int64 {
+	if h.count == 0 {
+		return 0
+	}
+	rank := int64(math.Ceil(p / 100 * float64(h.count)))
+	if rank < 1 {
+		rank = 1
+	}
+	buckets := h.sortedBuckets()
+	var seen int64
+	for _, bucket := range buckets {
+		seen += h.counts[bucket]
+		if seen >= rank {
+			v := histogramValue(bucket)
+			if v < h.min {
+				v = h.min
+			}
+			if v > h.max {
+				v = h.max
+			}
+			return v
+		}
+	}
+	return h.max
+}
+
+func (h *Histogram) sortedBuckets() []int {
+	buckets := make([]int, 0, len(h.counts))
+	for bucket := range h.counts {
+		buckets = append(buckets, bucket)
+	}
+	sort.Ints(buckets)
+	return buckets
+}
+
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	snap := HistogramSnapshot{Count: h.count, Sum: h.sum, Min: h.min, Max: h.max}
+	for _, bucket := range h.sortedBuckets() {
+		snap.Buckets = append(snap.Buckets, [2]int64{histogramValue(bucket), h.counts[bucket]})
+	}
+	return snap
+}
+
+// HistogramFromSnapshot rebuilds a histogram, for example to merge results
+// from several machines.
+func HistogramFromSnapshot(snap HistogramSnapshot) *Histogram {
+	h := NewHistogram()
+	for _, pair := range snap.Buckets {
+		h.counts[histogramBucket(pair[0])] += pair[1]
+	}
+	h.count, h.sum, h.min, h.max = snap.Count, snap.Sum, snap.Min, snap.Max
+	return h
+}
@@ -0,0 +1,74 @@
+package loadtest
+
+import (
+	"math"
+	"sort"
+	"testing"
+)
+
+func TestHistogramPercentilesStayWithinPrecision(t *testing.T) {
+	h := NewHistogram()
+	var values []int64
+	for i := int64(1); i <= 20000; i++ {
+		v := (i * 7919) % 30000
+		values = append(values, v)
+		h.Record(v)
+	}
+	sort.Slice(values, func(a, b int) bool { return values[a] < values[b] })
+
+	for _, p := range []float64{50, 90, 95, 99, 99.9} {
+		want := values[int(math.Ceil(p/100*float64(len(values))))-1]
+		got := h.Percentile(p)
+		if diff := math.Abs(float64(got - want)); diff > float64(want)*0.002+1 {
+			t.Errorf("p%v = %d, want %d within 0.2%%", p, got, want)
+		}
+	}
+	if h.Min() != values[0] || h.Max() != values[len(values)-1] {
+		t.Errorf("min/max = %d/%d", h.Min(), h.Max())
+	}
+
+	small := NewHistogram()
+	for _, v := range []int64{3, 1, 2, 900} {
+		small.Record(v)
+	}
+	if small.Percentile(50) != 2 || small.Percentile(75) != 3 || small.Percentile(100) != 900 {
+		t.Errorf("values below 1024ms must be exact, got p50=%d p75=%d", small.Percentile(50), small.Percentile(75))
+	}
+}
+
+func TestHistogramMergeAndSnapshotRoundTrip(t *testing.T) {
+	a, b := NewHistogram(), NewHistogram()
+	for i := int64(0); i < 100; i++ {
+		a.Record(i)
+		b.Record(5000 + i*10)
+	}
+	a.Merge(b)
+	if a.Count() != 200 || a.Min() != 0 || a.Max() != 5990 {
+		t.Fatalf("merged count/min/max = %d/%d/%d", a.Count(), a.Min(), a.Max())
+	}
+	restored := HistogramFromSnapshot(a.Snapshot())
+	for _, p := range []float64{10, 50, 99} {
+		if restored.Percentile(p) != a.Percentile(p) {
+			t.Errorf("p%v after round trip = %d, want %d", p, restored.Percentile(p), a.Percentile(p))
+		}
+	}
+	if restored.Mean() != a.Mean() {
+		t.Errorf("mean after round trip = %d, want %d", restored.Mean(), a.Mean())
+	}
+}
+
+func TestLatencyRecorderBoundsSample(t *testing.T) {
+	rec := NewLatencyRecorder()
+	if rec.Summary() != nil {
+		t.Fatal("an empty recorder should have no summary")
+	}
+	for i := 0; i < maxLatencySample+500; i++ {
+		rec.Record(int64(i%3) * 100)
+	}
+	if got := len(rec.Sample()); got != maxLatencySample {
+		t.Fatalf("sample size = %d, want %d", got, maxLatencySample)
+	}
+	if summary := rec.Summary(); summary.Count != maxLatencySample+500 || summary.MaxMs != 200 {
+		t.Fatalf("summary = %+v", summary)
+	}
+}
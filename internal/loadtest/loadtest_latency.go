@@ -0,0 +1,89 @@
+package loadtest
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// maxLatencySample bounds the uniform sample of raw response times kept for
+// charts that plot individual samples.
+const maxLatencySample = 10000
+
+// LatencySummary is the latency distribution of a whole load test.
+type LatencySummary struct {
+	Count     int64             `json:"count"`
+	AvgMs     int64             `json:"avg"`
+	MinMs     int64             `json:"min"`
+	P50Ms     int64             `json:"p50"`
+	P90Ms     int64             `json:"p90"`
+	P95Ms     int64             `json:"p95"`
+	P99Ms     int64             `json:"p99"`
+	MaxMs     int64             `json:"max"`
+	Histogram HistogramSnapshot `json:"histogram"`
+}
+
+// LatencyRecorder collects response times into a histogram for the whole
+// run, so memory stays bounded however long the test runs. It is safe for
+// concurrent use.
+type LatencyRecorder struct {
+	mu     sync.Mutex
+	total  *Histogram
+	sample []int64
+	rng    *rand.Rand
+}
+
+func NewLatencyRecorder() *LatencyRecorder {
+	return &LatencyRecorder{
+		total: NewHistogram(),
+		rng:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (l *LatencyRecorder) Record(ms int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total.Record(ms)
+
+	// Reservoir sampling keeps every sample equally likely to be kept.
+	if len(l.sample) < maxLatencySample {
+		l.sample = append(l.sample, ms)
+	} else if i := l.rng.Int63n(l.total.Count()); i < maxLatencySample {
+		l.sample[i] = ms
+	}
+}
+
+// Summary returns the distribution of every response so far, or nil when
+// there are none.
+func (l *LatencyRecorder) Summary() *LatencySummary {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.total.Count() == 0 {
+		return nil
+	}
+	h := l.total
+	return &LatencySummary{
+		Count:     h.Count(),
+		AvgMs:     h.Mean(),
+		MinMs:     h.Min(),
+		P50Ms:     h.Percentile(50),
+		P90Ms:     h.Percentile(90),
+		P95Ms:     h.Percentile(95),
+		P99Ms:     h.Percentile(99),
+		MaxMs:     h.Max(),
+		Histogram: h.Snapshot(),
+	}
+}
+
+// Sample returns a copy of the uniform sample of raw response times.
+func (l *LatencyRecorder) Sample() []int64 {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]int64(nil), l.sample...)
+}
@@ -0,0 +1,115 @@
+package loadtest
+
+import (
+	"strconv"
+	"sync"
+)
+
+// Step is one request of a scenario load test. Every virtual user runs the
+// steps in order; later steps can use {{key}} variables set by earlier
+// post-scripts with setVar, and {{requestN.response...}} references to the
+// responses of earlier steps.
+type Step struct {
+	Name        string   `json:"name"`
+	Method      string   `json:"method"`
+	URL         string   `json:"url"`
+	HeadersJSON string   `json:"headersJson"`
+	Body        string   `json:"body"`
+	PostScript  string   `json:"postScript,omitempty"`
+	Assertions  []string `json:"assertions,omitempty"`
+}
+
+// StepSummary is the outcome of one scenario step.
+type StepSummary struct {
+	Name                string           `json:"name"`
+	Method              string           `json:"method"`
+	URL                 string           `json:"url"`
+	Total               int64            `json:"total"`
+	Successful          int64            `json:"successful"`
+	Failed              int64            `json:"failed"`
+	FailureStatusCounts map[string]int64 `json:"failureStatusCounts,omitempty"`
+	AvgMs               int64            `json:"avg"`
+	MinMs               int64            `json:"min"`
+	P50Ms               int64            `json:"p50"`
+	P95Ms               int64            `json:"p95"`
+	P99Ms               int64            `json:"p99"`
+	MaxMs               int64            `json:"max"`
+	// Histogram lets steps from several workers be merged exactly.
+	Histogram *HistogramSnapshot `json:"histogram,omitempty"`
+}
+
+// StepStats collects per-step results while a scenario runs. It is safe for
+// concurrent use by the virtual users.
+type StepStats struct {
+	mu    sync.Mutex
+	steps []stepStat
+}
+
+type stepStat struct {
+	summary StepSummary
+	times   *Histogram
+}
+
+func NewStepStats(steps []Step) *StepStats {
+	stats := &StepStats{steps: make([]stepStat, len(steps))}
+	for i, step := range steps {
+		stats.steps[i].summary = StepSummary{Name: step.Name, Method: step.Method, URL: step.URL}
+		stats.steps[i].times = NewHistogram()
+	}
+	return stats
+}
+
+// Record counts one response of step index. Status failures are also
+// tallied by status code; failed covers them and failed checks.
+func (s *StepStats) Record(index int, timingMs int64, status int, statusFailure, failed bool) {
+	if s == nil || index < 0 || index >= len(s.steps) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	step := &s.steps[index]
+	step.summary.Total++
+	step.times.Record(timingMs)
+	if !failed {
+		step.summary.Successful++
+		return
+	}
+	step.summary.Failed++
+	if statusFailure {
+		if step.summary.FailureStatusCounts == nil {
+			step.summary.FailureStatusCounts = map[string]int64{}
+		}
+		step.summary.FailureStatusCounts[strconv.Itoa(status)]++
+	}
+}
+
+// Summaries returns a snapshot of every step with its latency percentiles.
+func (s *StepStats) Summaries() []StepSummary {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]StepSummary, len(s.steps))
+	for i, step := range s.steps {
+		summary := step.summary
+		if step.summary.FailureStatusCounts != nil {
+			summary.FailureStatusCounts = make(map[string]int64, len(step.summary.FailureStatusCounts))
+			for k, v := range step.summary.FailureStatusCounts {
+				summary.FailureStatusCounts[k] = v
+			}
+		}
+		if h := step.times; h.Count() > 0 {
+			summary.AvgMs = h.Mean()
+			summary.MinMs = h.Min()
+			summary.MaxMs = h.Max()
+			summary.P50Ms = h.Percentile(50)
+			summary.P95Ms = h.Percentile(95)
+			summary.P99Ms = h.Percentile(99)
+			snapshot := h.Snapshot()
+			summary.Histogram = &snapshot
+		}
+		out[i] = summary
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package loadtest
+
+import "testing"
+
+func TestStepStatsSummaries(t *testing.T) {
+	stats := NewStepStats([]Step{
+		{Name: "login", Method: "POST", URL: "/login"},
+		{Name: "list", Method: "GET", URL: "/items"},
+	})
+	for _, ms := range []int64{30, 10, 20, 40} {
+		stats.Record(0, ms, 200, false, false)
+	}
+	stats.Record(1, 5, 503, true, true)
+	stats.Record(1, 7, 200, false, true)
+	stats.Record(2, 1, 200, false, false) // out of range: ignored
+
+	got := stats.Summaries()
+	if len(got) != 2 {
+		t.Fatalf("len(Summaries()) = %d, want 2", len(got))
+	}
+	login := got[0]
+	if login.Name != "login" || login.Total != 4 || login.Successful != 4 || login.Failed != 0 {
+		t.Fatalf("login = %+v", login)
+	}
+	if login.MinMs != 10 || login.MaxMs != 40 || login.AvgMs != 25 || login.P50Ms != 20 || login.P95Ms != 40 {
+		t.Fatalf("login latencies = %+v", login)
+	}
+	list := got[1]
+	if list.Total != 2 || list.Failed != 2 || list.FailureStatusCounts["503"] != 1 || len(list.FailureStatusCounts) != 1 {
+		t.Fatalf("list = %+v", list)
+	}
+
+	got[1].FailureStatusCounts["503"] = 99
+	if stats.Summaries()[1].FailureStatusCounts["503"] != 1 {
+		t.Fatal("Summaries() shares its maps with the collector")
+	}
+}
@@ -0,0 +1,196 @@
+package loadtest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// minThresholdSamples is how many responses a run needs before thresholds
+// are checked while it is still going, so a slow first request can't abort
+// it.
+const minThresholdSamples = 100
+
+// Threshold is one service level objective such as p95<300ms. Limits are in
+// milliseconds for latency metrics, a fraction for errorRate and requests per
+// second for rps.
+type Threshold struct {
+	Raw    string
+	Metric string
+	Op     string
+	Limit  float64
+}
+
+// ThresholdResult reports one threshold against the numbers of a run.
+type ThresholdResult struct {
+	Threshold string  `json:"threshold"`
+	Metric    string  `json:"metric"`
+	Limit     float64 `json:"limit"`
+	Actual    float64 `json:"actual"`
+	Passed    bool    `json:"passed"`
+}
+
+// ThresholdMetrics are the numbers of a run that thresholds are checked
+// against.
+type ThresholdMetrics struct {
+	Requests   int64
+	Failed     int64
+	DurationMs int64
+	Latency    *LatencySummary
+}
+
+var thresholdRx = regexp.MustCompile(`^([A-Za-z]\w*)\s*(<=|>=|<|>)\s*(\S.*)$`)
+
+var thresholdMetrics = map[string]string{
+	"p50": "p50", "median": "p50", "p90": "p90", "p95": "p95", "p99": "p99",
+	"avg": "avg", "mean": "avg", "min": "min", "max": "max",
+	"errorrate": "errorRate", "failrate": "errorRate", "failurerate": "errorRate",
+	"rps": "rps", "requests": "requests",
+}
+
+// ParseThresholds reads a list such as "p95<300ms, p99<1s, errorRate<1%,
+// rps>150".
+func ParseThresholds(raw string) ([]Threshold, error) {
+	var thresholds []Threshold
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		match := thresholdRx.FindStringSubmatch(part)
+		if match == nil {
+			return nil, fmt.Errorf("invalid threshold %q: use metric<value like p95<300ms", part)
+		}
+		metric, ok := thresholdMetrics[strings.ToLower(match[1])]
+		if !ok {
+			return nil, fmt.Errorf("invalid threshold %q: unknown metric %s", part, match[1])
+		}
+		limit, err := parseThresholdLimit(metric, strings.TrimSpace(match[3]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid threshold %q: %w", part, err)
+		}
+		thresholds = append(thresholds, Threshold{Raw: part, Metric: metric, Op: match[2], Limit: limit})
+	}
+	return thresholds, nil
+}
+
+func parseThresholdLimit(metric, raw string) (float64, error) {
+	switch metric {
+	case "errorRate":
+		rate, ok := parseFailureRateThresholdGo(raw)
+		if !ok {
+			return 0, fmt.Errorf("%q is not a rate like 1%% or 0.01", raw)
+		}
+		return rate, nil
+	case "rps":
+		return ParseRate(raw)
+	case "requests":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a request count", raw)
+		}
+		return float64(n), nil
+	default:
+		ms := parseDurationMsGo(raw)
+		if ms <= 0 && raw != "0" {
+			return 0, fmt.Errorf("%q is not a duration like 300ms", raw)
+		}
+		return float64(ms), nil
+	}
+}
+
+func (t Threshold) actual(m ThresholdMetrics) float64 {
+	l := m.Latency
+	if l == nil {
+		l = &LatencySummary{}
+	}
+	switch t.Metric {
+	case "p50":
+		return float64(l.P50Ms)
+	case "p90":
+		return float64(l.P90Ms)
+	case "p95":
+		return float64(l.P95Ms)
+	case "p99":
+		return float64(l.P99Ms)
+	case "avg":
+		return float64(l.AvgMs)
+	case "min":
+		return float64(l.MinMs)
+	case "max":
+		return float64(l.MaxMs)
+	case "errorRate":
+		if m.Requests == 0 {
+			return 0
+		}
+		return float64(m.Failed) / float64(m.Requests)
+	case "rps":
+		if m.DurationMs <= 0 {
+			return 0
+		}
+		return float64(m.Requests) / (float64(m.DurationMs) / 1000)
+	default:
+		return float64(m.Requests)
+	}
+}
+
+func (t Threshold) check(m ThresholdMetrics) ThresholdResult {
+	actual := t.actual(m)
+	var passed bool
+	switch t.Op {
+	case "<":
+		passed = actual < t.Limit
+	case "<=":
+		passed = actual <= t.Limit
+	case ">":
+		passed = actual > t.Limit
+	default:
+		passed = actual >= t.Limit
+	}
+	return ThresholdResult{Threshold: t.Raw, Metric: t.Metric, Limit: t.Limit, Actual: actual, Passed: passed}
+}
+
+// EvaluateThresholds checks every threshold against a finished run.
+func EvaluateThresholds(thresholds []Threshold, m ThresholdMetrics) []ThresholdResult {
+	if len(thresholds) == 0 {
+		return nil
+	}
+	results := make([]ThresholdResult, len(thresholds))
+	for i, t := range thresholds {
+		results[i] = t.check(m)
+	}
+	return results
+}
+
+// CrossedThreshold returns the first upper limit a running test has already
+// broken. Lower limits such as rps>150 are left for the end, since a run
+// still ramping up hasn't had its chance to meet them.
+func CrossedThreshold(thresholds []Threshold, m ThresholdMetrics) (ThresholdResult, bool) {
+	if m.Requests < minThresholdSamples {
+		return ThresholdResult{}, false
+	}
+	for _, t := range thresholds {
+		if t.Op != "<" && t.Op != "<=" {
+			continue
+		}
+		if result := t.check(m); !result.Passed {
+			return result, true
+		}
+	}
+	return ThresholdResult{}, false
+}
+
+// FormatThresholdValue renders a limit or actual value in the metric's unit.
+func FormatThresholdValue(metric string, v float64) string {
+	switch metric {
+	case "errorRate":
+		return strconv.FormatFloat(v*100, 'f', 2, 64) + "%"
+	case "rps":
+		return strconv.FormatFloat(v, 'f', 1, 64) + "/s"
+	case "requests":
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	default:
+		return strconv.FormatFloat(v, 'f', 0, 64) + "ms"
+	}
+}
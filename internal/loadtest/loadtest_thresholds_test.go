@@ -0,0 +1,69 @@
+package loadtest
+
+import "testing"
+
+func TestParseThresholds(t *testing.T) {
+	thresholds, err := ParseThresholds("p95<300ms, p99 <= 1s, errorRate<1%, rps>150, requests>=1000")
+	if err != nil {
+		t.Fatalf("ParseThresholds: %v", err)
+	}
+	want := []Threshold{
+		{Raw: "p95<300ms", Metric: "p95", Op: "<", Limit: 300},
+		{Raw: "p99 <= 1s", Metric: "p99", Op: "<=", Limit: 1000},
+		{Raw: "errorRate<1%", Metric: "errorRate", Op: "<", Limit: 0.01},
+		{Raw: "rps>150", Metric: "rps", Op: ">", Limit: 150},
+		{Raw: "requests>=1000", Metric: "requests", Op: ">=", Limit: 1000},
+	}
+	if len(thresholds) != len(want) {
+		t.Fatalf("thresholds = %+v", thresholds)
+	}
+	for i := range want {
+		if thresholds[i] != want[i] {
+			t.Errorf("threshold %d = %+v, want %+v", i, thresholds[i], want[i])
+		}
+	}
+
+	for _, raw := range []string{"p95", "p42<1s", "p95<soon", "errorRate<lots", "rps>fast"} {
+		if _, err := ParseThresholds(raw); err == nil {
+			t.Errorf("ParseThresholds(%q) should fail", raw)
+		}
+	}
+}
+
+func TestEvaluateThresholds(t *testing.T) {
+	thresholds, _ := ParseThresholds("p95<300ms, errorRate<1%, rps>150")
+	results := EvaluateThresholds(thresholds, ThresholdMetrics{
+		Requests:   1000,
+		Failed:     20,
+		DurationMs: 5000,
+		Latency:    &LatencySummary{P95Ms: 250},
+	})
+	if len(results) != 3 {
+		t.Fatalf("results = %+v", results)
+	}
+	if !results[0].Passed || results[0].Actual != 250 {
+		t.Errorf("p95 = %+v", results[0])
+	}
+	if results[1].Passed || results[1].Actual != 0.02 {
+		t.Errorf("errorRate = %+v", results[1])
+	}
+	if !results[2].Passed || results[2].Actual != 200 {
+		t.Errorf("rps = %+v", results[2])
+	}
+	if got := FormatThresholdValue("errorRate", results[1].Actual); got != "2.00%" {
+		t.Errorf("FormatThresholdValue = %q", got)
+	}
+}
+
+func TestCrossedThreshold_WaitsForSamplesAndSkipsLowerLimits(t *testing.T) {
+	thresholds, _ := ParseThresholds("rps>1000, p95<300ms")
+	slow := ThresholdMetrics{Requests: 50, DurationMs: 1000, Latency: &LatencySummary{P95Ms: 900}}
+	if _, ok := CrossedThreshold(thresholds, slow); ok {
+		t.Fatal("crossed with too few samples")
+	}
+	slow.Requests = minThresholdSamples
+	crossed, ok := CrossedThreshold(thresholds, slow)
+	if !ok || crossed.Threshold != "p95<300ms" {
+		t.Fatalf("crossed = %+v, %v; want only the upper p95 limit", crossed, ok)
+	}
+}
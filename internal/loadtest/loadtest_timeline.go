@@ -0,0 +1,122 @@
+package loadtest
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SecondWindow is what happened during one second of a load test, counted
+// from its start. Requests is the number of responses received in the
+// window, which is the request rate for every full second.
+type SecondWindow struct {
+	Second       int64            `json:"second"`
+	StartMs      int64            `json:"startTime"`
+	ActiveUsers  int64            `json:"activeUsers"`
+	Requests     int64            `json:"requests"`
+	Failed       int64            `json:"failed"`
+	ErrorRate    float64          `json:"errorRate"`
+	P50Ms        int64            `json:"p50"`
+	P95Ms        int64            `json:"p95"`
+	P99Ms        int64            `json:"p99"`
+	MaxMs        int64            `json:"max"`
+	StatusCounts map[string]int64 `json:"statusCounts,omitempty"`
+	// Histogram lets windows from several workers be merged exactly.
+	Histogram *HistogramSnapshot `json:"histogram,omitempty"`
+}
+
+// Timeline records a load test in one-second windows so ramp-up behaviour
+// can be graphed. It is safe for concurrent use.
+type Timeline struct {
+	mu      sync.Mutex
+	start   time.Time
+	windows []*timelineWindow
+}
+
+type timelineWindow struct {
+	latency  *Histogram
+	failed   int64
+	users    int64
+	statuses map[string]int64
+}
+
+func NewTimeline(start time.Time) *Timeline {
+	return &Timeline{start: start}
+}
+
+// window returns the window holding at, creating it and any gap before it.
+// The caller holds t.mu.
+func (t *Timeline) window(at time.Time) *timelineWindow {
+	second := int(at.Sub(t.start) / time.Second)
+	if second < 0 {
+		second = 0
+	}
+	for len(t.windows) <= second {
+		t.windows = append(t.windows, nil)
+	}
+	if t.windows[second] == nil {
+		t.windows[second] = &timelineWindow{latency: NewHistogram(), statuses: map[string]int64{}}
+	}
+	return t.windows[second]
+}
+
+// Record counts one response received at at. Status 0 stands for a
+// transport error, as in the failure breakdown.
+func (t *Timeline) Record(at time.Time, ms int64, status int, failed bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w := t.window(at)
+	w.latency.Record(ms)
+	w.statuses[strconv.Itoa(status)]++
+	if failed {
+		w.failed++
+	}
+}
+
+// ObserveUsers notes the number of active users at at; a window reports the
+// most it saw.
+func (t *Timeline) ObserveUsers(at time.Time, users int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if w := t.window(at); users > w.users {
+		w.users = users
+	}
+}
+
+// Windows returns every second up to the last one with activity. Seconds
+// with no responses and no user observations are left out.
+func (t *Timeline) Windows() []SecondWindow {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []SecondWindow
+	for i, w := range t.windows {
+		if w == nil {
+			continue
+		}
+		window := SecondWindow{
+			Second:      int64(i),
+			StartMs:     t.start.Add(time.Duration(i) * time.Second).UnixMilli(),
+			ActiveUsers: w.users,
+			Requests:    w.latency.Count(),
+			Failed:      w.failed,
+		}
+		if window.Requests > 0 {
+			window.ErrorRate = float64(w.failed) / float64(window.Requests)
+			window.P50Ms = w.latency.Percentile(50)
+			window.P95Ms = w.latency.Percentile(95)
+			window.P99Ms = w.latency.Percentile(99)
+			window.MaxMs = w.latency.Max()
+			snapshot := w.latency.Snapshot()
+			window.Histogram = &snapshot
+			window.StatusCounts = make(map[string]int64, len(w.statuses))
+			for k, v := range w.statuses {
+				window.StatusCounts[k] = v
+			}
+		}
+		out = append(out, window)
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimelineSplitsSeconds(t *testing.T) {
+	start := time.Unix(1000, 0)
+	timeline := NewTimeline(start)
+	timeline.ObserveUsers(start, 2)
+	timeline.ObserveUsers(start.Add(300*time.Millisecond), 5)
+	for i := 0; i < 10; i++ {
+		timeline.Record(start.Add(time.Duration(i)*100*time.Millisecond), int64(10+i), 200, false)
+	}
+	timeline.Record(start.Add(2500*time.Millisecond), 400, 503, true)
+	timeline.Record(start.Add(2600*time.Millisecond), 20, 200, false)
+
+	windows := timeline.Windows()
+	if len(windows) != 2 {
+		t.Fatalf("windows = %+v, want seconds 0 and 2", windows)
+	}
+	first, third := windows[0], windows[1]
+	if first.Second != 0 || first.ActiveUsers != 5 || first.Requests != 10 || first.P50Ms != 14 || first.StatusCounts["200"] != 10 {
+		t.Errorf("first window = %+v", first)
+	}
+	if third.Second != 2 || third.StartMs != 1002000 || third.Failed != 1 || third.ErrorRate != 0.5 || third.StatusCounts["503"] != 1 || third.MaxMs != 400 {
+		t.Errorf("third window = %+v", third)
+	}
+}
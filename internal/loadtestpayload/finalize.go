@@ -18,10 +18,11 @@ func copyStatusCounts(statusMu *sync.Mutex, statusCounts map[string]int64) map[s
 	return countsCopy
 }
 
-func copyResponseTimes(rtMu *sync.Mutex, responseTimes []int64) []int64 {
-	rtMu.Lock()
-	defer rtMu.Unlock()
-	return append([]int64(nil), responseTimes...)
+func copyAssertionCounts(assertMu *sync.Mutex, assertCounts map[string]int64) map[string]int64 {
+	if assertMu == nil || len(assertCounts) == 0 {
+		return nil
+	}
+	return copyStatusCounts(assertMu, assertCounts)
 }
 
 type FinalizeInput struct {
@@ -35,20 +36,52 @@ type FinalizeInput struct {
 	TotalSent         *atomic.Int64
 	OkSent            *atomic.Int64
 	FailedSent        *atomic.Int64
+	AssertionFailures *atomic.Int64
 	Cancelled         bool
 	StatusMu          *sync.Mutex
 	StatusCounts      map[string]int64
-	RtMu              *sync.Mutex
-	ResponseTimes     []int64
+	AssertMu          *sync.Mutex
+	AssertCounts      map[string]int64
+	Latency           *lt.LatencyRecorder
+	Timeline          *lt.Timeline
+	// Steps and CompletedJourneys are nil outside scenario load tests.
+	Steps             *lt.StepStats
+	CompletedJourneys *atomic.Int64
+	// DroppedIterations is nil outside arrival-rate load tests.
+	DroppedIterations *atomic.Int64
+	Thresholds        []lt.Threshold
 }
 
 func BuildResults(in FinalizeInput) Results {
+	var assertionFailures int64
+	if in.AssertionFailures != nil {
+		assertionFailures = in.AssertionFailures.Load()
+	}
+	var completedJourneys int64
+	if in.CompletedJourneys != nil {
+		completedJourneys = in.CompletedJourneys.Load()
+	}
+	var droppedIterations int64
+	if in.DroppedIterations != nil {
+		droppedIterations = in.DroppedIterations.Load()
+	}
+	latency := in.Latency.Summary()
+	thresholds := lt.EvaluateThresholds(in.Thresholds, lt.ThresholdMetrics{
+		Requests:   in.TotalSent.Load(),
+		Failed:     in.FailedSent.Load(),
+		DurationMs: in.EndMs - in.StartMs,
+		Latency:    latency,
+	})
 	return Results{
 		TotalRequests:       in.TotalSent.Load(),
 		SuccessfulRequests:  in.OkSent.Load(),
 		FailedRequests:      in.FailedSent.Load(),
 		FailureStatusCounts: copyStatusCounts(in.StatusMu, in.StatusCounts),
-		ResponseTimesMs:     copyResponseTimes(in.RtMu, in.ResponseTimes),
+		AssertionFailures:   assertionFailures,
+		AssertionCounts:     copyAssertionCounts(in.AssertMu, in.AssertCounts),
+		ResponseTimesMs:     in.Latency.Sample(),
+		Latency:             latency,
+		Timeline:            in.Timeline.Windows(),
 		StartTimeMs:         in.StartMs,
 		EndTimeMs:           in.EndMs,
 		Cancelled:           in.Cancelled && in.Ctx.Err() == context.Canceled && !in.Aborted.Load(),
@@ -56,5 +89,9 @@ func BuildResults(in FinalizeInput) Results {
 		AbortReason:         in.AbortReason.Load().(string),
 		PlannedDurationMs:   in.PlannedDurationMs,
 		Adaptive:            in.Adaptive,
+		Steps:               in.Steps.Summaries(),
+		CompletedJourneys:   completedJourneys,
+		DroppedIterations:   droppedIterations,
+		Thresholds:          thresholds,
 	}
 }
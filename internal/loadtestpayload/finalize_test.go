@@ -5,6 +5,7 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	lt "rawrequest/internal/loadtest"
 )
@@ -12,8 +13,12 @@ import (
 func TestBuildResults_CopiesMapsAndSlices(t *testing.T) {
 	var statusMu sync.Mutex
 	statusCounts := map[string]int64{"500": 2}
-	var rtMu sync.Mutex
-	responseTimes := []int64{10, 20}
+	latency := lt.NewLatencyRecorder()
+	latency.Record(10)
+	latency.Record(20)
+	timeline := lt.NewTimeline(time.Unix(0, 0))
+	timeline.Record(time.Unix(0, 0), 10, 200, false)
+	timeline.Record(time.Unix(1, 0), 20, 500, true)
 
 	aborted := atomic.Bool{}
 	abortReason := atomic.Value{}
@@ -30,24 +35,24 @@ func TestBuildResults_CopiesMapsAndSlices(t *testing.T) {
 	adaptive := &lt.AdaptiveSummary{Enabled: false}
 
 	res := BuildResults(FinalizeInput{
-		Ctx:           ctx,
-		StartMs:       1,
-		EndMs:         2,
-		Adaptive:      adaptive,
-		Aborted:       &aborted,
-		AbortReason:   &abortReason,
-		TotalSent:     &total,
-		OkSent:        &ok,
-		FailedSent:    &failed,
-		Cancelled:     false,
-		StatusMu:      &statusMu,
-		StatusCounts:  statusCounts,
-		RtMu:          &rtMu,
-		ResponseTimes: responseTimes,
+		Ctx:          ctx,
+		StartMs:      1,
+		EndMs:        2,
+		Adaptive:     adaptive,
+		Aborted:      &aborted,
+		AbortReason:  &abortReason,
+		TotalSent:    &total,
+		OkSent:       &ok,
+		FailedSent:   &failed,
+		Cancelled:    false,
+		StatusMu:     &statusMu,
+		StatusCounts: statusCounts,
+		Latency:      latency,
+		Timeline:     timeline,
 	})
 
 	statusCounts["500"] = 99
-	responseTimes[0] = 999
+	latency.Record(999)
 
 	if res.FailureStatusCounts["500"] != 2 {
 		t.Fatalf("expected counts copy to be independent")
@@ -55,13 +60,17 @@ func TestBuildResults_CopiesMapsAndSlices(t *testing.T) {
 	if len(res.ResponseTimesMs) != 2 || res.ResponseTimesMs[0] != 10 {
 		t.Fatalf("expected response times copy to be independent")
 	}
+	if res.Latency == nil || res.Latency.Count != 2 || res.Latency.P50Ms != 10 || res.Latency.MaxMs != 20 {
+		t.Fatalf("unexpected latency summary: %+v", res.Latency)
+	}
+	if len(res.Timeline) != 2 || res.Timeline[1].Second != 1 || res.Timeline[1].Failed != 1 {
+		t.Fatalf("unexpected timeline: %+v", res.Timeline)
+	}
 }
 
 func TestBuildResults_CancelledOnlyWhenCtxCanceled(t *testing.T) {
 	var statusMu sync.Mutex
 	statusCounts := map[string]int64{}
-	var rtMu sync.Mutex
-	responseTimes := []int64{}
 
 	aborted := atomic.Bool{}
 	abortReason := atomic.Value{}
@@ -75,20 +84,19 @@ func TestBuildResults_CancelledOnlyWhenCtxCanceled(t *testing.T) {
 	cancel()
 
 	res := BuildResults(FinalizeInput{
-		Ctx:           canceledCtx,
-		StartMs:       1,
-		EndMs:         2,
-		Adaptive:      &lt.AdaptiveSummary{Enabled: false},
-		Aborted:       &aborted,
-		AbortReason:   &abortReason,
-		TotalSent:     &total,
-		OkSent:        &ok,
-		FailedSent:    &failed,
-		Cancelled:     true,
-		StatusMu:      &statusMu,
-		StatusCounts:  statusCounts,
-		RtMu:          &rtMu,
-		ResponseTimes: responseTimes,
+		Ctx:          canceledCtx,
+		StartMs:      1,
+		EndMs:        2,
+		Adaptive:     &lt.AdaptiveSummary{Enabled: false},
+		Aborted:      &aborted,
+		AbortReason:  &abortReason,
+		TotalSent:    &total,
+		OkSent:       &ok,
+		FailedSent:   &failed,
+		Cancelled:    true,
+		StatusMu:     &statusMu,
+		StatusCounts: statusCounts,
+		Latency:      lt.NewLatencyRecorder(),
 	})
 
 	if !res.Cancelled {
@@ -96,20 +104,19 @@ func TestBuildResults_CancelledOnlyWhenCtxCanceled(t *testing.T) {
 	}
 
 	notCanceledRes := BuildResults(FinalizeInput{
-		Ctx:           context.Background(),
-		StartMs:       1,
-		EndMs:         2,
-		Adaptive:      &lt.AdaptiveSummary{Enabled: false},
-		Aborted:       &aborted,
-		AbortReason:   &abortReason,
-		TotalSent:     &total,
-		OkSent:        &ok,
-		FailedSent:    &failed,
-		Cancelled:     true,
-		StatusMu:      &statusMu,
-		StatusCounts:  statusCounts,
-		RtMu:          &rtMu,
-		ResponseTimes: responseTimes,
+		Ctx:          context.Background(),
+		StartMs:      1,
+		EndMs:        2,
+		Adaptive:     &lt.AdaptiveSummary{Enabled: false},
+		Aborted:      &aborted,
+		AbortReason:  &abortReason,
+		TotalSent:    &total,
+		OkSent:       &ok,
+		FailedSent:   &failed,
+		Cancelled:    true,
+		StatusMu:     &statusMu,
+		StatusCounts: statusCounts,
+		Latency:      lt.NewLatencyRecorder(),
 	})
 
 	if notCanceledRes.Cancelled {
@@ -9,6 +9,7 @@ type ProgressInput struct {
 	TotalSent         int64
 	Successful        int64
 	Failed            int64
+	AssertionFailures int64
 	Done              bool
 	Cancelled         bool
 	Aborted           bool
@@ -33,6 +34,7 @@ func BuildProgressPayload(in ProgressInput) ActiveRunProgressPayload {
 		TotalSent:         in.TotalSent,
 		Successful:        in.Successful,
 		Failed:            in.Failed,
+		AssertionFailures: in.AssertionFailures,
 		Done:              in.Done,
 		Cancelled:         in.Cancelled,
 		Aborted:           in.Aborted,
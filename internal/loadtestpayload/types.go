@@ -3,18 +3,33 @@ package loadtestpayload
 import lt "rawrequest/internal/loadtest"
 
 type Results struct {
-	TotalRequests       int64               `json:"totalRequests"`
-	SuccessfulRequests  int64               `json:"successfulRequests"`
-	FailedRequests      int64               `json:"failedRequests"`
-	FailureStatusCounts map[string]int64    `json:"failureStatusCounts"`
-	ResponseTimesMs     []int64             `json:"responseTimes"`
-	StartTimeMs         int64               `json:"startTime"`
-	EndTimeMs           int64               `json:"endTime"`
-	Cancelled           bool                `json:"cancelled,omitempty"`
-	Aborted             bool                `json:"aborted,omitempty"`
-	AbortReason         string              `json:"abortReason,omitempty"`
-	PlannedDurationMs   *int64              `json:"plannedDurationMs,omitempty"`
-	Adaptive            *lt.AdaptiveSummary `json:"adaptive,omitempty"`
+	TotalRequests       int64            `json:"totalRequests"`
+	SuccessfulRequests  int64            `json:"successfulRequests"`
+	FailedRequests      int64            `json:"failedRequests"`
+	FailureStatusCounts map[string]int64 `json:"failureStatusCounts"`
+	AssertionFailures   int64            `json:"assertionFailures,omitempty"`
+	AssertionCounts     map[string]int64 `json:"assertionFailureCounts,omitempty"`
+	// ResponseTimesMs is a uniform sample of at most 10,000 response times
+	// for charts; Latency holds the exact distribution.
+	ResponseTimesMs   []int64             `json:"responseTimes"`
+	Latency           *lt.LatencySummary  `json:"latency,omitempty"`
+	Timeline          []lt.SecondWindow   `json:"timeline,omitempty"`
+	StartTimeMs       int64               `json:"startTime"`
+	EndTimeMs         int64               `json:"endTime"`
+	Cancelled         bool                `json:"cancelled,omitempty"`
+	Aborted           bool                `json:"aborted,omitempty"`
+	AbortReason       string              `json:"abortReason,omitempty"`
+	PlannedDurationMs *int64              `json:"plannedDurationMs,omitempty"`
+	Adaptive          *lt.AdaptiveSummary `json:"adaptive,omitempty"`
+	// Steps and CompletedJourneys are set for scenario load tests: per-step
+	// results, and how many user journeys ran every step successfully.
+	Steps             []lt.StepSummary `json:"steps,omitempty"`
+	CompletedJourneys int64            `json:"completedJourneys,omitempty"`
+	// DroppedIterations counts arrivals of an arrival-rate test that found
+	// every user busy and were skipped.
+	DroppedIterations int64 `json:"droppedIterations,omitempty"`
+	// Thresholds reports every configured threshold as passed or failed.
+	Thresholds []lt.ThresholdResult `json:"thresholds,omitempty"`
 }
 
 type ActiveRunProgressPayload struct {
@@ -27,6 +42,7 @@ type ActiveRunProgressPayload struct {
 	TotalSent         int64  `json:"totalSent,omitempty"`
 	Successful        int64  `json:"successful,omitempty"`
 	Failed            int64  `json:"failed,omitempty"`
+	AssertionFailures int64  `json:"assertionFailures,omitempty"`
 	Done              bool   `json:"done,omitempty"`
 	Cancelled         bool   `json:"cancelled,omitempty"`
 	Aborted           bool   `json:"aborted,omitempty"`
@@ -0,0 +1,233 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AdminPrefix is the reserved path namespace of the admin API. Requests
+// under it never reach mock routes, the proxy or the journal.
+const AdminPrefix = "/__rawrequest/"
+
+// VerifyRequest asks how often matching requests were received. Path may use
+// :param placeholders and an empty Method matches any. Without Times,
+// AtLeast or AtMost the check is "at least once".
+type VerifyRequest struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Times   *int   `json:"times,omitempty"`
+	AtLeast *int   `json:"atLeast,omitempty"`
+	AtMost  *int   `json:"atMost,omitempty"`
+}
+
+// VerifyResult is the outcome of a VerifyRequest.
+type VerifyResult struct {
+	OK       bool           `json:"ok"`
+	Count    int            `json:"count"`
+	Expected string         `json:"expected"`
+	Message  string         `json:"message"`
+	Requests []JournalEntry `json:"requests"`
+}
+
+// Verify checks the journal against req.
+func (s *Server) Verify(req VerifyRequest) VerifyResult {
+	matches := filterJournal(s.Journal(), req.Method, req.Path)
+	count := len(matches)
+	ok := true
+	var expected []string
+	if req.Times != nil {
+		ok = ok && count == *req.Times
+		expected = append(expected, fmt.Sprintf("exactly %d", *req.Times))
+	}
+	if req.AtLeast != nil {
+		ok = ok && count >= *req.AtLeast
+		expected = append(expected, fmt.Sprintf("at least %d", *req.AtLeast))
+	}
+	if req.AtMost != nil {
+		ok = ok && count <= *req.AtMost
+		expected = append(expected, fmt.Sprintf("at most %d", *req.AtMost))
+	}
+	if len(expected) == 0 {
+		ok = count >= 1
+		expected = append(expected, "at least 1")
+	}
+
+	label := strings.TrimSpace(strings.ToUpper(req.Method) + " " + req.Path)
+	if label == "" {
+		label = "any request"
+	}
+	result := VerifyResult{
+		OK:       ok,
+		Count:    count,
+		Expected: strings.Join(expected, " and "),
+		Requests: matches,
+	}
+	result.Message = fmt.Sprintf("%s was called %d time(s), expected %s", label, count, result.Expected)
+	if result.Requests == nil {
+		result.Requests = []JournalEntry{}
+	}
+	return result
+}
+
+// Reset returns the server to its seeded state, so tests can start over:
+// every scenario goes back to "started", the setVar store to
+// Config.Variables and, with a database, every table is dropped and the
+// @mockinit blocks run again. It reports whether the server
+// has a database.
+func (s *Server) Reset() (bool, error) {
+	s.scenarios.reset()
+	s.vars.reset(s.cfg.Variables)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.db == nil {
+		return false, nil
+	}
+	rows, err := s.db.Query(`SELECT type, name FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'`)
+	if err != nil {
+		return true, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var drops []string
+	for rows.Next() {
+		var kind, name string
+		if err := rows.Scan(&kind, &name); err != nil {
+			_ = rows.Close()
+			return true, err
+		}
+		drops = append(drops, fmt.Sprintf(`DROP %s IF EXISTS "%s"`, strings.ToUpper(kind), strings.ReplaceAll(name, `"`, `""`)))
+	}
+	_ = rows.Close()
+
+	_, _ = s.db.Exec(`PRAGMA foreign_keys = OFF`)
+	for _, stmt := range drops {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return true, fmt.Errorf("failed to reset database: %w", err)
+		}
+	}
+	_, _ = s.db.Exec(`PRAGMA foreign_keys = ON`)
+
+	s.logf("info", "mockserver", "[Mock Server] Database reset; running @mockinit again...\n")
+	for _, req := range initRequests(s.cfg.Requests) {
+		s.executeMockInitScript(req)
+	}
+	return true, nil
+}
+
+// serveAdmin handles the admin API:
+//
+//	GET    /__rawrequest/requests  journal, filtered by ?method= and ?path=
+//	DELETE /__rawrequest/requests  clear the journal
+//	POST   /__rawrequest/reset     reset scenarios, variables and the database, rerun @mockinit
+//	GET    /__rawrequest/verify    check call counts (query or JSON body)
+//	GET    /__rawrequest/routes    list the active routes
+//	GET    /__rawrequest/scenarios         current scenario states
+//	PUT    /__rawrequest/scenarios/<name>  set a state from {"state": "..."}
+//	DELETE /__rawrequest/scenarios         move every scenario back to "started"
+func (s *Server) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	endpoint := strings.Trim(strings.TrimPrefix(r.URL.Path, AdminPrefix), "/")
+	switch {
+	case endpoint == "requests" && r.Method == http.MethodGet:
+		q := r.URL.Query()
+		entries := filterJournal(s.Journal(), q.Get("method"), q.Get("path"))
+		if entries == nil {
+			entries = []JournalEntry{}
+		}
+		writeAdminJSON(w, http.StatusOK, entries)
+
+	case endpoint == "requests" && r.Method == http.MethodDelete:
+		cleared := s.journal.clear()
+		s.logf("info", "mockserver", "[Mock Server] Request journal cleared (%d entries)\n", cleared)
+		writeAdminJSON(w, http.StatusOK, map[string]int{"cleared": cleared})
+
+	case endpoint == "reset" && r.Method == http.MethodPost:
+		hasDB, err := s.Reset()
+		if err != nil {
+			writeAdminJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, map[string]bool{"reset": true, "database": hasDB})
+
+	case endpoint == "verify" && (r.Method == http.MethodGet || r.Method == http.MethodPost):
+		req, err := parseVerifyRequest(r)
+		if err != nil {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		result := s.Verify(req)
+		status := http.StatusOK
+		if !result.OK {
+			status = http.StatusExpectationFailed
+			s.logf("warn", "mockserver", "[Mock Server] Verify failed: %s\n", result.Message)
+		}
+		writeAdminJSON(w, status, result)
+
+	case endpoint == "routes" && r.Method == http.MethodGet:
+		routes := []string{}
+		if current := s.routes.Load(); current != nil {
+			for _, route := range *current {
+				routes = append(routes, route.describe())
+			}
+		}
+		writeAdminJSON(w, http.StatusOK, routes)
+
+	case endpoint == "scenarios" && r.Method == http.MethodGet:
+		writeAdminJSON(w, http.StatusOK, s.Scenarios())
+
+	case endpoint == "scenarios" && r.Method == http.MethodDelete:
+		s.scenarios.reset()
+		s.logf("info", "mockserver", "[Mock Server] Scenarios reset to %s\n", ScenarioStarted)
+		writeAdminJSON(w, http.StatusOK, s.Scenarios())
+
+	case strings.HasPrefix(endpoint, "scenarios/") && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		name := strings.TrimPrefix(endpoint, "scenarios/")
+		var payload struct {
+			State string `json:"state"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.State == "" {
+			writeAdminJSON(w, http.StatusBadRequest, map[string]string{"error": `expected {"state": "<state>"}`})
+			return
+		}
+		s.SetScenarioState(name, payload.State)
+		writeAdminJSON(w, http.StatusOK, map[string]string{"scenario": name, "state": payload.State})
+
+	default:
+		writeAdminJSON(w, http.StatusNotFound, map[string]string{"error": "unknown admin endpoint " + r.Method + " " + r.URL.Path})
+	}
+}
+
+// parseVerifyRequest reads a VerifyRequest from a JSON body or from the
+// method, path, times, atLeast and atMost query parameters.
+func parseVerifyRequest(r *http.Request) (VerifyRequest, error) {
+	var req VerifyRequest
+	if r.Method == http.MethodPost && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid JSON: %w", err)
+		}
+		return req, nil
+	}
+	q := r.URL.Query()
+	req.Method = q.Get("method")
+	req.Path = q.Get("path")
+	for name, target := range map[string]**int{"times": &req.Times, "atLeast": &req.AtLeast, "atMost": &req.AtMost} {
+		raw := q.Get(name)
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return req, fmt.Errorf("%s must be a non-negative integer", name)
+		}
+		*target = &n
+	}
+	return req, nil
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(payload)
+}
@@ -0,0 +1,99 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAdminAPIJournalsVerifiesAndResets(t *testing.T) {
+	srv := New(Config{
+		DBPath: filepath.Join(t.TempDir(), "orders.db"),
+		Requests: []MockRequest{
+			{Method: "MOCKINIT", URL: "@mockinit", PreScript: `
+				db.exec("CREATE TABLE orders (id INTEGER PRIMARY KEY, item TEXT)");
+				db.exec("INSERT INTO orders (item) VALUES ('seed')");
+			`},
+			{Name: "createOrder", Method: "POST", URL: "/orders", PreScript: `
+				db.exec("INSERT INTO orders (item) VALUES (?)", JSON.parse(request.body).item);
+				response.status = 201;
+				response.body = { ok: true };
+			`},
+			{Method: "GET", URL: "/orders", PreScript: `response.body = db.query("SELECT item FROM orders ORDER BY id");`},
+		},
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	port := srv.Port()
+
+	postJSON(t, port, "/orders", `{"item":"book"}`)
+	postJSON(t, port, "/orders", `{"item":"pen"}`)
+	getBody(t, port, "/missing")
+
+	journal := getJSON[[]JournalEntry](t, port, "/__rawrequest/requests?method=POST")
+	if len(journal) != 2 || journal[0].Route != "POST /orders" || journal[0].RouteName != "createOrder" ||
+		journal[0].Status != http.StatusCreated || journal[1].Body != `{"item":"pen"}` {
+		t.Fatalf("journal = %+v", journal)
+	}
+	if all := srv.Journal(); len(all) != 3 || all[2].Route != "" || all[2].Status != http.StatusNotFound {
+		t.Fatalf("full journal = %+v", all)
+	}
+
+	if status, result := verify(t, port, "?method=POST&path=/orders&times=2"); status != http.StatusOK || !result.OK || result.Count != 2 {
+		t.Fatalf("verify exactly 2 = %d %+v", status, result)
+	}
+	status, result := verify(t, port, "?method=POST&path=/orders&times=3")
+	if status != http.StatusExpectationFailed || result.OK || !strings.Contains(result.Message, "POST /orders was called 2 time(s), expected exactly 3") {
+		t.Fatalf("verify exactly 3 = %d %+v", status, result)
+	}
+	if got := srv.CountCalls("", "/orders/:id"); got != 0 {
+		t.Fatalf("CountCalls(/orders/:id) = %d, want 0", got)
+	}
+
+	if resp := adminDo(t, http.MethodPost, port, "reset"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("reset returned %d", resp.StatusCode)
+	}
+	if items := getJSON[[]map[string]string](t, port, "/orders"); len(items) != 1 || items[0]["item"] != "seed" {
+		t.Fatalf("orders after reset = %v, want only the seeded row", items)
+	}
+
+	if resp := adminDo(t, http.MethodDelete, port, "requests"); resp.StatusCode != http.StatusOK {
+		t.Fatalf("clear returned %d", resp.StatusCode)
+	}
+	if got := srv.Journal(); len(got) != 0 {
+		t.Fatalf("journal after clear = %+v", got)
+	}
+	if resp := adminDo(t, http.MethodGet, port, "nope"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("unknown admin endpoint returned %d", resp.StatusCode)
+	}
+}
+
+func verify(t *testing.T, port int, query string) (int, VerifyResult) {
+	t.Helper()
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/__rawrequest/verify%s", port, query))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	var result VerifyResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	return resp.StatusCode, result
+}
+
+func adminDo(t *testing.T, method string, port int, endpoint string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(method, fmt.Sprintf("http://127.0.0.1:%d/__rawrequest/%s", port, endpoint), nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, endpoint, err)
+	}
+	resp.Body.Close()
+	return resp
+}
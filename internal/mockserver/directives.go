@@ -0,0 +1,82 @@
+package mockserver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// applyDirectives compiles the mock directives of route.Request onto the
+// route. Directives that do not parse are reported in route.Warnings and
+// otherwise ignored.
+func applyDirectives(route *Route) {
+	for _, directive := range route.Request.Directives {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), " ")
+		arg = strings.TrimSpace(arg)
+		switch strings.ToLower(name) {
+		case "match":
+			matcher, err := compileMatcher(arg)
+			if err != nil {
+				route.warnf("invalid @match %q: %v", arg, err)
+				continue
+			}
+			route.Matchers = append(route.Matchers, matcher)
+		case "priority":
+			priority, err := strconv.Atoi(arg)
+			if err != nil {
+				route.warnf("invalid @priority %q: must be an integer", arg)
+				continue
+			}
+			route.Priority = priority
+		case "status":
+			status, err := parseStatus(arg)
+			if err != nil {
+				route.warnf("invalid @status %q: must be an HTTP status code", arg)
+				continue
+			}
+			route.Status = status
+		case "delay":
+			low, high, err := parseDelay(arg)
+			if err != nil {
+				route.warnf("invalid @delay %q: %v", arg, err)
+				continue
+			}
+			route.Faults.DelayMin, route.Faults.DelayMax = low, high
+		case "fail-rate":
+			rate, status, err := parseFailRate(arg)
+			if err != nil {
+				route.warnf("invalid @fail-rate %q: %v", arg, err)
+				continue
+			}
+			route.Faults.FailRate, route.Faults.FailStatus = rate, status
+		case "drop-connection":
+			route.Faults.DropConnection = true
+		case "chunked-throttle":
+			rate, err := parseThroughput(arg)
+			if err != nil {
+				route.warnf("invalid @chunked-throttle %q: %v", arg, err)
+				continue
+			}
+			route.Faults.BytesPerSecond = rate
+		case "body-file":
+			if arg == "" {
+				route.warnf("@body-file needs a path")
+				continue
+			}
+			route.BodyFile = arg
+		case "scenario":
+			scenario, err := parseScenario(arg)
+			if err != nil {
+				route.warnf("invalid @scenario %q: %v", arg, err)
+				continue
+			}
+			route.Scenario = scenario
+		default:
+			route.warnf("unknown mock directive @%s", name)
+		}
+	}
+}
+
+func (r *Route) warnf(format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,294 @@
+package mockserver
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// throttleTick is how often a throttled response writes its next chunk.
+const throttleTick = 100 * time.Millisecond
+
+// Faults describes the misbehaviour injected into a mock response, set by
+// directives on the block or by its script.
+type Faults struct {
+	// DelayMin and DelayMax bound a random delay before responding.
+	DelayMin time.Duration
+	DelayMax time.Duration
+	// FailRate is the probability (0-1) of answering FailStatus instead of
+	// the mock response.
+	FailRate   float64
+	FailStatus int
+	// DropConnection closes the connection without writing a response.
+	DropConnection bool
+	// BytesPerSecond streams the body in chunks at this rate when positive.
+	BytesPerSecond int
+}
+
+// parseDelay parses "200ms" or a range "200ms..800ms". Bare numbers are
+// milliseconds.
+func parseDelay(raw string) (time.Duration, time.Duration, error) {
+	lo, hi, isRange := strings.Cut(strings.TrimSpace(raw), "..")
+	low, err := parseMillis(lo)
+	if err != nil {
+		return 0, 0, err
+	}
+	high := low
+	if isRange {
+		if high, err = parseMillis(hi); err != nil {
+			return 0, 0, err
+		}
+	}
+	if low < 0 || high < low {
+		return 0, 0, fmt.Errorf("invalid delay range %q", raw)
+	}
+	return low, high, nil
+}
+
+func parseMillis(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if ms, err := strconv.ParseFloat(raw, 64); err == nil {
+		return time.Duration(ms * float64(time.Millisecond)), nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", raw)
+	}
+	return d, nil
+}
+
+// parseFailRate parses "0.1", "10%" or "0.1 status=503". The status
+// defaults to 500.
+func parseFailRate(raw string) (float64, int, error) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("missing rate")
+	}
+	rate, err := parseRate(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	status := http.StatusInternalServerError
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || !strings.EqualFold(key, "status") {
+			return 0, 0, fmt.Errorf("unknown option %q", field)
+		}
+		if status, err = parseStatus(value); err != nil {
+			return 0, 0, err
+		}
+	}
+	return rate, status, nil
+}
+
+func parseRate(raw string) (float64, error) {
+	percent := strings.HasSuffix(raw, "%")
+	rate, err := strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q", raw)
+	}
+	if percent {
+		rate /= 100
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate %q must be between 0 and 1", raw)
+	}
+	return rate, nil
+}
+
+func parseStatus(raw string) (int, error) {
+	status, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil || status < 100 || status > 999 {
+		return 0, fmt.Errorf("invalid HTTP status %q", raw)
+	}
+	return status, nil
+}
+
+// parseThroughput parses a rate such as "1kb/s", "512b/s" or "2mb/s" into
+// bytes per second. The "/s" suffix is optional.
+func parseThroughput(raw string) (int, error) {
+	text := strings.ToLower(strings.TrimSpace(raw))
+	text = strings.TrimSuffix(text, "/s")
+	multiplier := 1
+	for _, unit := range []struct {
+		suffix string
+		size   int
+	}{{"kb", 1024}, {"mb", 1024 * 1024}, {"k", 1024}, {"m", 1024 * 1024}, {"b", 1}} {
+		if strings.HasSuffix(text, unit.suffix) {
+			text = strings.TrimSuffix(text, unit.suffix)
+			multiplier = unit.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid throughput %q", raw)
+	}
+	bytes := int(n * float64(multiplier))
+	if bytes < 1 {
+		bytes = 1
+	}
+	return bytes, nil
+}
+
+// installFaultControls adds response.delay, response.fail,
+// response.dropConnection and response.throttle to a mock script, each
+// overriding the matching directive for this response.
+func installFaultControls(vm *goja.Runtime, respObj *goja.Object, faults *Faults) {
+	_ = respObj.Set("delay", func(call goja.FunctionCall) goja.Value {
+		low, high, err := parseDelay(call.Argument(0).String())
+		if err != nil {
+			panic(vm.NewTypeError("response.delay: %v", err))
+		}
+		faults.DelayMin, faults.DelayMax = low, high
+		return goja.Undefined()
+	})
+	_ = respObj.Set("fail", func(call goja.FunctionCall) goja.Value {
+		status := http.StatusInternalServerError
+		if arg := call.Argument(0); !goja.IsUndefined(arg) {
+			var err error
+			if status, err = parseStatus(arg.String()); err != nil {
+				panic(vm.NewTypeError("response.fail: %v", err))
+			}
+		}
+		rate := 1.0
+		if arg := call.Argument(1); !goja.IsUndefined(arg) {
+			var err error
+			if rate, err = parseRate(arg.String()); err != nil {
+				panic(vm.NewTypeError("response.fail: %v", err))
+			}
+		}
+		faults.FailRate, faults.FailStatus = rate, status
+		return goja.Undefined()
+	})
+	_ = respObj.Set("dropConnection", func(goja.FunctionCall) goja.Value {
+		faults.DropConnection = true
+		return goja.Undefined()
+	})
+	_ = respObj.Set("throttle", func(call goja.FunctionCall) goja.Value {
+		rate, err := parseThroughput(call.Argument(0).String())
+		if err != nil {
+			panic(vm.NewTypeError("response.throttle: %v", err))
+		}
+		faults.BytesPerSecond = rate
+		return goja.Undefined()
+	})
+}
+
+// deliver writes a mock response after applying faults: it waits out the
+// delay, then drops the connection, answers the injected failure, or streams
+// the body at the throttled rate.
+func (s *Server) deliver(w http.ResponseWriter, r *http.Request, faults Faults, status int, body []byte) {
+	if s.injectFaults(w, r, faults) {
+		return
+	}
+	if faults.BytesPerSecond > 0 {
+		writeThrottled(r.Context(), w, status, body, faults.BytesPerSecond)
+		return
+	}
+
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
+
+// deliverChunks is deliver for a response.stream body: after the delay,
+// drop and failure faults it writes the chunks at their own pace.
+func (s *Server) deliverChunks(w http.ResponseWriter, r *http.Request, faults Faults, status int, chunks [][]byte, interval time.Duration) {
+	if s.injectFaults(w, r, faults) {
+		return
+	}
+	writeChunks(r.Context(), w, status, chunks, interval)
+}
+
+// injectFaults waits out the delay, then drops the connection or answers
+// the injected failure. It reports whether the response was handled.
+func (s *Server) injectFaults(w http.ResponseWriter, r *http.Request, faults Faults) bool {
+	if delay := faults.delay(); delay > 0 {
+		if !sleepContext(r.Context(), delay) {
+			return true
+		}
+	}
+
+	if faults.DropConnection {
+		s.logf("warn", "mockserver", "[Mock Server] Dropping connection for %s %s\n", r.Method, r.URL.Path)
+		dropConnection(w)
+		return true
+	}
+
+	if faults.FailRate > 0 && rand.Float64() < faults.FailRate {
+		failStatus := faults.FailStatus
+		if failStatus == 0 {
+			failStatus = http.StatusInternalServerError
+		}
+		s.logf("warn", "mockserver", "[Mock Server] Injected failure %d for %s %s\n", failStatus, r.Method, r.URL.Path)
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(failStatus)
+		_, _ = w.Write([]byte(`{"error": "Injected fault"}`))
+		return true
+	}
+	return false
+}
+
+func (f Faults) delay() time.Duration {
+	if f.DelayMax <= f.DelayMin {
+		return f.DelayMin
+	}
+	return f.DelayMin + time.Duration(rand.Int63n(int64(f.DelayMax-f.DelayMin)+1))
+}
+
+// dropConnection closes the client connection without a response. Writers
+// that cannot be hijacked abort the handler, which net/http also turns into
+// a closed connection.
+func dropConnection(w http.ResponseWriter) {
+	if hj, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hj.Hijack(); err == nil {
+			_ = conn.Close()
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// writeThrottled streams body in chunks sized so that about bytesPerSecond
+// are written each second, flushing after each chunk.
+func writeThrottled(ctx context.Context, w http.ResponseWriter, status int, body []byte, bytesPerSecond int) {
+	chunk := int(float64(bytesPerSecond) * throttleTick.Seconds())
+	if chunk < 1 {
+		chunk = 1
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	flusher, _ := w.(http.Flusher)
+	for len(body) > 0 {
+		n := min(chunk, len(body))
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+		if len(body) > 0 && !sleepContext(ctx, throttleTick) {
+			return
+		}
+	}
+}
+
+// sleepContext waits for d and reports false if ctx ended first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,112 @@
+package mockserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFaultDirectives(t *testing.T) {
+	if low, high, err := parseDelay("200ms..800ms"); err != nil || low != 200*time.Millisecond || high != 800*time.Millisecond {
+		t.Errorf("parseDelay(range) = %v, %v, %v", low, high, err)
+	}
+	if low, high, err := parseDelay("150"); err != nil || low != 150*time.Millisecond || high != low {
+		t.Errorf("parseDelay(150) = %v, %v, %v", low, high, err)
+	}
+	if _, _, err := parseDelay("1s..10ms"); err == nil {
+		t.Error("parseDelay accepted a reversed range")
+	}
+
+	if rate, status, err := parseFailRate("0.1 status=503"); err != nil || rate != 0.1 || status != 503 {
+		t.Errorf("parseFailRate = %v, %v, %v", rate, status, err)
+	}
+	if rate, status, err := parseFailRate("25%"); err != nil || rate != 0.25 || status != 500 {
+		t.Errorf("parseFailRate(25%%) = %v, %v, %v", rate, status, err)
+	}
+	for _, bad := range []string{"", "2", "0.1 code=503", "0.1 status=abc"} {
+		if _, _, err := parseFailRate(bad); err == nil {
+			t.Errorf("parseFailRate(%q) succeeded", bad)
+		}
+	}
+
+	for raw, want := range map[string]int{"1kb/s": 1024, "512b/s": 512, "2mb/s": 2 << 20, "100": 100} {
+		if got, err := parseThroughput(raw); err != nil || got != want {
+			t.Errorf("parseThroughput(%q) = %d, %v, want %d", raw, got, err, want)
+		}
+	}
+	if _, err := parseThroughput("fast"); err == nil {
+		t.Error("parseThroughput accepted a non-numeric rate")
+	}
+}
+
+func TestServerInjectsFaults(t *testing.T) {
+	srv := New(Config{Requests: []MockRequest{
+		{Method: "GET", URL: "/slow", Body: "ok", Directives: []string{"status 202", "delay 150ms..200ms"}},
+		{Method: "GET", URL: "/flaky", Body: "ok", Directives: []string{"fail-rate 1 status=503"}},
+		{Method: "GET", URL: "/drop", Body: "ok", Directives: []string{"drop-connection"}},
+		{Method: "GET", URL: "/stream", Body: strings.Repeat("x", 300), Directives: []string{"chunked-throttle 1kb/s"}},
+		{Method: "GET", URL: "/script-fail", PreScript: `response.fail(418); response.body = "never";`},
+		{Method: "GET", URL: "/script-drop", PreScript: `response.dropConnection();`},
+		{Method: "GET", URL: "/script-delay", PreScript: `response.delay("100ms"); response.body = "late";`},
+		{Method: "GET", URL: "/script-bad", PreScript: `response.throttle("fast");`},
+	}})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	base := fmt.Sprintf("http://127.0.0.1:%d", srv.Port())
+
+	get := func(path string) (*http.Response, string, time.Duration, error) {
+		t.Helper()
+		start := time.Now()
+		resp, err := http.Get(base + path)
+		if err != nil {
+			return nil, "", time.Since(start), err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return resp, string(body), time.Since(start), err
+	}
+
+	resp, body, elapsed, err := get("/slow")
+	if err != nil || resp.StatusCode != 202 || body != "ok" || elapsed < 150*time.Millisecond {
+		t.Errorf("/slow = %v %q after %v (%v), want 202 ok after >=150ms", resp.StatusCode, body, elapsed, err)
+	}
+
+	resp, body, _, err = get("/flaky")
+	if err != nil || resp.StatusCode != 503 || !strings.Contains(body, "Injected fault") {
+		t.Errorf("/flaky = %v %q (%v), want injected 503", resp.StatusCode, body, err)
+	}
+
+	if _, _, _, err := get("/drop"); err == nil {
+		t.Error("/drop returned a response, want the connection closed")
+	}
+
+	resp, body, elapsed, err = get("/stream")
+	if err != nil || len(body) != 300 || resp.Header.Get("Content-Length") != "" || elapsed < 200*time.Millisecond {
+		t.Errorf("/stream = %d bytes, Content-Length %q after %v (%v), want 300 chunked bytes over >=200ms",
+			len(body), resp.Header.Get("Content-Length"), elapsed, err)
+	}
+
+	resp, body, _, err = get("/script-fail")
+	if err != nil || resp.StatusCode != 418 || strings.Contains(body, "never") {
+		t.Errorf("/script-fail = %v %q (%v), want injected 418", resp.StatusCode, body, err)
+	}
+
+	if _, _, _, err := get("/script-drop"); err == nil {
+		t.Error("/script-drop returned a response, want the connection closed")
+	}
+
+	resp, body, elapsed, err = get("/script-delay")
+	if err != nil || body != "late" || elapsed < 100*time.Millisecond {
+		t.Errorf("/script-delay = %q after %v (%v), want late after >=100ms", body, elapsed, err)
+	}
+
+	resp, body, _, err = get("/script-bad")
+	if err != nil || resp.StatusCode != 500 || !strings.Contains(body, "response.throttle") {
+		t.Errorf("/script-bad = %v %q (%v), want a script error", resp.StatusCode, body, err)
+	}
+}
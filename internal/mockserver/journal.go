@@ -0,0 +1,179 @@
+package mockserver
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"rawrequest/internal/ringbuffer"
+)
+
+// JournalLimit is how many requests the journal keeps; older ones are
+// dropped first.
+const JournalLimit = 1000
+
+// JournalEntry is one request received by the mock server.
+type JournalEntry struct {
+	Time    time.Time         `json:"time"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body,omitempty"`
+	// Route is the matched route, e.g. "POST /orders", "proxy" for a
+	// forwarded request, or empty when nothing matched.
+	Route     string `json:"route,omitempty"`
+	RouteName string `json:"routeName,omitempty"`
+	// Status is the response status; 0 means the connection was dropped
+	// or the request is still being served.
+	Status int `json:"status"`
+}
+
+type journal struct {
+	mu      sync.Mutex
+	entries *ringbuffer.Buffer[*JournalEntry]
+}
+
+func (j *journal) add(entry *JournalEntry) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.entries == nil {
+		j.entries = ringbuffer.New[*JournalEntry](JournalLimit)
+	}
+	j.entries.Append(entry)
+}
+
+func (j *journal) setStatus(entry *JournalEntry, status int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	entry.Status = status
+}
+
+func (j *journal) list() []JournalEntry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	items := j.entries.Items()
+	out := make([]JournalEntry, len(items))
+	for i, entry := range items {
+		out[i] = *entry
+	}
+	return out
+}
+
+func (j *journal) clear() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	n := j.entries.Len()
+	j.entries.Clear()
+	return n
+}
+
+// Journal returns the requests received so far, oldest first.
+func (s *Server) Journal() []JournalEntry {
+	return s.journal.list()
+}
+
+// ClearJournal forgets every recorded request.
+func (s *Server) ClearJournal() {
+	s.journal.clear()
+}
+
+// CountCalls returns how many journaled requests have method (any when
+// empty) and a path matching pattern, which may use :param placeholders.
+func (s *Server) CountCalls(method, pattern string) int {
+	return len(filterJournal(s.Journal(), method, pattern))
+}
+
+func filterJournal(entries []JournalEntry, method, pattern string) []JournalEntry {
+	var re *regexp.Regexp
+	if pattern != "" {
+		re, _ = compilePathPattern(pattern)
+	}
+	var out []JournalEntry
+	for _, entry := range entries {
+		if method != "" && !strings.EqualFold(entry.Method, method) {
+			continue
+		}
+		if re != nil && !re.MatchString(entry.Path) {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// recordRequest adds r to the journal before it is served.
+func (s *Server) recordRequest(r *http.Request, body []byte, route *Route) *JournalEntry {
+	headers := make(map[string]string, len(r.Header))
+	for k, vals := range r.Header {
+		if len(vals) > 0 {
+			headers[k] = vals[0]
+		}
+	}
+	entry := &JournalEntry{
+		Time:    time.Now(),
+		Method:  r.Method,
+		Path:    r.URL.Path,
+		Query:   r.URL.RawQuery,
+		Headers: headers,
+		Body:    string(body),
+	}
+	switch {
+	case route != nil:
+		entry.Route = route.Method + " " + route.PathPattern
+		entry.RouteName = route.Request.Name
+	case s.proxy != nil:
+		entry.Route = "proxy"
+	}
+	s.journal.add(entry)
+	return entry
+}
+
+// statusRecorder remembers the status written through it for the journal.
+// It passes Flush and Hijack through so streaming, WebSocket and dropped
+// connection responses keep working.
+type statusRecorder struct {
+	http.ResponseWriter
+	status  int
+	upgrade bool
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hj, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	if rec.upgrade && rec.status == 0 {
+		rec.status = http.StatusSwitchingProtocols
+	}
+	return hj.Hijack()
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,92 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"rawrequest/internal/assertions"
+	"rawrequest/internal/jsonpath"
+)
+
+// Matcher is a compiled `@match` condition. A route only serves a request
+// when all of its matchers accept it.
+type Matcher struct {
+	assertions.Expr
+}
+
+// matchGrammar lists the subjects `@match` accepts. header and query also
+// take the compact key=value form, e.g. `query status=active`.
+var matchGrammar = assertions.Grammar{
+	Noun: "match",
+	Subjects: map[string]assertions.Subject{
+		"body":     {Name: "body"},
+		"header":   {Name: "header", Arg: "header name", KeyValue: true},
+		"query":    {Name: "query", Arg: "parameter name", KeyValue: true},
+		"jsonpath": {Name: "jsonpath", Arg: "path"},
+		"json":     {Name: "jsonpath", Arg: "path"},
+	},
+}
+
+// compileMatcher parses the text following `@match`, e.g.
+//
+//	header X-Tenant = acme
+//	query status=active
+//	jsonpath $.type == "premium"
+//	body contains "express"
+func compileMatcher(expr string) (Matcher, error) {
+	e, err := assertions.ParseExpr(expr, matchGrammar)
+	return Matcher{Expr: e}, err
+}
+
+// Matches reports whether the request satisfies the condition. Conditions
+// that cannot be evaluated (e.g. a jsonpath on a non-JSON body) do not match.
+func (m Matcher) Matches(r *http.Request, body []byte) bool {
+	actual, present := m.actual(r, body)
+	ok, err := assertions.Compare(actual, present, m.Op, m.Expected)
+	return err == nil && ok
+}
+
+func (m Matcher) actual(r *http.Request, body []byte) (interface{}, bool) {
+	switch m.Subject {
+	case "header":
+		values, ok := r.Header[http.CanonicalHeaderKey(m.Arg)]
+		if !ok || len(values) == 0 {
+			return nil, false
+		}
+		return values[0], true
+	case "query":
+		values, ok := r.URL.Query()[m.Arg]
+		if !ok || len(values) == 0 {
+			return nil, false
+		}
+		return values[0], true
+	case "body":
+		return string(body), true
+	case "jsonpath":
+		var data interface{}
+		if json.Unmarshal(body, &data) != nil {
+			return nil, false
+		}
+		return jsonpath.Lookup(data, m.Arg)
+	}
+	return nil, false
+}
+
+// conditional reports whether the route has @match conditions or a
+// required scenario state.
+func (route *Route) conditional() bool {
+	return len(route.Matchers) > 0 || (route.Scenario != nil && route.Scenario.State != "")
+}
+
+// sortRoutes orders routes for matching: higher @priority first, then routes
+// with @match conditions or a required scenario state ahead of unconditional
+// ones, then file order.
+func sortRoutes(routes []Route) {
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Priority != routes[j].Priority {
+			return routes[i].Priority > routes[j].Priority
+		}
+		return routes[i].conditional() && !routes[j].conditional()
+	})
+}
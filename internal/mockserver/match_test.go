@@ -0,0 +1,82 @@
+package mockserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCompileMatcher(t *testing.T) {
+	cases := []struct {
+		expr    string
+		subject string
+		arg     string
+		want    string
+	}{
+		{`header X-Tenant = acme`, "header", "X-Tenant", "acme"},
+		{`query status=active`, "query", "status", "active"},
+		{`jsonpath $.type == "premium"`, "jsonpath", "$.type", `"premium"`},
+		{`body contains express delivery`, "body", "", "express delivery"},
+		{`header X-Note contains "two  words"`, "header", "X-Note", `"two  words"`},
+	}
+	for _, tc := range cases {
+		m, err := compileMatcher(tc.expr)
+		if err != nil {
+			t.Fatalf("compileMatcher(%q) error = %v", tc.expr, err)
+		}
+		if m.Subject != tc.subject || m.Arg != tc.arg || m.Expected != tc.want {
+			t.Errorf("compileMatcher(%q) = %+v", tc.expr, m)
+		}
+	}
+
+	for _, bad := range []string{"", "cookie a = b", "header", "query status", "jsonpath $.a ~~ 1"} {
+		if _, err := compileMatcher(bad); err == nil {
+			t.Errorf("compileMatcher(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestServeHTTPPicksRouteByConditionsAndPriority(t *testing.T) {
+	srv := New(Config{})
+	srv.Reload([]MockRequest{
+		{Method: "POST", URL: "/orders", Body: "default"},
+		{Method: "POST", URL: "/orders", Body: "acme", Directives: []string{"match header X-Tenant = acme"}},
+		{Method: "POST", URL: "/orders", Body: "premium", Directives: []string{`match jsonpath $.type == "premium"`}},
+		{Method: "POST", URL: "/orders", Body: "active-premium", Directives: []string{
+			"match query status=active",
+			`match jsonpath $.type == "premium"`,
+			"priority 5",
+		}},
+		{Method: "POST", URL: "/orders", Body: "ignored", Directives: []string{"match cookie session = x"}},
+	})
+
+	cases := []struct {
+		query, tenant, body string
+		want                string
+	}{
+		{"", "", `{}`, "default"},
+		{"", "acme", `{}`, "acme"},
+		{"", "", `{"type":"premium"}`, "premium"},
+		{"?status=active", "", `{"type":"premium"}`, "active-premium"},
+		{"?status=active", "acme", `{"type":"basic"}`, "acme"},
+		{"", "", `not json`, "default"},
+	}
+	for _, tc := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/orders"+tc.query, strings.NewReader(tc.body))
+		if tc.tenant != "" {
+			r.Header.Set("X-Tenant", tc.tenant)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, r)
+		if got := rec.Body.String(); got != tc.want {
+			t.Errorf("query=%q tenant=%q body=%q -> %q, want %q", tc.query, tc.tenant, tc.body, got, tc.want)
+		}
+	}
+
+	routes := *srv.routes.Load()
+	last := routes[len(routes)-1]
+	if len(last.Warnings) != 1 || !strings.Contains(last.Warnings[0], "unknown match subject") {
+		t.Fatalf("warnings = %v, want the invalid @match reported", last.Warnings)
+	}
+}
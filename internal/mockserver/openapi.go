@@ -0,0 +1,469 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxSchemaDepth stops example synthesis on deeply nested or recursive
+// schemas.
+const maxSchemaDepth = 8
+
+var openAPIParamRegex = regexp.MustCompile(`\{([^}]+)\}`)
+
+// OpenAPISpec is an OpenAPI 3 document reduced to what the mock server needs:
+// one Operation per path and method.
+type OpenAPISpec struct {
+	Title      string
+	Operations []Operation
+	doc        map[string]any
+}
+
+// Operation is one path and method of the spec with its generated response.
+type Operation struct {
+	ID     string
+	Method string
+	// Path is the OpenAPI template, e.g. "/users/{id}"; Route is the mock
+	// route form, e.g. "/users/:id".
+	Path       string
+	Route      string
+	Parameters []OpenAPIParameter
+	// BodySchema is the JSON request body schema, when the spec declares one.
+	BodySchema   map[string]any
+	BodyRequired bool
+
+	Status      int
+	ContentType string
+	Body        string
+
+	regex *regexp.Regexp
+}
+
+// OpenAPIParameter is a path, query or header parameter of an operation.
+type OpenAPIParameter struct {
+	Name     string
+	In       string
+	Required bool
+	Schema   map[string]any
+}
+
+// LoadOpenAPI reads and parses an OpenAPI 3 document in YAML or JSON.
+func LoadOpenAPI(path string) (*OpenAPISpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseOpenAPI(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return spec, nil
+}
+
+// ParseOpenAPI parses an OpenAPI 3 document and generates a response for
+// each operation from its examples or, failing that, its schema.
+func ParseOpenAPI(data []byte) (*OpenAPISpec, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	doc, ok := normalizeYAML(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid OpenAPI document: expected an object")
+	}
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q (need 3.x)", version)
+	}
+
+	spec := &OpenAPISpec{doc: doc}
+	if info, ok := doc["info"].(map[string]any); ok {
+		spec.Title, _ = info["title"].(string)
+	}
+	paths, _ := doc["paths"].(map[string]any)
+	for _, path := range sortedKeys(paths) {
+		item := spec.resolve(paths[path])
+		pathParams := spec.parameters(item["parameters"])
+		for _, method := range []string{"get", "put", "post", "delete", "options", "head", "patch"} {
+			opNode, ok := item[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			spec.Operations = append(spec.Operations, spec.operation(path, strings.ToUpper(method), opNode, pathParams))
+		}
+	}
+	// Concrete paths such as /users/me must win over /users/{id}.
+	sort.SliceStable(spec.Operations, func(i, j int) bool {
+		return len(spec.Operations[i].pathParams()) < len(spec.Operations[j].pathParams())
+	})
+	return spec, nil
+}
+
+func (spec *OpenAPISpec) operation(path, method string, node map[string]any, pathParams []OpenAPIParameter) Operation {
+	op := Operation{Method: method, Path: path}
+	op.ID, _ = node["operationId"].(string)
+	op.Route = openAPIParamRegex.ReplaceAllStringFunc(path, func(m string) string {
+		return ":" + sanitizeParamName(m[1:len(m)-1])
+	})
+	op.regex, _ = compilePathPattern(op.Route)
+
+	// Operation parameters override path-level ones with the same name and
+	// location.
+	params := map[string]OpenAPIParameter{}
+	var order []string
+	for _, p := range append(pathParams, spec.parameters(node["parameters"])...) {
+		key := p.In + ":" + p.Name
+		if _, seen := params[key]; !seen {
+			order = append(order, key)
+		}
+		params[key] = p
+	}
+	for _, key := range order {
+		op.Parameters = append(op.Parameters, params[key])
+	}
+
+	if body := spec.resolve(node["requestBody"]); body != nil {
+		op.BodyRequired, _ = body["required"].(bool)
+		if media := pickMedia(spec.mapAt(body, "content")); media != nil {
+			op.BodySchema = spec.resolve(media["schema"])
+		}
+	}
+
+	op.Status, op.ContentType, op.Body = spec.exampleResponse(spec.mapAt(node, "responses"))
+	return op
+}
+
+func (spec *OpenAPISpec) parameters(node any) []OpenAPIParameter {
+	list, _ := node.([]any)
+	var out []OpenAPIParameter
+	for _, entry := range list {
+		p := spec.resolve(entry)
+		if p == nil {
+			continue
+		}
+		param := OpenAPIParameter{Schema: spec.resolve(p["schema"])}
+		param.Name, _ = p["name"].(string)
+		param.In, _ = p["in"].(string)
+		param.Required, _ = p["required"].(bool)
+		if param.In == "path" {
+			param.Required = true
+		}
+		out = append(out, param)
+	}
+	return out
+}
+
+// exampleResponse picks the lowest 2xx response (or default) and renders its
+// example body.
+func (spec *OpenAPISpec) exampleResponse(responses map[string]any) (int, string, string) {
+	code, key := http.StatusOK, ""
+	for _, k := range sortedKeys(responses) {
+		n, err := strconv.Atoi(k)
+		if err == nil && n >= 200 && n < 300 {
+			code, key = n, k
+			break
+		}
+	}
+	if key == "" {
+		if _, ok := responses["default"]; ok {
+			key = "default"
+		} else if keys := sortedKeys(responses); len(keys) > 0 {
+			key = keys[0]
+			if n, err := strconv.Atoi(key); err == nil {
+				code = n
+			}
+		}
+	}
+	response := spec.resolve(responses[key])
+	content := spec.mapAt(response, "content")
+	contentType := pickMediaType(content)
+	if contentType == "" {
+		return code, "", ""
+	}
+	media := spec.resolve(content[contentType])
+
+	value, ok := spec.mediaExample(media)
+	if !ok {
+		value = spec.synthesize(spec.resolve(media["schema"]), 0)
+	}
+	if text, isString := value.(string); isString && !strings.Contains(contentType, "json") {
+		return code, contentType, text
+	}
+	body, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return code, contentType, ""
+	}
+	return code, contentType, string(body)
+}
+
+func (spec *OpenAPISpec) mediaExample(media map[string]any) (any, bool) {
+	if example, ok := media["example"]; ok {
+		return example, true
+	}
+	if examples := spec.mapAt(media, "examples"); len(examples) > 0 {
+		first := spec.resolve(examples[sortedKeys(examples)[0]])
+		if value, ok := first["value"]; ok {
+			return value, true
+		}
+	}
+	if schema := spec.resolve(media["schema"]); schema != nil {
+		if example, ok := schema["example"]; ok {
+			return example, true
+		}
+	}
+	return nil, false
+}
+
+// synthesize builds a value that satisfies schema, preferring its example,
+// default and enum values.
+func (spec *OpenAPISpec) synthesize(schema map[string]any, depth int) any {
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	for _, key := range []string{"example", "default"} {
+		if v, ok := schema[key]; ok {
+			return v
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		merged := map[string]any{}
+		for _, part := range all {
+			if obj, ok := spec.synthesize(spec.resolve(part), depth+1).(map[string]any); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := schema[key].([]any); ok && len(options) > 0 {
+			return spec.synthesize(spec.resolve(options[0]), depth+1)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj := map[string]any{}
+		props := spec.mapAt(schema, "properties")
+		for _, name := range sortedKeys(props) {
+			obj[name] = spec.synthesize(spec.resolve(props[name]), depth+1)
+		}
+		return obj
+	case "array":
+		item := spec.synthesize(spec.resolve(schema["items"]), depth+1)
+		if item == nil {
+			return []any{}
+		}
+		return []any{item}
+	case "integer":
+		if min, ok := schema["minimum"].(float64); ok {
+			return int64(min)
+		}
+		return 0
+	case "number":
+		if min, ok := schema["minimum"].(float64); ok {
+			return min
+		}
+		return 0.0
+	case "boolean":
+		return true
+	case "string":
+		switch schema["format"] {
+		case "date-time":
+			return "2024-01-01T00:00:00Z"
+		case "date":
+			return "2024-01-01"
+		case "email":
+			return "user@example.com"
+		case "uuid":
+			return "00000000-0000-4000-8000-000000000000"
+		case "uri", "url":
+			return "https://example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+// resolve follows a local "$ref" and returns the node as an object, or nil.
+func (spec *OpenAPISpec) resolve(node any) map[string]any {
+	for i := 0; i < maxSchemaDepth; i++ {
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj
+		}
+		node = spec.lookup(ref)
+	}
+	return nil
+}
+
+func (spec *OpenAPISpec) lookup(ref string) any {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+	var node any = spec.doc
+	for _, part := range strings.Split(ref[2:], "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		obj, ok := node.(map[string]any)
+		if !ok {
+			return nil
+		}
+		node = obj[part]
+	}
+	return node
+}
+
+func (spec *OpenAPISpec) mapAt(node map[string]any, key string) map[string]any {
+	if node == nil {
+		return nil
+	}
+	obj, _ := node[key].(map[string]any)
+	return obj
+}
+
+// MockRequests turns the operations into mock route definitions. Put them
+// after hand-written @mock blocks so those take precedence.
+func (spec *OpenAPISpec) MockRequests() []MockRequest {
+	var out []MockRequest
+	for _, op := range spec.Operations {
+		req := MockRequest{
+			Name:       op.ID,
+			Method:     op.Method,
+			URL:        op.Route,
+			Headers:    map[string]string{},
+			Body:       op.Body,
+			Directives: []string{fmt.Sprintf("status %d", op.Status)},
+		}
+		if req.Name == "" {
+			req.Name = op.Method + " " + op.Path
+		}
+		if op.ContentType != "" {
+			req.Headers["Content-Type"] = op.ContentType
+		}
+		out = append(out, req)
+	}
+	return out
+}
+
+// find returns the operation for method and path.
+func (spec *OpenAPISpec) find(method, path string) *Operation {
+	for i := range spec.Operations {
+		op := &spec.Operations[i]
+		if op.Method == method && op.regex.MatchString(path) {
+			return op
+		}
+	}
+	return nil
+}
+
+func (op *Operation) pathParams() []string {
+	return openAPIParamRegex.FindAllString(op.Path, -1)
+}
+
+func pickMedia(content map[string]any) map[string]any {
+	media, _ := content[pickMediaType(content)].(map[string]any)
+	return media
+}
+
+// pickMediaType prefers application/json, then any JSON type, then the first
+// declared type.
+func pickMediaType(content map[string]any) string {
+	if _, ok := content["application/json"]; ok {
+		return "application/json"
+	}
+	keys := sortedKeys(content)
+	for _, k := range keys {
+		if strings.Contains(k, "json") {
+			return k
+		}
+	}
+	if len(keys) > 0 {
+		return keys[0]
+	}
+	return ""
+}
+
+func schemaType(schema map[string]any) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []any:
+		// OpenAPI 3.1 allows ["string", "null"].
+		for _, v := range t {
+			if s, ok := v.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	if _, ok := schema["items"]; ok {
+		return "array"
+	}
+	return ""
+}
+
+func sanitizeParamName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// normalizeYAML converts YAML mappings to map[string]any, integers to
+// float64 as encoding/json would decode them, and timestamps to strings.
+func normalizeYAML(node any) any {
+	switch v := node.(type) {
+	case map[string]any:
+		for k, child := range v {
+			v[k] = normalizeYAML(child)
+		}
+		return v
+	case map[any]any:
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			out[fmt.Sprint(k)] = normalizeYAML(child)
+		}
+		return out
+	case []any:
+		for i, child := range v {
+			v[i] = normalizeYAML(child)
+		}
+		return v
+	case int:
+		return float64(v)
+	case time.Time:
+		return v.Format(time.RFC3339)
+	}
+	return node
+}
@@ -0,0 +1,187 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const petstoreSpec = `
+openapi: 3.0.3
+info:
+  title: Pets
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - name: limit
+          in: query
+          schema: { type: integer, maximum: 100 }
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: { $ref: '#/components/schemas/Pet' }
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: { $ref: '#/components/schemas/NewPet' }
+      responses:
+        201:
+          description: created
+          content:
+            application/json:
+              examples:
+                rex: { value: { id: 7, name: Rex, tag: dog } }
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema: { type: integer }
+    get:
+      operationId: getPet
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema: { $ref: '#/components/schemas/Pet' }
+        '404':
+          description: missing
+    delete:
+      responses:
+        '204': { description: gone }
+  /pets/mine:
+    get:
+      responses:
+        '200':
+          description: ok
+          content:
+            text/plain:
+              example: all of them
+components:
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name: { type: string, minLength: 1 }
+        tag: { type: string, enum: [dog, cat] }
+    Pet:
+      allOf:
+        - $ref: '#/components/schemas/NewPet'
+        - type: object
+          properties:
+            id: { type: integer, example: 1 }
+            born: { type: string, format: date }
+`
+
+func TestParseOpenAPIGeneratesRoutes(t *testing.T) {
+	spec, err := ParseOpenAPI([]byte(petstoreSpec))
+	if err != nil {
+		t.Fatalf("ParseOpenAPI() error = %v", err)
+	}
+	if spec.Title != "Pets" {
+		t.Errorf("Title = %q", spec.Title)
+	}
+	byName := map[string]MockRequest{}
+	var order []string
+	for _, req := range spec.MockRequests() {
+		byName[req.Name] = req
+		order = append(order, req.Method+" "+req.URL)
+	}
+	if got := strings.Join(order, ","); !strings.HasPrefix(got, "GET /pets,POST /pets,GET /pets/mine,") {
+		t.Fatalf("route order = %s, want concrete paths before /pets/:petId", got)
+	}
+
+	pet := byName["getPet"]
+	if pet.URL != "/pets/:petId" || pet.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("getPet = %+v", pet)
+	}
+	var body map[string]any
+	if err := json.Unmarshal([]byte(pet.Body), &body); err != nil {
+		t.Fatalf("getPet body %q: %v", pet.Body, err)
+	}
+	if body["id"] != float64(1) || body["name"] != "string" || body["tag"] != "dog" || body["born"] != "2024-01-01" {
+		t.Errorf("synthesized pet = %v", body)
+	}
+
+	if created := byName["createPet"]; created.Body != "{\n  \"id\": 7,\n  \"name\": \"Rex\",\n  \"tag\": \"dog\"\n}" ||
+		strings.Join(created.Directives, ",") != "status 201" {
+		t.Errorf("createPet = %+v", created)
+	}
+	if gone := byName["DELETE /pets/{petId}"]; gone.Body != "" || strings.Join(gone.Directives, ",") != "status 204" {
+		t.Errorf("deletePet = %+v", gone)
+	}
+	if mine := byName["GET /pets/mine"]; mine.Body != "all of them" || mine.Headers["Content-Type"] != "text/plain" {
+		t.Errorf("text example = %+v", mine)
+	}
+
+	if _, err := ParseOpenAPI([]byte(`swagger: "2.0"`)); err == nil {
+		t.Error("ParseOpenAPI accepted a Swagger 2 document")
+	}
+}
+
+func TestServerValidatesRequestsAgainstOpenAPI(t *testing.T) {
+	spec, err := ParseOpenAPI([]byte(petstoreSpec))
+	if err != nil {
+		t.Fatalf("ParseOpenAPI() error = %v", err)
+	}
+	override := MockRequest{Method: "GET", URL: "/pets/:petId", Body: `{"id":42,"name":"Override"}`}
+	srv := New(Config{
+		Requests:         append([]MockRequest{override}, spec.MockRequests()...),
+		OpenAPI:          spec,
+		ValidateRequests: true,
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	base := fmt.Sprintf("http://127.0.0.1:%d", srv.Port())
+
+	do := func(method, path, body string) (int, string) {
+		t.Helper()
+		req, _ := http.NewRequest(method, base+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s error = %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(data)
+	}
+
+	if status, body := do("GET", "/pets/3", ""); status != 200 || !strings.Contains(body, "Override") {
+		t.Fatalf("override = %d %s", status, body)
+	}
+	if status, body := do("POST", "/pets", `{"name":"Rex","tag":"dog"}`); status != 201 || !strings.Contains(body, `"Rex"`) {
+		t.Fatalf("valid create = %d %s", status, body)
+	}
+
+	for _, tc := range []struct {
+		method, path, body, want string
+	}{
+		{"POST", "/pets", `{"tag":"bird"}`, "body.name is required"},
+		{"POST", "/pets", `{"name":"Rex","tag":"bird"}`, "body.tag must be one of"},
+		{"POST", "/pets", ``, "request body is required"},
+		{"GET", "/pets?limit=500", ``, `query parameter \"limit\" must be <= 100`},
+		{"GET", "/pets/abc", ``, `path parameter \"petId\" must be an integer`},
+	} {
+		status, body := do(tc.method, tc.path, tc.body)
+		if status != http.StatusBadRequest || !strings.Contains(body, tc.want) {
+			t.Errorf("%s %s %s = %d %s, want 400 mentioning %q", tc.method, tc.path, tc.body, status, body, tc.want)
+		}
+	}
+}
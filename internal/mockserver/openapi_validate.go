@@ -0,0 +1,257 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidateRequest checks r and its body against the operation the spec
+// declares for its method and path. It returns the violations, or nil when
+// the request conforms or the spec has no such operation.
+func (spec *OpenAPISpec) ValidateRequest(r *http.Request, body []byte) []string {
+	op := spec.find(strings.ToUpper(r.Method), r.URL.Path)
+	if op == nil {
+		return nil
+	}
+	var violations []string
+
+	pathValues := map[string]string{}
+	if matches := op.regex.FindStringSubmatch(r.URL.Path); matches != nil {
+		for i, name := range openAPIParamRegex.FindAllStringSubmatch(op.Path, -1) {
+			if i+1 < len(matches) {
+				pathValues[name[1]] = matches[i+1]
+			}
+		}
+	}
+	query := r.URL.Query()
+	for _, p := range op.Parameters {
+		var raw string
+		var present bool
+		switch p.In {
+		case "path":
+			raw, present = pathValues[p.Name]
+		case "query":
+			present = query.Has(p.Name)
+			raw = query.Get(p.Name)
+		case "header":
+			raw = r.Header.Get(p.Name)
+			present = raw != ""
+		default:
+			continue
+		}
+		label := fmt.Sprintf("%s parameter %q", p.In, p.Name)
+		if !present {
+			if p.Required {
+				violations = append(violations, label+" is required")
+			}
+			continue
+		}
+		violations = append(violations, spec.validate(p.Schema, coerceParam(p.Schema, raw), label, 0)...)
+	}
+
+	if op.BodySchema != nil || op.BodyRequired {
+		if len(strings.TrimSpace(string(body))) == 0 {
+			if op.BodyRequired {
+				violations = append(violations, "request body is required")
+			}
+		} else if op.BodySchema != nil {
+			var value any
+			if err := json.Unmarshal(body, &value); err != nil {
+				violations = append(violations, "request body is not valid JSON: "+err.Error())
+			} else {
+				violations = append(violations, spec.validate(op.BodySchema, value, "body", 0)...)
+			}
+		}
+	}
+	return violations
+}
+
+// validate checks a decoded JSON value against a schema subset: type, enum,
+// required, properties, items, bounds, lengths, pattern and the
+// allOf/oneOf/anyOf combinators.
+func (spec *OpenAPISpec) validate(schema map[string]any, value any, at string, depth int) []string {
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	var out []string
+	fail := func(format string, args ...any) {
+		out = append(out, at+" "+fmt.Sprintf(format, args...))
+	}
+
+	if value == nil {
+		if nullable, _ := schema["nullable"].(bool); nullable || typeAllowsNull(schema) {
+			return nil
+		}
+		if schemaType(schema) != "" {
+			fail("must not be null")
+		}
+		return out
+	}
+
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, part := range all {
+			out = append(out, spec.validate(spec.resolve(part), value, at, depth+1)...)
+		}
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if options, ok := schema[key].([]any); ok && len(options) > 0 {
+			matched := false
+			for _, option := range options {
+				if len(spec.validate(spec.resolve(option), value, at, depth+1)) == 0 {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				fail("does not match any schema in %s", key)
+			}
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok && len(enum) > 0 {
+		found := false
+		for _, allowed := range enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %v", enum)
+		}
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			fail("must be an object")
+			return out
+		}
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := obj[fmt.Sprint(name)]; !ok {
+				out = append(out, fmt.Sprintf("%s.%v is required", at, name))
+			}
+		}
+		props := spec.mapAt(schema, "properties")
+		for _, name := range sortedKeys(obj) {
+			if propSchema, ok := props[name]; ok {
+				out = append(out, spec.validate(spec.resolve(propSchema), obj[name], at+"."+name, depth+1)...)
+			} else if extra, ok := schema["additionalProperties"].(bool); ok && !extra {
+				out = append(out, fmt.Sprintf("%s.%s is not allowed", at, name))
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			fail("must be an array")
+			return out
+		}
+		if min, ok := schema["minItems"].(float64); ok && float64(len(items)) < min {
+			fail("must have at least %v items", min)
+		}
+		if max, ok := schema["maxItems"].(float64); ok && float64(len(items)) > max {
+			fail("must have at most %v items", max)
+		}
+		itemSchema := spec.resolve(schema["items"])
+		for i, item := range items {
+			out = append(out, spec.validate(itemSchema, item, fmt.Sprintf("%s[%d]", at, i), depth+1)...)
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return out
+		}
+		length := float64(len([]rune(text)))
+		if min, ok := schema["minLength"].(float64); ok && length < min {
+			fail("must be at least %v characters", min)
+		}
+		if max, ok := schema["maxLength"].(float64); ok && length > max {
+			fail("must be at most %v characters", max)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(text) {
+				fail("must match pattern %s", pattern)
+			}
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		integer := schemaType(schema) == "integer"
+		if !ok || (integer && n != math.Trunc(n)) {
+			if integer {
+				fail("must be an integer")
+			} else {
+				fail("must be a number")
+			}
+			return out
+		}
+		if min, ok := schema["minimum"].(float64); ok && n < min {
+			fail("must be >= %v", min)
+		}
+		if max, ok := schema["maximum"].(float64); ok && n > max {
+			fail("must be <= %v", max)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	}
+	return out
+}
+
+// coerceParam converts a raw parameter string to the JSON type its schema
+// declares, leaving it a string when it does not parse so validate reports
+// the mismatch.
+func coerceParam(schema map[string]any, raw string) any {
+	switch schemaType(schema) {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	case "array":
+		var items []any
+		itemSchema, _ := schema["items"].(map[string]any)
+		for _, part := range strings.Split(raw, ",") {
+			items = append(items, coerceParam(itemSchema, part))
+		}
+		return items
+	}
+	return raw
+}
+
+func typeAllowsNull(schema map[string]any) bool {
+	types, ok := schema["type"].([]any)
+	if !ok {
+		return false
+	}
+	for _, t := range types {
+		if t == "null" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeValidationError answers a request that does not match the spec.
+func (s *Server) writeValidationError(w http.ResponseWriter, r *http.Request, violations []string) {
+	s.logf("warn", "mockserver", "[Mock Server] 400 %s %s violates the OpenAPI spec: %s\n", r.Method, r.URL.Path, strings.Join(violations, "; "))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(map[string]any{
+		"error":      "Request does not match the OpenAPI specification",
+		"violations": violations,
+	})
+}
@@ -0,0 +1,127 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	hcl "rawrequest/internal/httpclientlogic"
+
+	"github.com/dop251/goja"
+)
+
+// payload is a response body a mock script set through response.file,
+// response.bytes or response.stream. It replaces response.body when set.
+type payload struct {
+	set  bool
+	body []byte
+	// contentType is used when the script set no Content-Type header.
+	contentType string
+	// chunks, when not nil, are written one at a time with interval between
+	// them instead of body.
+	chunks   [][]byte
+	interval time.Duration
+}
+
+// resolveFile resolves a fixture path against the directory of the .http
+// file the mocks came from.
+func (s *Server) resolveFile(path string) string {
+	if filepath.IsAbs(path) || s.cfg.File == "" {
+		return path
+	}
+	return filepath.Join(filepath.Dir(s.cfg.File), path)
+}
+
+// readBodyFile reads a fixture and infers its content type from the
+// extension.
+func (s *Server) readBodyFile(path string) ([]byte, string, error) {
+	data, err := os.ReadFile(s.resolveFile(path))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, hcl.ContentTypeForExtension(filepath.Ext(path)), nil
+}
+
+// serveBodyFile answers a route with @body-file. The file is read on every
+// request, so edits to the fixture apply without a reload.
+func (s *Server) serveBodyFile(w http.ResponseWriter, r *http.Request, route *Route) {
+	for k, v := range route.Request.Headers {
+		w.Header().Set(k, v)
+	}
+	data, contentType, err := s.readBodyFile(route.BodyFile)
+	if err != nil {
+		s.logf("error", "mockserver", "[Mock Server] @body-file for %s %s: %v\n", route.Method, route.PathPattern, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"error": "Mock body file could not be read", "details": %q}`, err.Error())))
+		return
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+	s.deliver(w, r, route.Faults, route.status(), data)
+}
+
+// installPayloadControls adds response.file, response.bytes and
+// response.stream to a mock script.
+func (s *Server) installPayloadControls(vm *goja.Runtime, respObj *goja.Object, p *payload) {
+	_ = respObj.Set("file", func(call goja.FunctionCall) goja.Value {
+		path := call.Argument(0).String()
+		data, contentType, err := s.readBodyFile(path)
+		if err != nil {
+			panic(vm.NewTypeError("response.file: %v", err))
+		}
+		*p = payload{set: true, body: data, contentType: contentType}
+		return goja.Undefined()
+	})
+	_ = respObj.Set("bytes", func(call goja.FunctionCall) goja.Value {
+		data, err := base64.StdEncoding.DecodeString(call.Argument(0).String())
+		if err != nil {
+			panic(vm.NewTypeError("response.bytes: invalid base64: %v", err))
+		}
+		*p = payload{set: true, body: data, contentType: "application/octet-stream"}
+		return goja.Undefined()
+	})
+	_ = respObj.Set("stream", func(call goja.FunctionCall) goja.Value {
+		list, ok := call.Argument(0).Export().([]interface{})
+		if !ok {
+			panic(vm.NewTypeError("response.stream: chunks must be an array"))
+		}
+		var interval time.Duration
+		if arg := call.Argument(1); !goja.IsUndefined(arg) {
+			var err error
+			if interval, err = parseMillis(arg.String()); err != nil || interval < 0 {
+				panic(vm.NewTypeError("response.stream: invalid interval %q", arg.String()))
+			}
+		}
+		chunks := make([][]byte, 0, len(list))
+		for _, chunk := range list {
+			chunks = append(chunks, encodeMessage(vm.ToValue(chunk)))
+		}
+		*p = payload{set: true, chunks: chunks, interval: interval, contentType: "text/plain; charset=utf-8"}
+		return goja.Undefined()
+	})
+}
+
+// writeChunks streams chunks with chunked transfer encoding, flushing each
+// one and waiting interval between them.
+func writeChunks(ctx context.Context, w http.ResponseWriter, status int, chunks [][]byte, interval time.Duration) {
+	w.Header().Del("Content-Length")
+	w.WriteHeader(status)
+	flusher, _ := w.(http.Flusher)
+	for i, chunk := range chunks {
+		if i > 0 && interval > 0 && !sleepContext(ctx, interval) {
+			return
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package mockserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMocksServeFilesBytesAndStreams(t *testing.T) {
+	dir := t.TempDir()
+	pdf := []byte("%PDF-1.4\x00\x01binary")
+	if err := os.MkdirAll(filepath.Join(dir, "fixtures"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fixtures", "report.pdf"), pdf, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := New(Config{
+		File: filepath.Join(dir, "api.http"),
+		Requests: []MockRequest{
+			{Method: "GET", URL: "/report", Directives: []string{"body-file ./fixtures/report.pdf"}},
+			{Method: "GET", URL: "/missing", Directives: []string{"body-file ./fixtures/nope.zip"}},
+			{Method: "GET", URL: "/scripted", PreScript: `response.file("fixtures/report.pdf");`},
+			{Method: "GET", URL: "/bytes", PreScript: `response.bytes("AAEC/w==");`},
+			{Method: "GET", URL: "/export", PreScript: `
+				response.headers["Content-Type"] = "application/x-ndjson";
+				response.stream([{ id: 1 }, "\n", { id: 2 }, "\n"], "50ms");
+			`},
+		},
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	base := fmt.Sprintf("http://127.0.0.1:%d", srv.Port())
+
+	get := func(path string) (*http.Response, []byte) {
+		t.Helper()
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", path, err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		return resp, data
+	}
+
+	for _, path := range []string{"/report", "/scripted"} {
+		resp, data := get(path)
+		if string(data) != string(pdf) || resp.Header.Get("Content-Type") != "application/pdf" {
+			t.Errorf("%s = %q (%s), want the PDF fixture", path, data, resp.Header.Get("Content-Type"))
+		}
+	}
+	if resp, _ := get("/missing"); resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("missing fixture status = %d, want 500", resp.StatusCode)
+	}
+	if resp, data := get("/bytes"); string(data) != "\x00\x01\x02\xff" || resp.Header.Get("Content-Type") != "application/octet-stream" {
+		t.Errorf("bytes = %v (%s)", data, resp.Header.Get("Content-Type"))
+	}
+
+	resp, err := http.Get(base + "/export")
+	if err != nil {
+		t.Fatalf("Get(/export) error = %v", err)
+	}
+	defer resp.Body.Close()
+	if len(resp.TransferEncoding) == 0 || resp.TransferEncoding[0] != "chunked" {
+		t.Errorf("TransferEncoding = %v, want chunked", resp.TransferEncoding)
+	}
+	reader := bufio.NewReader(resp.Body)
+	start := time.Now()
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) != 2 || lines[0] != "{\"id\":1}\n" || lines[1] != "{\"id\":2}\n" {
+		t.Errorf("stream lines = %q", lines)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("stream took %s, want the 50ms interval between chunks", elapsed)
+	}
+}
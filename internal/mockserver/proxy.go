@@ -0,0 +1,275 @@
+package mockserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	hcl "rawrequest/internal/httpclientlogic"
+)
+
+// hopHeaders are connection-level headers that must not be forwarded by a
+// proxy (RFC 9110 section 7.6.1).
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Authenticate", "Proxy-Authorization",
+	"Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// unrecordedHeaders are response headers that describe one particular
+// exchange and would be wrong if replayed by a mock.
+var unrecordedHeaders = map[string]bool{
+	"Content-Length":   true,
+	"Content-Encoding": true,
+	"Date":             true,
+	"Set-Cookie":       true,
+	"Age":              true,
+}
+
+// proxy forwards requests that match no route to an upstream and optionally
+// records each exchange as a @mock block.
+type proxy struct {
+	upstream   *url.URL
+	client     *http.Client
+	recordFile string
+
+	// mu serialises appends to recordFile.
+	mu sync.Mutex
+}
+
+func newProxy(rawURL, recordFile string) (*proxy, error) {
+	upstream, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || upstream.Scheme == "" || upstream.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: want scheme://host[/base]", rawURL)
+	}
+	return &proxy{
+		upstream: upstream,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+			// Hand redirects back to the caller like a transparent proxy would.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		recordFile: recordFile,
+	}, nil
+}
+
+// target joins the upstream base with the request path and query.
+func (p *proxy) target(r *http.Request) string {
+	u := *p.upstream
+	u.Path = strings.TrimSuffix(p.upstream.Path, "/") + r.URL.Path
+	u.RawPath = ""
+	u.RawQuery = r.URL.RawQuery
+	return u.String()
+}
+
+// proxyRequest forwards r upstream and copies the response back. body is
+// the already-read request body.
+func (s *Server) proxyRequest(w http.ResponseWriter, r *http.Request, body []byte) {
+	p := s.proxy
+	target := p.target(r)
+	out, err := http.NewRequestWithContext(r.Context(), r.Method, target, bytes.NewReader(body))
+	if err != nil {
+		s.writeProxyError(w, r, err)
+		return
+	}
+	out.Header = r.Header.Clone()
+	for _, h := range hopHeaders {
+		out.Header.Del(h)
+	}
+	// Let the transport negotiate compression so recorded bodies are
+	// readable text.
+	out.Header.Del("Accept-Encoding")
+
+	resp, err := p.client.Do(out)
+	if err != nil {
+		s.writeProxyError(w, r, err)
+		return
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		s.writeProxyError(w, r, err)
+		return
+	}
+
+	for k, values := range resp.Header {
+		for _, v := range values {
+			w.Header().Add(k, v)
+		}
+	}
+	for _, h := range hopHeaders {
+		w.Header().Del(h)
+	}
+	w.Header().Del("Content-Length")
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+
+	s.logf("info", "mockserver", "[Mock Server] Proxied: %s %s -> %d from %s\n", r.Method, r.URL.RequestURI(), resp.StatusCode, p.upstream.Host)
+
+	if p.recordFile == "" {
+		return
+	}
+	if err := p.record(r, resp, respBody); err != nil {
+		s.logf("error", "mockserver", "[Mock Server] Failed to record %s %s: %v\n", r.Method, r.URL.Path, err)
+	} else {
+		s.logf("info", "mockserver", "[Mock Server] Recorded %s %s into %s\n", r.Method, r.URL.RequestURI(), p.recordFile)
+	}
+}
+
+func (s *Server) writeProxyError(w http.ResponseWriter, r *http.Request, err error) {
+	s.logf("error", "mockserver", "[Mock Server] Proxy error for %s %s: %v\n", r.Method, r.URL.RequestURI(), err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadGateway)
+	_, _ = w.Write([]byte(fmt.Sprintf(`{"error": "Proxy request failed", "details": %q}`, err.Error())))
+}
+
+// record appends the exchange to the record file as a new block, even when
+// the same request was recorded before. A text body the .http parser would
+// misread is written to a fixture next to the record file instead.
+func (p *proxy) record(r *http.Request, resp *http.Response, body []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	bodyFile := ""
+	if utf8.Valid(body) && !bodySafeInline(string(body)) {
+		var err error
+		if bodyFile, err = p.writeBodyFixture(r.Method, resp.Header.Get("Content-Type"), body); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(p.recordFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.WriteString(formatRecordedMock(r, resp.StatusCode, resp.Header, body, bodyFile, time.Now()))
+	return err
+}
+
+// writeBodyFixture saves a recorded body under "<record file>.recorded/"
+// and returns its path relative to the record file, for @body-file.
+func (p *proxy) writeBodyFixture(method, contentType string, body []byte) (string, error) {
+	base := filepath.Base(p.recordFile)
+	dirName := strings.TrimSuffix(base, filepath.Ext(base)) + ".recorded"
+	dir := filepath.Join(filepath.Dir(p.recordFile), dirName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	// Only text bodies are written here, so prefer .txt to .bin.
+	ext := hcl.ExtensionForContentType(contentType)
+	if ext == ".bin" {
+		ext = ".txt"
+	}
+	f, err := os.CreateTemp(dir, strings.ToLower(method)+"-*"+ext)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := f.Write(body); err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(filepath.Join(dirName, filepath.Base(f.Name()))), nil
+}
+
+// bodySafeInline reports whether text can follow the headers of a block
+// verbatim: no line may look like a separator, comment, directive, script
+// marker or request line when the file is parsed again.
+func bodySafeInline(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		switch trimmed[0] {
+		case '#', '@', '<', '>':
+			return false
+		}
+		if strings.HasPrefix(trimmed, "//") {
+			return false
+		}
+		if fields := strings.Fields(trimmed); len(fields) > 1 && isMethodToken(fields[0]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isMethodToken(s string) bool {
+	switch s {
+	case "GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS":
+		return true
+	}
+	return false
+}
+
+// formatRecordedMock renders a proxied exchange as a @mock block. Query
+// parameters become @match conditions so the recorded block only answers
+// the request it was recorded from. A non-empty bodyFile replaces the
+// inline body with a @body-file directive.
+func formatRecordedMock(r *http.Request, status int, header http.Header, body []byte, bodyFile string, at time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n### Recorded %s %s at %s\n", r.Method, r.URL.Path, at.UTC().Format(time.RFC3339))
+	b.WriteString("@mock\n")
+
+	query := r.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "@match query %s=%s\n", k, query.Get(k))
+	}
+	if status != http.StatusOK {
+		fmt.Fprintf(&b, "@status %d\n", status)
+	}
+	if bodyFile != "" {
+		fmt.Fprintf(&b, "@body-file %s\n", bodyFile)
+	}
+	fmt.Fprintf(&b, "%s %s\n", r.Method, r.URL.Path)
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		canonical := http.CanonicalHeaderKey(name)
+		if unrecordedHeaders[canonical] || isHopHeader(canonical) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s: %s\n", name, header.Get(name))
+	}
+
+	switch {
+	case len(body) == 0 || bodyFile != "":
+	case !utf8.Valid(body):
+		fmt.Fprintf(&b, "\n# %d-byte binary body was not recorded\n", len(body))
+	default:
+		text := strings.TrimRight(string(body), "\r\n")
+		b.WriteString("\n")
+		b.WriteString(text)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func isHopHeader(name string) bool {
+	for _, h := range hopHeaders {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,149 @@
+package mockserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProxyForwardsUnmatchedRequestsAndRecordsThem(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Connection") == "close" {
+			t.Errorf("hop-by-hop header was forwarded")
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Upstream", "yes")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","q":"` + r.URL.Query().Get("q") + `","got":` + string(body) + `}`))
+	}))
+	defer upstream.Close()
+
+	recordFile := filepath.Join(t.TempDir(), "recorded.http")
+	srv := New(Config{
+		Requests:   []MockRequest{{Method: "GET", URL: "/local", Body: "local"}},
+		Proxy:      upstream.URL + "/api/",
+		RecordFile: recordFile,
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+
+	if body := getBody(t, srv.Port(), "/local"); body != "local" {
+		t.Fatalf("GET /local = %q, want the mock route to win over the proxy", body)
+	}
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("http://127.0.0.1:%d/orders?q=x", srv.Port()), strings.NewReader(`{"n":1}`))
+		req.Header.Set("Connection", "close")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusCreated || resp.Header.Get("X-Upstream") != "yes" {
+			t.Fatalf("proxied response = %d %v", resp.StatusCode, resp.Header)
+		}
+		if want := `{"path":"/api/orders","q":"x","got":{"n":1}}`; string(body) != want {
+			t.Fatalf("proxied body = %s, want %s", body, want)
+		}
+	}
+
+	data, err := os.ReadFile(recordFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	recorded := string(data)
+	if strings.Count(recorded, "@mock\n") != 2 {
+		t.Fatalf("expected a recorded block per exchange, got:\n%s", recorded)
+	}
+	for _, want := range []string{
+		"@match query q=x\n",
+		"@status 201\n",
+		"POST /orders\n",
+		"Content-Type: application/json\n",
+		"X-Upstream: yes\n",
+		"\n{\"path\":\"/api/orders\",\"q\":\"x\",\"got\":{\"n\":1}}\n",
+	} {
+		if !strings.Contains(recorded, want) {
+			t.Errorf("recorded block missing %q:\n%s", want, recorded)
+		}
+	}
+	if strings.Contains(recorded, "Date:") || strings.Contains(recorded, "Content-Length:") {
+		t.Errorf("recorded block kept per-exchange headers:\n%s", recorded)
+	}
+}
+
+func TestProxyRecordsUnsafeTextBodiesAsFixtures(t *testing.T) {
+	const doc = "### Usage\n@see docs\nGET /next\n# note\n"
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/markdown")
+		_, _ = w.Write([]byte(doc))
+	}))
+	defer upstream.Close()
+
+	recordFile := filepath.Join(t.TempDir(), "recorded.http")
+	srv := New(Config{Proxy: upstream.URL, RecordFile: recordFile})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+
+	if body := getBody(t, srv.Port(), "/readme"); body != doc {
+		t.Fatalf("proxied body = %q", body)
+	}
+
+	data, err := os.ReadFile(recordFile)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	recorded := string(data)
+	if strings.Contains(recorded, "### Usage") || strings.Contains(recorded, "@see") {
+		t.Fatalf("body was written inline:\n%s", recorded)
+	}
+	_, rest, ok := strings.Cut(recorded, "@body-file ")
+	if !ok {
+		t.Fatalf("recorded block has no @body-file:\n%s", recorded)
+	}
+	fixture, _, _ := strings.Cut(rest, "\n")
+	if !strings.HasPrefix(fixture, "recorded.recorded/get-") || !strings.HasSuffix(fixture, ".txt") {
+		t.Fatalf("fixture path = %q", fixture)
+	}
+	saved, err := os.ReadFile(filepath.Join(filepath.Dir(recordFile), fixture))
+	if err != nil || string(saved) != doc {
+		t.Fatalf("fixture = %q, %v", saved, err)
+	}
+}
+
+func TestBodySafeInline(t *testing.T) {
+	cases := map[string]bool{
+		`{"id":1}`:                 true,
+		"line one\n  indented":     true,
+		"text\n### heading":        false,
+		"@name x":                  false,
+		"// comment":               false,
+		"> {% script %}":           false,
+		"POST\thttp://example.com": false,
+		"GETTING started":          true,
+	}
+	for text, want := range cases {
+		if got := bodySafeInline(text); got != want {
+			t.Errorf("bodySafeInline(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestNewProxyRejectsRelativeURL(t *testing.T) {
+	srv := New(Config{Proxy: "staging.example.com"})
+	if err := srv.Start(); err == nil {
+		_ = srv.Close()
+		t.Fatal("Start() accepted a proxy URL without a scheme")
+	}
+}
@@ -0,0 +1,406 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rawrequest/internal/eventloop"
+
+	"github.com/dop251/goja"
+	"github.com/gorilla/websocket"
+)
+
+// Route methods of the streaming mocks declared with "@mock ws /path" and
+// "@mock sse /path".
+const (
+	MethodWebSocket = "WS"
+	MethodSSE       = "SSE"
+)
+
+const socketWriteTimeout = 10 * time.Second
+
+var socketUpgrader = websocket.Upgrader{
+	// Mocks stand in for services on other origins, so any page may connect.
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// acceptsMethod reports whether r uses the method route was declared for.
+// WebSocket routes take upgrade requests and SSE routes plain GETs.
+func (route *Route) acceptsMethod(r *http.Request) bool {
+	method := strings.ToUpper(r.Method)
+	upgrade := websocket.IsWebSocketUpgrade(r)
+	switch route.Method {
+	case MethodWebSocket:
+		return method == http.MethodGet && upgrade
+	case MethodSSE:
+		return method == http.MethodGet && !upgrade
+	}
+	return route.Method == method
+}
+
+// socketHub holds the open connections of one WebSocket endpoint so
+// socket.broadcast reaches every client.
+type socketHub struct {
+	mu      sync.Mutex
+	clients map[*mockSocket]struct{}
+}
+
+type mockSocket struct {
+	id      string
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+func (c *mockSocket) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(socketWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+func (c *mockSocket) close(code int, reason string) {
+	c.writeMu.Lock()
+	_ = c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+	_ = c.conn.Close()
+}
+
+func (h *socketHub) add(c *mockSocket) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clients[c] = struct{}{}
+}
+
+func (h *socketHub) remove(c *mockSocket) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, c)
+}
+
+func (h *socketHub) snapshot() []*mockSocket {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	clients := make([]*mockSocket, 0, len(h.clients))
+	for c := range h.clients {
+		clients = append(clients, c)
+	}
+	return clients
+}
+
+// broadcast sends data to every client and returns how many received it.
+func (h *socketHub) broadcast(data []byte) int {
+	sent := 0
+	for _, c := range h.snapshot() {
+		if c.write(data) == nil {
+			sent++
+		}
+	}
+	return sent
+}
+
+// hub returns the connection hub of the WebSocket endpoint pattern.
+func (s *Server) hub(pattern string) *socketHub {
+	s.socketsMu.Lock()
+	defer s.socketsMu.Unlock()
+	if s.sockets == nil {
+		s.sockets = make(map[string]*socketHub)
+	}
+	h, ok := s.sockets[pattern]
+	if !ok {
+		h = &socketHub{clients: make(map[*mockSocket]struct{})}
+		s.sockets[pattern] = h
+	}
+	return h
+}
+
+// closeSockets closes every WebSocket connection. http.Server.Close does not
+// track hijacked connections, so Close calls this itself.
+func (s *Server) closeSockets() {
+	s.socketsMu.Lock()
+	hubs := make([]*socketHub, 0, len(s.sockets))
+	for _, h := range s.sockets {
+		hubs = append(hubs, h)
+	}
+	s.socketsMu.Unlock()
+	for _, h := range hubs {
+		for _, c := range h.snapshot() {
+			c.close(websocket.CloseGoingAway, "mock server stopped")
+		}
+	}
+}
+
+// serveWebSocket upgrades r and runs the block's script for the connection.
+// The script may define onConnect(socket), onMessage(socket, message) and
+// onClose(socket); a block without onConnect sends its body on connect.
+func (s *Server) serveWebSocket(w http.ResponseWriter, r *http.Request, route *Route, params map[string]string) {
+	header := http.Header{}
+	for k, v := range route.Request.Headers {
+		header.Set(k, v)
+	}
+	conn, err := socketUpgrader.Upgrade(w, r, header)
+	if err != nil {
+		s.logf("warn", "mockserver", "[Mock Server] WebSocket upgrade failed for %s: %v\n", r.URL.Path, err)
+		return
+	}
+
+	socket := &mockSocket{id: strconv.FormatInt(s.socketSeq.Add(1), 10), conn: conn}
+	hub := s.hub(route.PathPattern)
+	hub.add(socket)
+	defer func() {
+		hub.remove(socket)
+		_ = conn.Close()
+		s.logf("info", "mockserver", "[Mock Server] WebSocket %s closed: %s\n", socket.id, r.URL.Path)
+	}()
+	s.logf("info", "mockserver", "[Mock Server] WebSocket %s connected: %s\n", socket.id, r.URL.Path)
+
+	vm := goja.New()
+	s.installStreamGlobals(vm, r, route, params)
+	loop := eventloop.Install(vm)
+
+	socketObj := vm.NewObject()
+	_ = socketObj.Set("id", socket.id)
+	_ = socketObj.Set("send", func(call goja.FunctionCall) goja.Value {
+		if err := socket.write(encodeMessage(call.Argument(0))); err != nil {
+			panic(vm.NewGoError(fmt.Errorf("socket.send: %w", err)))
+		}
+		return goja.Undefined()
+	})
+	_ = socketObj.Set("broadcast", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(hub.broadcast(encodeMessage(call.Argument(0))))
+	})
+	_ = socketObj.Set("clients", func(goja.FunctionCall) goja.Value {
+		return vm.ToValue(len(hub.snapshot()))
+	})
+	_ = socketObj.Set("close", func(call goja.FunctionCall) goja.Value {
+		code := websocket.CloseNormalClosure
+		if arg := call.Argument(0); !goja.IsUndefined(arg) {
+			code = int(arg.ToInteger())
+		}
+		reason := ""
+		if arg := call.Argument(1); !goja.IsUndefined(arg) {
+			reason = arg.String()
+		}
+		socket.close(code, reason)
+		return goja.Undefined()
+	})
+
+	if _, err := vm.RunString(mockScript(route.Request)); err != nil {
+		s.logf("error", "console", "[Mock Script Error] Runtime Exception: %v\n", err)
+		socket.close(websocket.CloseInternalServerErr, "mock script failed")
+		return
+	}
+	call := func(name string, args ...goja.Value) {
+		fn, ok := goja.AssertFunction(vm.Get(name))
+		if !ok {
+			return
+		}
+		if _, err := fn(goja.Undefined(), args...); err != nil {
+			s.logf("error", "console", "[Mock Script Error] %s: %v\n", name, err)
+		}
+	}
+
+	if _, ok := goja.AssertFunction(vm.Get("onConnect")); ok {
+		call("onConnect", socketObj)
+	} else if route.Request.Body != "" {
+		_ = socket.write([]byte(route.Request.Body))
+	}
+
+	messages := make(chan []byte)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(messages)
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			select {
+			case messages <- data:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// Messages and timers are handled on this goroutine, which owns the VM.
+	for {
+		var timer *time.Timer
+		var due <-chan time.Time
+		if wait, ok := loop.Wait(); ok {
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+		select {
+		case data, ok := <-messages:
+			if !ok {
+				call("onClose", socketObj)
+				return
+			}
+			call("onMessage", socketObj, vm.ToValue(string(data)))
+		case <-due:
+			if err := loop.RunDue(); err != nil {
+				s.logf("error", "console", "[Mock Script Error] timer: %v\n", err)
+			}
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// serveSSE streams Server-Sent Events. A block with a script emits events
+// with sse.send, sleep and timers, and the stream ends when the script
+// finishes with no timers left; a block without one sends its body verbatim.
+func (s *Server) serveSSE(w http.ResponseWriter, r *http.Request, route *Route, params map[string]string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	for k, v := range route.Request.Headers {
+		w.Header().Set(k, v)
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(route.status())
+	flusher.Flush()
+
+	script := mockScript(route.Request)
+	if strings.TrimSpace(script) == "" {
+		body := strings.TrimRight(route.Request.Body, "\n")
+		_, _ = io.WriteString(w, body+"\n\n")
+		flusher.Flush()
+		return
+	}
+
+	vm := goja.New()
+	s.installStreamGlobals(vm, r, route, params)
+	loop := eventloop.Install(vm)
+
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-r.Context().Done():
+			vm.Interrupt("client disconnected")
+		case <-finished:
+		}
+	}()
+
+	sseObj := vm.NewObject()
+	_ = sseObj.Set("send", func(call goja.FunctionCall) goja.Value {
+		var event, id string
+		retry := 0
+		if opts := call.Argument(1); !goja.IsUndefined(opts) && !goja.IsNull(opts) {
+			if obj := opts.ToObject(vm); obj != nil {
+				if v := obj.Get("event"); v != nil && !goja.IsUndefined(v) {
+					event = v.String()
+				}
+				if v := obj.Get("id"); v != nil && !goja.IsUndefined(v) {
+					id = v.String()
+				}
+				if v := obj.Get("retry"); v != nil && !goja.IsUndefined(v) {
+					retry = int(v.ToInteger())
+				}
+			}
+		}
+		if err := writeSSEEvent(w, event, id, retry, string(encodeMessage(call.Argument(0)))); err != nil {
+			panic(vm.NewGoError(fmt.Errorf("sse.send: %w", err)))
+		}
+		flusher.Flush()
+		return goja.Undefined()
+	})
+	_ = vm.Set("sse", sseObj)
+	_ = vm.Set("sleep", func(call goja.FunctionCall) goja.Value {
+		d, err := parseMillis(call.Argument(0).String())
+		if err != nil {
+			panic(vm.NewTypeError("sleep: %v", err))
+		}
+		sleepContext(r.Context(), d)
+		return goja.Undefined()
+	})
+
+	err := func() error {
+		if _, err := vm.RunString(script); err != nil {
+			return err
+		}
+		return loop.Run(nil, r.Context().Done())
+	}()
+	var interrupted *goja.InterruptedError
+	if err != nil && !errors.As(err, &interrupted) {
+		s.logf("error", "console", "[Mock Script Error] Runtime Exception: %v\n", err)
+		_ = writeSSEEvent(w, "error", "", 0, err.Error())
+		flusher.Flush()
+	}
+}
+
+// writeSSEEvent writes one event; multi-line data becomes several data
+// fields as the SSE format requires.
+func writeSSEEvent(w io.Writer, event, id string, retry int, data string) error {
+	var b strings.Builder
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	if retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", retry)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// encodeMessage sends strings and byte arrays as-is and everything else as
+// JSON.
+func encodeMessage(value goja.Value) []byte {
+	if value == nil || goja.IsUndefined(value) || goja.IsNull(value) {
+		return nil
+	}
+	exported := value.Export()
+	switch v := exported.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return append([]byte(nil), v...)
+	case goja.ArrayBuffer:
+		return append([]byte(nil), v.Bytes()...)
+	}
+	data, err := json.Marshal(exported)
+	if err != nil {
+		return []byte(value.String())
+	}
+	return data
+}
+
+// installStreamGlobals gives a streaming mock script the request and the
+// globals every mock script shares.
+func (s *Server) installStreamGlobals(vm *goja.Runtime, r *http.Request, route *Route, params map[string]string) {
+	_ = vm.Set("request", newRequestObject(vm, r, route.Method, params, nil))
+	s.installScriptGlobals(r.Context(), vm, s.database(), "[Mock Script Log]", scriptSource("mock", route.Request))
+}
+
+// mockScript joins the pre and post scripts of a block.
+func mockScript(req MockRequest) string {
+	script := ""
+	if req.PreScript != "" {
+		script += cleanScript(req.PreScript) + "\n"
+	}
+	if req.PostScript != "" {
+		script += cleanScript(req.PostScript) + "\n"
+	}
+	return script
+}
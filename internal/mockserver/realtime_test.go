@@ -0,0 +1,167 @@
+package mockserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketMockRunsHandlersAndBroadcasts(t *testing.T) {
+	srv := New(Config{Requests: []MockRequest{{
+		Method: MethodWebSocket,
+		URL:    "/notifications/:user",
+		PreScript: `< {
+function onConnect(socket) {
+  socket.send({ hello: request.params.user, id: socket.id });
+}
+function onMessage(socket, message) {
+  if (message === "later") {
+    setTimeout(function () { socket.send("timer fired"); }, 20);
+    return;
+  }
+  socket.broadcast(request.params.user + ": " + message);
+}
+}`,
+	}}})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	base := fmt.Sprintf("ws://127.0.0.1:%d/notifications/", srv.Port())
+
+	alice := dialSocket(t, base+"alice")
+	if got := readSocket(t, alice); !strings.Contains(got, `"hello":"alice"`) {
+		t.Fatalf("greeting = %s", got)
+	}
+	bob := dialSocket(t, base+"bob")
+	readSocket(t, bob)
+
+	if err := alice.WriteMessage(websocket.TextMessage, []byte("hi all")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	for _, conn := range []*websocket.Conn{alice, bob} {
+		if got := readSocket(t, conn); got != "alice: hi all" {
+			t.Fatalf("broadcast = %q, want alice: hi all", got)
+		}
+	}
+
+	if err := bob.WriteMessage(websocket.TextMessage, []byte("later")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if got := readSocket(t, bob); got != "timer fired" {
+		t.Fatalf("timer message = %q", got)
+	}
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/notifications/alice", srv.Port()))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("plain GET on a ws route = %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestSSEMockStreamsScriptedEvents(t *testing.T) {
+	srv := New(Config{Requests: []MockRequest{
+		{
+			Method: MethodSSE,
+			URL:    "/events",
+			PreScript: `< {
+for (var i = 1; i <= 2; i++) {
+  sse.send({ n: i }, { event: "tick", id: String(i) });
+  sleep(10);
+}
+var left = 2;
+var timer = setInterval(function () {
+  sse.send("line one\nline two");
+  if (--left === 0) clearInterval(timer);
+}, 10);
+}`,
+		},
+		{Method: MethodSSE, URL: "/static", Body: "event: ready\ndata: {\"ok\":true}"},
+	}})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d/events", srv.Port()))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	want := "event: tick\nid: 1\ndata: {\"n\":1}\n\n" +
+		"event: tick\nid: 2\ndata: {\"n\":2}\n\n" +
+		"data: line one\ndata: line two\n\n" +
+		"data: line one\ndata: line two\n\n"
+	if string(body) != want {
+		t.Fatalf("stream = %q, want %q", body, want)
+	}
+
+	resp, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/static", srv.Port()))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if line != "event: ready\n" {
+		t.Fatalf("static stream starts with %q", line)
+	}
+}
+
+func TestCloseEndsOpenWebSockets(t *testing.T) {
+	srv := New(Config{Requests: []MockRequest{{Method: MethodWebSocket, URL: "/ws", Body: "welcome"}}})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	conn := dialSocket(t, fmt.Sprintf("ws://127.0.0.1:%d/ws", srv.Port()))
+	if got := readSocket(t, conn); got != "welcome" {
+		t.Fatalf("body message = %q", got)
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- srv.Close() }()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() hung on an open WebSocket")
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Fatalf("ReadMessage() after Close = %v, want going-away close", err)
+	}
+}
+
+func dialSocket(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial(%s) error = %v", url, err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func readSocket(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	return string(data)
+}
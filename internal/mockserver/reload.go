@@ -0,0 +1,188 @@
+package mockserver
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"rawrequest/internal/filewatch"
+)
+
+// DefaultWatchInterval is how often WatchFile polls the source file.
+const DefaultWatchInterval = time.Second
+
+// RouteDiff lists the routes a reload added, removed or changed, as
+// "METHOD /path" labels.
+type RouteDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty reports whether the reload left every route as it was.
+func (d RouteDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Reload recompiles the routes from requests and swaps them in atomically.
+// Requests already being served finish on the old routes; the database and
+// listener are kept, and @mockinit does not run again.
+func (s *Server) Reload(requests []MockRequest) RouteDiff {
+	routes := compileRoutes(requests)
+	var previous []Route
+	if old := s.routes.Swap(&routes); old != nil {
+		previous = *old
+	}
+	diff := diffRoutes(previous, routes)
+
+	s.mu.Lock()
+	initChanged := !reflect.DeepEqual(initRequests(s.cfg.Requests), initRequests(requests))
+	s.cfg.Requests = requests
+	s.mu.Unlock()
+
+	if diff.Empty() {
+		s.logf("info", "mockserver", "[Mock Server] Reloaded %s: routes unchanged\n", s.cfg.File)
+	} else {
+		s.logf("info", "mockserver", "[Mock Server] Reloaded %s: %d added, %d removed, %d changed\n",
+			s.cfg.File, len(diff.Added), len(diff.Removed), len(diff.Changed))
+	}
+	for _, label := range diff.Added {
+		s.logf("info", "mockserver", "  + %s\n", label)
+	}
+	for _, label := range diff.Removed {
+		s.logf("info", "mockserver", "  - %s\n", label)
+	}
+	for _, label := range diff.Changed {
+		s.logf("info", "mockserver", "  ~ %s\n", label)
+	}
+	s.logRouteWarnings(routes)
+	if initChanged {
+		s.logf("warn", "mockserver", "[Mock Server] @mockinit changed; restart the server or POST %sreset to run it again\n", AdminPrefix)
+	}
+	return diff
+}
+
+// WatchFile polls path and reloads the routes whenever its content changes,
+// using parse to turn the file into requests. Like the desktop file watcher
+// it only re-reads the file when the mtime advances and only reloads when the
+// content hash differs, so a touch or a save of identical bytes is ignored.
+// Call it after Start; watching stops when the server stops.
+func (s *Server) WatchFile(path string, interval time.Duration, parse func(content []byte) []MockRequest) {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+
+	var modTime time.Time
+	var contentHash string
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		contentHash = filewatch.HashContent(data)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			info, err := os.Stat(path)
+			if err != nil || !info.ModTime().After(modTime) {
+				continue
+			}
+			modTime = info.ModTime()
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			newHash := filewatch.HashContent(data)
+			if newHash == contentHash {
+				continue
+			}
+			contentHash = newHash
+
+			requests := parse(data)
+			if len(compileRoutes(requests)) == 0 {
+				s.logf("warn", "mockserver", "[Mock Server] %s has no mock endpoints; keeping the current routes\n", path)
+				continue
+			}
+			s.Reload(requests)
+		}
+	}()
+}
+
+func initRequests(requests []MockRequest) []MockRequest {
+	var out []MockRequest
+	for _, req := range requests {
+		if req.Method == "MOCKINIT" {
+			out = append(out, req)
+		}
+	}
+	return out
+}
+
+// diffRoutes compares two route tables by method and path. Repeated method and
+// path pairs are matched in file order.
+func diffRoutes(before, after []Route) RouteDiff {
+	var diff RouteDiff
+	old := routesByKey(before)
+	seen := make(map[string]bool, len(after))
+	for _, key := range routeKeys(after) {
+		seen[key.key] = true
+		prev, ok := old[key.key]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, key.label)
+		case !reflect.DeepEqual(prev.Request, key.route.Request):
+			diff.Changed = append(diff.Changed, key.label)
+		}
+	}
+	for _, key := range routeKeys(before) {
+		if !seen[key.key] {
+			diff.Removed = append(diff.Removed, key.label)
+		}
+	}
+	return diff
+}
+
+type keyedRoute struct {
+	key   string
+	label string
+	route Route
+}
+
+func routeKeys(routes []Route) []keyedRoute {
+	counts := make(map[string]int, len(routes))
+	keyed := make([]keyedRoute, 0, len(routes))
+	for _, route := range routes {
+		label := route.Method + " " + route.PathPattern
+		counts[label]++
+		key := label
+		if n := counts[label]; n > 1 {
+			key = fmt.Sprintf("%s#%d", label, n)
+			label = fmt.Sprintf("%s (#%d)", label, n)
+		}
+		keyed = append(keyed, keyedRoute{key: key, label: label, route: route})
+	}
+	return keyed
+}
+
+func routesByKey(routes []Route) map[string]Route {
+	byKey := make(map[string]Route, len(routes))
+	for _, key := range routeKeys(routes) {
+		byKey[key.key] = key.route
+	}
+	return byKey
+}
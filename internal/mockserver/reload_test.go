@@ -0,0 +1,132 @@
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReloadReportsRouteDiff(t *testing.T) {
+	srv := New(Config{File: "api.http"})
+	srv.Reload([]MockRequest{
+		{Method: "GET", URL: "/users", Body: "[]"},
+		{Method: "GET", URL: "/orders", Body: "[]"},
+		{Method: "DELETE", URL: "/users/:id"},
+	})
+
+	diff := srv.Reload([]MockRequest{
+		{Method: "GET", URL: "/users", Body: `[{"id":1}]`},
+		{Method: "DELETE", URL: "/users/:id"},
+		{Method: "POST", URL: "/users"},
+	})
+
+	want := RouteDiff{
+		Added:   []string{"POST /users"},
+		Removed: []string{"GET /orders"},
+		Changed: []string{"GET /users"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("diff = %+v, want %+v", diff, want)
+	}
+	if diff := srv.Reload(srv.Config().Requests); !diff.Empty() {
+		t.Fatalf("reloading the same requests reported %+v", diff)
+	}
+}
+
+func TestWatchFileSwapsRoutesAndKeepsDatabase(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "api.http")
+	writeRoutes := func(content string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+		if err := os.Chtimes(file, mtime, mtime); err != nil {
+			t.Fatalf("Chtimes() error = %v", err)
+		}
+	}
+	// Each line is "METHOD PATH BODY"; enough to drive the watcher without
+	// the .http parser, which lives in the cli package.
+	parse := func(content []byte) []MockRequest {
+		requests := []MockRequest{{Method: "MOCKINIT", PreScript: `db.exec("CREATE TABLE hits (n INTEGER)");`}}
+		for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+			parts := strings.SplitN(line, " ", 3)
+			if len(parts) == 3 {
+				requests = append(requests, MockRequest{Method: parts[0], URL: parts[1], Body: parts[2]})
+			}
+		}
+		return requests
+	}
+
+	start := time.Now().Add(-time.Hour)
+	writeRoutes("GET /version v1", start)
+	requests := parse([]byte("GET /version v1"))
+	requests = append(requests, MockRequest{Method: "POST", URL: "/hits", PreScript: `
+		db.exec("INSERT INTO hits (n) VALUES (1)");
+		response.body = db.get("SELECT COUNT(*) AS count FROM hits");
+	`})
+	srv := New(Config{File: file, DBPath: filepath.Join(dir, "mock.db"), Requests: requests})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	waitForServer(t, srv.Port(), "/version")
+
+	hits := func() float64 {
+		t.Helper()
+		resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d/hits", srv.Port()), "application/json", nil)
+		if err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+		defer resp.Body.Close()
+		var got map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		return got["count"].(float64)
+	}
+	if n := hits(); n != 1 {
+		t.Fatalf("hits = %v, want 1", n)
+	}
+
+	srv.WatchFile(file, 10*time.Millisecond, func(content []byte) []MockRequest {
+		return append(parse(content), MockRequest{Method: "POST", URL: "/hits", PreScript: `
+			db.exec("INSERT INTO hits (n) VALUES (1)");
+			response.body = db.get("SELECT COUNT(*) AS count FROM hits");
+		`})
+	})
+	writeRoutes("GET /version v2\nGET /new fresh", start.Add(time.Minute))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for getBody(t, srv.Port(), "/version") != "v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("routes were not reloaded after the file changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if body := getBody(t, srv.Port(), "/new"); body != "fresh" {
+		t.Fatalf("GET /new = %q, want fresh", body)
+	}
+	// The database connection survives the reload, so earlier rows remain.
+	if n := hits(); n != 2 {
+		t.Fatalf("hits after reload = %v, want 2", n)
+	}
+}
+
+func getBody(t *testing.T, port int, path string) string {
+	t.Helper()
+	resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, path))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return string(data)
+}
@@ -0,0 +1,176 @@
+package mockserver
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/dop251/goja"
+)
+
+// ScenarioStarted is the state every scenario begins in and returns to on
+// reset.
+const ScenarioStarted = "started"
+
+// Scenario ties a route to a named state machine, set with
+// "@scenario checkout state=started next=paid". The route only matches while
+// the scenario is in State (any state when empty) and, once it answers,
+// moves the scenario to Next (unchanged when empty).
+type Scenario struct {
+	Name  string
+	State string
+	Next  string
+}
+
+// parseScenario parses "<name> [state=<s>] [next=<n>]".
+func parseScenario(raw string) (*Scenario, error) {
+	fields := strings.Fields(raw)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("missing scenario name")
+	}
+	sc := &Scenario{Name: fields[0]}
+	if strings.Contains(sc.Name, "=") {
+		return nil, fmt.Errorf("missing scenario name before %q", sc.Name)
+	}
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("expected key=value, got %q", field)
+		}
+		switch strings.ToLower(key) {
+		case "state":
+			sc.State = value
+		case "next":
+			sc.Next = value
+		default:
+			return nil, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return sc, nil
+}
+
+func (sc *Scenario) String() string {
+	label := "scenario " + sc.Name
+	if sc.State != "" {
+		label += " state=" + sc.State
+	}
+	if sc.Next != "" {
+		label += " next=" + sc.Next
+	}
+	return label
+}
+
+// scenarioStore holds the current state of each scenario by name.
+type scenarioStore struct {
+	mu     sync.Mutex
+	states map[string]string
+}
+
+func (st *scenarioStore) get(name string) string {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if state, ok := st.states[name]; ok {
+		return state
+	}
+	return ScenarioStarted
+}
+
+func (st *scenarioStore) set(name, state string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if st.states == nil {
+		st.states = make(map[string]string)
+	}
+	st.states[name] = state
+}
+
+// advance moves name from "from" to "to" and reports whether it did; a
+// concurrent request that already moved the scenario wins.
+func (st *scenarioStore) advance(name, from, to string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	current, ok := st.states[name]
+	if !ok {
+		current = ScenarioStarted
+	}
+	if from != "" && current != from {
+		return false
+	}
+	if st.states == nil {
+		st.states = make(map[string]string)
+	}
+	st.states[name] = to
+	return true
+}
+
+func (st *scenarioStore) reset() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.states = nil
+}
+
+// Scenarios returns the current state of every scenario the routes declare
+// or scripts have set.
+func (s *Server) Scenarios() map[string]string {
+	out := make(map[string]string)
+	if routes := s.routes.Load(); routes != nil {
+		for _, route := range *routes {
+			if route.Scenario != nil {
+				out[route.Scenario.Name] = ScenarioStarted
+			}
+		}
+	}
+	s.scenarios.mu.Lock()
+	for name, state := range s.scenarios.states {
+		out[name] = state
+	}
+	s.scenarios.mu.Unlock()
+	return out
+}
+
+// SetScenarioState moves scenario name to state.
+func (s *Server) SetScenarioState(name, state string) {
+	s.scenarios.set(name, state)
+	s.logf("info", "mockserver", "[Mock Server] Scenario %s -> %s\n", name, state)
+}
+
+// acceptsScenario reports whether the route's scenario is in its required
+// state.
+func (s *Server) acceptsScenario(route *Route) bool {
+	if route.Scenario == nil || route.Scenario.State == "" {
+		return true
+	}
+	return s.scenarios.get(route.Scenario.Name) == route.Scenario.State
+}
+
+// advanceScenario applies the route's transition after it matched.
+func (s *Server) advanceScenario(route *Route) {
+	sc := route.Scenario
+	if sc == nil || sc.Next == "" {
+		return
+	}
+	if s.scenarios.advance(sc.Name, sc.State, sc.Next) {
+		s.logf("info", "mockserver", "[Mock Server] Scenario %s -> %s\n", sc.Name, sc.Next)
+	}
+}
+
+// installScenarioObject adds scenario.get(name), scenario.set(name, state)
+// and scenario.all() to a mock script.
+func (s *Server) installScenarioObject(vm *goja.Runtime) {
+	obj := vm.NewObject()
+	_ = obj.Set("get", func(call goja.FunctionCall) goja.Value {
+		return vm.ToValue(s.scenarios.get(call.Argument(0).String()))
+	})
+	_ = obj.Set("set", func(call goja.FunctionCall) goja.Value {
+		name, state := call.Argument(0).String(), call.Argument(1).String()
+		if name == "" || goja.IsUndefined(call.Argument(1)) {
+			panic(vm.NewTypeError("scenario.set requires a name and a state"))
+		}
+		s.SetScenarioState(name, state)
+		return goja.Undefined()
+	})
+	_ = obj.Set("all", func(goja.FunctionCall) goja.Value {
+		return vm.ToValue(s.Scenarios())
+	})
+	_ = vm.Set("scenario", obj)
+}
@@ -0,0 +1,84 @@
+package mockserver
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseScenario(t *testing.T) {
+	sc, err := parseScenario("checkout state=started next=paid")
+	if err != nil || sc.Name != "checkout" || sc.State != "started" || sc.Next != "paid" {
+		t.Fatalf("parseScenario() = %+v, %v", sc, err)
+	}
+	for _, bad := range []string{"", "state=started", "checkout when=paid", "checkout next"} {
+		if _, err := parseScenario(bad); err == nil {
+			t.Errorf("parseScenario(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestScenarioAdvancesBetweenResponses(t *testing.T) {
+	srv := New(Config{Requests: []MockRequest{
+		{Method: "GET", URL: "/payment", Body: `{"status":"pending"}`, Directives: []string{"scenario checkout state=started"}},
+		{Method: "POST", URL: "/payment/confirm", Body: `{"ok":true}`, Directives: []string{"scenario checkout state=started next=paid"}},
+		{Method: "GET", URL: "/payment", Body: `{"status":"paid"}`, Directives: []string{"scenario checkout state=paid"}},
+		{Method: "POST", URL: "/payment/refund", PreScript: `
+			scenario.set("checkout", "refunded");
+			response.body = { was: "paid", now: scenario.get("checkout") };
+		`},
+		{Method: "GET", URL: "/payment", Body: `{"status":"unknown"}`},
+	}})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	port := srv.Port()
+	post := func(path string) string {
+		t.Helper()
+		resp, err := http.Post(fmt.Sprintf("http://127.0.0.1:%d%s", port, path), "application/json", nil)
+		if err != nil {
+			t.Fatalf("Post(%s) error = %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if got := getBody(t, port, "/payment"); got != `{"status":"pending"}` {
+		t.Fatalf("before confirm = %s", got)
+	}
+	post("/payment/confirm")
+	if got := getBody(t, port, "/payment"); got != `{"status":"paid"}` {
+		t.Fatalf("after confirm = %s", got)
+	}
+	if got := post("/payment/confirm"); !strings.Contains(got, "Resource not matched") {
+		t.Fatalf("second confirm = %s, want no match outside state started", got)
+	}
+
+	if got := post("/payment/refund"); got != `{"now":"refunded","was":"paid"}` {
+		t.Fatalf("script transition = %s", got)
+	}
+	if got := getBody(t, port, "/payment"); got != `{"status":"unknown"}` {
+		t.Fatalf("in refunded state = %s, want the unconditional fallback", got)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("http://127.0.0.1:%d/__rawrequest/scenarios/checkout", port), strings.NewReader(`{"state":"paid"}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("PUT scenario = %v, %v", resp, err)
+	}
+	resp.Body.Close()
+	if states := getJSON[map[string]string](t, port, "/__rawrequest/scenarios"); states["checkout"] != "paid" {
+		t.Fatalf("scenarios = %v", states)
+	}
+
+	if _, err := srv.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if got := getBody(t, port, "/payment"); got != `{"status":"pending"}` {
+		t.Fatalf("after reset = %s", got)
+	}
+}
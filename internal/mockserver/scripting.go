@@ -0,0 +1,233 @@
+package mockserver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	sh "rawrequest/internal/scripthelpers"
+	so "rawrequest/internal/scriptops"
+	sr "rawrequest/internal/scriptruntime"
+
+	"github.com/dop251/goja"
+)
+
+// scriptVars is the key/value store mock scripts share through setVar and
+// getVar. It starts from Config.Variables and keeps its values across
+// requests until Reset.
+type scriptVars struct {
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func (v *scriptVars) get(key string) (string, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	value, ok := v.values[key]
+	return value, ok
+}
+
+func (v *scriptVars) set(key, value string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.values == nil {
+		v.values = make(map[string]string)
+	}
+	v.values[key] = value
+}
+
+// reset replaces the store with a copy of seed.
+func (v *scriptVars) reset(seed map[string]string) {
+	values := make(map[string]string, len(seed))
+	for k, val := range seed {
+		values[k] = val
+	}
+	v.mu.Lock()
+	v.values = values
+	v.mu.Unlock()
+}
+
+// acquireVM takes a runtime from the pool of request scripts. Every global
+// a mock script relies on is set again before each run.
+func (s *Server) acquireVM() *goja.Runtime {
+	if vm, ok := s.vms.Get().(*goja.Runtime); ok {
+		return vm
+	}
+	return goja.New()
+}
+
+// releaseVM returns vm to the pool once the globals the script added are
+// removed, so nothing a script stores on globalThis reaches a later request.
+// Like the client script engine, callers only release runtimes whose script
+// completed: an exception can leave the call stack dirty.
+func (s *Server) releaseVM(vm *goja.Runtime) {
+	if !resetGlobals(vm) {
+		return
+	}
+	vm.ClearInterrupt()
+	s.vms.Put(vm)
+}
+
+// builtinGlobals names the globals of a fresh runtime.
+var builtinGlobals = sync.OnceValue(func() map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range goja.New().GlobalObject().GetOwnPropertyNames() {
+		names[name] = true
+	}
+	return names
+})
+
+// resetGlobals deletes every global vm has beyond the builtins. It reports
+// false when one cannot be deleted; such a runtime must not be reused.
+func resetGlobals(vm *goja.Runtime) bool {
+	global := vm.GlobalObject()
+	builtins := builtinGlobals()
+	for _, name := range global.GetOwnPropertyNames() {
+		if builtins[name] {
+			continue
+		}
+		if err := global.Delete(name); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// scriptSource names a mock script in log entries, e.g. "mock:getUser" or
+// "mock:GET /users/:id", the way client scripts are named.
+func scriptSource(stage string, req MockRequest) string {
+	return sr.BuildSource(&sr.ExecutionContext{
+		Stage:   stage,
+		Request: map[string]interface{}{"name": req.Name, "method": req.Method, "url": req.URL},
+	})
+}
+
+// installScriptGlobals gives a mock script what every kind of mock script
+// shares: console, setVar/getVar, assert, delay, env, scenario and, with a
+// database, db. db is passed in because @mockinit runs while the server lock
+// is held; delay returns early once ctx ends.
+func (s *Server) installScriptGlobals(ctx context.Context, vm *goja.Runtime, db *sql.DB, prefix, source string) {
+	s.installConsole(vm, prefix, source)
+	s.installScenarioObject(vm)
+
+	// assert and delay behave like the client script helpers, except that a
+	// failed assert fails the mock script, which answers with a 500.
+	_ = vm.Set("assert", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 || call.Arguments[0].ToBoolean() {
+			return goja.Undefined()
+		}
+		message := "Assertion failed"
+		if len(call.Arguments) > 1 {
+			message = call.Arguments[1].String()
+		}
+		failure, err := vm.New(vm.Get("Error"), vm.ToValue(message))
+		if err != nil {
+			panic(vm.NewGoError(errors.New(message)))
+		}
+		panic(failure)
+	})
+	_ = vm.Set("delay", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+		if duration, ok := sh.DurationFromValue(call.Arguments[0].Export()); ok {
+			so.Delay(duration, func(d time.Duration) { sleepContext(ctx, d) })
+		}
+		return goja.Undefined()
+	})
+
+	_ = vm.Set("setVar", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) < 2 {
+			return goja.Undefined()
+		}
+		s.vars.set(call.Arguments[0].String(), call.Arguments[1].String())
+		return goja.Undefined()
+	})
+	_ = vm.Set("getVar", func(call goja.FunctionCall) goja.Value {
+		if len(call.Arguments) == 0 {
+			return goja.Undefined()
+		}
+		if value, ok := s.vars.get(call.Arguments[0].String()); ok {
+			return vm.ToValue(value)
+		}
+		return goja.Undefined()
+	})
+
+	env := make(map[string]interface{}, len(s.cfg.Env))
+	for k, v := range s.cfg.Env {
+		env[k] = v
+	}
+	_ = vm.Set("env", env)
+
+	if db != nil {
+		_ = vm.Set("db", createDbObject(vm, db))
+	} else {
+		_ = vm.Set("db", goja.Undefined())
+	}
+}
+
+// installConsole adds a console whose methods write to the server log with
+// prefix and, when Config.ScriptLog is set, to the script log under source.
+func (s *Server) installConsole(vm *goja.Runtime, prefix, source string) {
+	consoleObj := vm.NewObject()
+	logAt := func(level string) func(goja.FunctionCall) goja.Value {
+		return func(call goja.FunctionCall) goja.Value {
+			message := sh.BuildMessageFromArgs(call.Arguments)
+			s.logf(level, "console", "%s %s\n", prefix, message)
+			if s.cfg.ScriptLog != nil {
+				s.cfg.ScriptLog(level, source, message)
+			}
+			return goja.Undefined()
+		}
+	}
+	_ = consoleObj.Set("log", logAt("info"))
+	_ = consoleObj.Set("info", logAt("info"))
+	_ = consoleObj.Set("warn", logAt("warn"))
+	_ = consoleObj.Set("error", logAt("error"))
+	_ = vm.Set("console", consoleObj)
+}
+
+// newRequestObject builds the script's `request`. Query parameters and
+// headers sent once are strings; repeated ones are arrays. bodyBytes holds
+// the raw body as a Uint8Array.
+func newRequestObject(vm *goja.Runtime, r *http.Request, method string, params map[string]string, body []byte) *goja.Object {
+	paramsMap := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		paramsMap[k] = v
+	}
+
+	reqObj := vm.NewObject()
+	_ = reqObj.Set("method", method)
+	_ = reqObj.Set("path", r.URL.Path)
+	_ = reqObj.Set("params", paramsMap)
+	_ = reqObj.Set("query", multiValueMap(r.URL.Query()))
+	_ = reqObj.Set("headers", multiValueMap(r.Header))
+	_ = reqObj.Set("body", string(body))
+
+	buffer := vm.NewArrayBuffer(append([]byte(nil), body...))
+	if bytes, err := vm.New(vm.Get("Uint8Array"), vm.ToValue(buffer)); err == nil {
+		_ = reqObj.Set("bodyBytes", bytes)
+	}
+	return reqObj
+}
+
+func multiValueMap(values map[string][]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(values))
+	for k, vals := range values {
+		switch len(vals) {
+		case 0:
+		case 1:
+			out[k] = vals[0]
+		default:
+			list := make([]interface{}, len(vals))
+			for i, v := range vals {
+				list[i] = v
+			}
+			out[k] = list
+		}
+	}
+	return out
+}
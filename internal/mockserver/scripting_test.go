@@ -0,0 +1,205 @@
+package mockserver
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMockScriptsShareVariablesEnvAndScriptLog(t *testing.T) {
+	var mu sync.Mutex
+	var logs []string
+	srv := New(Config{
+		Variables: map[string]string{"greeting": "hello"},
+		Env:       map[string]string{"region": "eu"},
+		ScriptLog: func(level, source, message string) {
+			mu.Lock()
+			defer mu.Unlock()
+			logs = append(logs, level+"|"+source+"|"+message)
+		},
+		Requests: []MockRequest{
+			{Name: "count", Method: "POST", URL: "/count", PreScript: `
+				const n = Number(getVar("count") || 0) + 1;
+				setVar("count", n);
+				console.warn("count is", n, { region: env.region });
+				response.body = { count: n, greeting: getVar("greeting") };
+			`},
+			{Method: "POST", URL: "/echo", PreScript: `
+				let sum = 0;
+				for (const b of request.bodyBytes) sum += b;
+				response.headers["X-Sum"] = String(sum);
+				response.headers["X-Accept"] = request.headers["Accept"];
+				response.headers["Set-Cookie"] = ["a=1", "b=2"];
+				response.body = request.bodyBytes.slice(1);
+			`},
+		},
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	base := fmt.Sprintf("http://127.0.0.1:%d", srv.Port())
+
+	for want := 1; want <= 3; want++ {
+		resp, err := http.Post(base+"/count", "text/plain", nil)
+		if err != nil {
+			t.Fatalf("Post() error = %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if got := fmt.Sprintf(`{"count":%d,"greeting":"hello"}`, want); string(body) != got {
+			t.Fatalf("request %d = %s, want %s", want, body, got)
+		}
+	}
+	mu.Lock()
+	if len(logs) != 3 || logs[2] != `warn|mock:count|count is 3 {"region":"eu"}` {
+		t.Errorf("script logs = %q", logs)
+	}
+	mu.Unlock()
+
+	req, _ := http.NewRequest(http.MethodPost, base+"/echo", bytes.NewReader([]byte{0, 1, 2, 255}))
+	req.Header.Add("Accept", "text/plain")
+	req.Header.Add("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(body, []byte{1, 2, 255}) {
+		t.Errorf("raw body = %v, want [1 2 255]", body)
+	}
+	if got := resp.Header.Get("X-Sum"); got != "258" {
+		t.Errorf("X-Sum = %q", got)
+	}
+	if got := strings.Join(resp.Header.Values("X-Accept"), ","); got != "text/plain,application/json" {
+		t.Errorf("X-Accept = %q, want both Accept values", got)
+	}
+	if got := strings.Join(resp.Header.Values("Set-Cookie"), ";"); got != "a=1;b=2" {
+		t.Errorf("Set-Cookie = %q", got)
+	}
+
+	if _, err := srv.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	resp, err = http.Post(base+"/count", "text/plain", nil)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), `"count":1`) {
+		t.Errorf("after Reset = %s, want the store back at its seed", body)
+	}
+}
+
+func TestResetGlobalsRemovesScriptGlobals(t *testing.T) {
+	srv := New(Config{})
+	vm := srv.acquireVM()
+	if _, err := vm.RunString(`globalThis.leak = 1; stray = 2; Object.defineProperty(globalThis, "hidden", { value: 3, configurable: true });`); err != nil {
+		t.Fatalf("RunString() error = %v", err)
+	}
+	if !resetGlobals(vm) {
+		t.Fatalf("resetGlobals() = false")
+	}
+	got, err := vm.RunString(`[typeof leak, typeof stray, typeof hidden, typeof JSON].join(",")`)
+	if err != nil {
+		t.Fatalf("RunString() error = %v", err)
+	}
+	if got.String() != "undefined,undefined,undefined,object" {
+		t.Errorf("globals after reset = %s", got)
+	}
+
+	if _, err := vm.RunString(`Object.defineProperty(globalThis, "pinned", { value: 1 });`); err != nil {
+		t.Fatalf("RunString() error = %v", err)
+	}
+	if resetGlobals(vm) {
+		t.Errorf("resetGlobals() = true for a non-configurable global")
+	}
+}
+
+func TestMockScriptsAssertAndDelay(t *testing.T) {
+	srv := New(Config{
+		Requests: []MockRequest{
+			{Method: "GET", URL: "/guarded", PreScript: `
+				assert(request.query.ok === "1", "ok query parameter required");
+				delay(5);
+				response.body = typeof globalThis.seen;
+				globalThis.seen = true;
+			`},
+		},
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	base := fmt.Sprintf("http://127.0.0.1:%d", srv.Port())
+
+	for i := 0; i < 3; i++ {
+		resp, err := http.Get(base + "/guarded?ok=1")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "undefined" {
+			t.Fatalf("request %d saw a global from an earlier request: %s", i+1, body)
+		}
+	}
+
+	resp, err := http.Get(base + "/guarded")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError || !strings.Contains(string(body), "ok query parameter required") {
+		t.Errorf("failed assert = %d %s, want a 500 with the message", resp.StatusCode, body)
+	}
+}
+
+func TestMockScriptsAwaitTimersAndTimeOut(t *testing.T) {
+	srv := New(Config{
+		ScriptTimeout: 100 * time.Millisecond,
+		Requests: []MockRequest{
+			{Method: "GET", URL: "/later", PreScript: `
+				const value = await new Promise(resolve => setTimeout(resolve, 5, "later"));
+				response.body = value;
+			`},
+			{Method: "GET", URL: "/spin", PreScript: `while (true) {}`},
+			{Method: "GET", URL: "/hang", PreScript: `await new Promise(resolve => setTimeout(resolve, 60000));`},
+		},
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+	base := fmt.Sprintf("http://127.0.0.1:%d", srv.Port())
+
+	for _, path := range []string{"/spin", "/hang"} {
+		start := time.Now()
+		resp, err := http.Get(base + path)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusInternalServerError || !strings.Contains(string(body), "timed out") {
+			t.Errorf("%s = %d %s, want a 500 timeout", path, resp.StatusCode, body)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s took %s, want about 100ms", path, elapsed)
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		if body := getBody(t, srv.Port(), "/later"); body != "later" {
+			t.Fatalf("GET /later = %q, want the awaited timer value", body)
+		}
+	}
+}
@@ -1,15 +1,24 @@
 package mockserver
 
 import (
+	"context"
+	"crypto/tls"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"rawrequest/internal/eventloop"
 
 	"github.com/dop251/goja"
 	_ "modernc.org/sqlite"
@@ -24,6 +33,9 @@ type MockRequest struct {
 	Body       string
 	PreScript  string
 	PostScript string
+	// Directives holds the mock-only directive lines of the block without
+	// their leading "@", e.g. "match header X-Tenant = acme".
+	Directives []string
 }
 
 // Route holds a compiled route rule for endpoint matching
@@ -33,148 +45,471 @@ type Route struct {
 	Regex       *regexp.Regexp
 	ParamNames  []string
 	Request     MockRequest
+	Matchers    []Matcher
+	Priority    int
+	// Status is the response status set by @status; zero means 200.
+	Status int
+	Faults Faults
+	// Scenario is set by @scenario.
+	Scenario *Scenario
+	// BodyFile is the fixture set by @body-file, relative to the .http
+	// file; it replaces the block body.
+	BodyFile string
+	// Warnings lists directives that could not be applied.
+	Warnings []string
 }
 
-var (
-	activeServer *http.Server
-	serverMu     sync.Mutex
-	LogListener  func(level, source, message string)
-)
+// Config describes one mock server instance.
+type Config struct {
+	// File is the .http file the routes came from; it is only used in logs.
+	File string
+	// Host is the interface to listen on. Empty means 127.0.0.1; use
+	// "0.0.0.0" to accept connections from other machines.
+	Host string
+	// Port to listen on. Zero picks a free port; see Server.Port.
+	Port int
+	// DBPath is the SQLite database exposed to scripts as `db`. Empty means
+	// no database.
+	DBPath   string
+	Requests []MockRequest
+	// LogListener, when set, receives every log line of this server in
+	// addition to stdout.
+	LogListener func(level, source, message string)
+	// Proxy is the upstream base URL that requests matching no route are
+	// forwarded to. Empty answers them with 404.
+	Proxy string
+	// RecordFile, when set with Proxy, is the .http file each proxied
+	// exchange is appended to as a new @mock block.
+	RecordFile string
+	// TLS serves HTTPS. Without CertFile and KeyFile the certificate is
+	// issued by a generated CA kept in CertDir (DefaultCertDir when empty).
+	TLS      bool
+	CertFile string
+	KeyFile  string
+	CertDir  string
+	// OpenAPI is the spec the routes were generated from, if any. With
+	// ValidateRequests, requests to its operations that violate it get a
+	// 400 listing the violations instead of a mock response.
+	OpenAPI          *OpenAPISpec
+	ValidateRequests bool
+	// Variables seed the key/value store scripts share through setVar and
+	// getVar, e.g. the file's @variables and --var values. Env holds the
+	// active environment's variables, exposed to scripts as `env`.
+	Variables map[string]string
+	Env       map[string]string
+	// ScriptLog, when set, also receives the console output of mock scripts
+	// with its level, the way client scripts report theirs.
+	ScriptLog func(level, source, message string)
+	// ScriptTimeout bounds each mock script run, including awaited timers.
+	// Zero means eventloop.DefaultTimeout.
+	ScriptTimeout time.Duration
+}
 
-func broadcastLog(level, source, format string, args ...interface{}) {
+// Server is one mock server with its own routes, database, logs and
+// lifecycle. Any number of servers can run in the same process.
+type Server struct {
+	cfg    Config
+	routes atomic.Pointer[[]Route]
+	// journal records the requests served, for the admin API.
+	journal journal
+	// scenarios holds the state of each @scenario.
+	scenarios scenarioStore
+	// vars is the setVar/getVar store and vms pools request script runtimes.
+	vars scriptVars
+	vms  sync.Pool
+	// port is the bound port; it is atomic so log listeners can read it
+	// while Start holds mu.
+	port atomic.Int64
+
+	// proxy is set by Start when Config.Proxy is not empty.
+	proxy *proxy
+
+	// sockets holds the WebSocket connections of each ws endpoint.
+	socketsMu sync.Mutex
+	sockets   map[string]*socketHub
+	socketSeq atomic.Int64
+
+	mu         sync.Mutex
+	db         *sql.DB
+	httpServer *http.Server
+	done       chan struct{}
+	serveErr   error
+}
+
+// New creates a server for cfg. It does not listen until Start is called.
+func New(cfg Config) *Server {
+	s := &Server{cfg: cfg}
+	s.vars.reset(cfg.Variables)
+	return s
+}
+
+func (s *Server) logf(level, source, format string, args ...interface{}) {
 	msg := fmt.Sprintf(format, args...)
 	fmt.Print(msg)
-	if LogListener != nil {
+	if s.cfg.LogListener != nil {
 		cleanMsg := strings.TrimRight(msg, "\r\n")
-		LogListener(level, source, cleanMsg)
+		s.cfg.LogListener(level, source, cleanMsg)
 	}
 }
 
-// StopMockServer stops the running mock server instance if active
-func StopMockServer() error {
-	serverMu.Lock()
-	defer serverMu.Unlock()
-	if activeServer == nil {
-		return nil
+// Start compiles the routes, opens the database, runs the @mockinit scripts
+// and starts listening. It returns once the port is bound; requests are
+// served in the background until Close.
+func (s *Server) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.httpServer != nil {
+		return fmt.Errorf("mock server is already running")
 	}
-	err := activeServer.Close()
-	activeServer = nil
-	broadcastLog("info", "mockserver", "[Mock Server] Stopped successfully.\n")
-	return err
-}
 
-// StartMockServer parses the .http file, compiles routing rules, and boots the HTTP server
-func StartMockServer(file string, port int, dbPath string, requests []MockRequest) error {
-	broadcastLog("info", "mockserver", "[Mock Server] Parsing and compiling endpoints from %s...\n", file)
-	var routes []Route
-	for _, req := range requests {
-		if req.Method == "MOCKINIT" {
-			continue
+	if s.cfg.Proxy != "" {
+		p, err := newProxy(s.cfg.Proxy, s.cfg.RecordFile)
+		if err != nil {
+			return err
 		}
-		route := compileRoute(req)
-		routes = append(routes, route)
-		broadcastLog("info", "mockserver", "  - %-7s %s\n", route.Method, route.PathPattern)
+		s.proxy = p
 	}
 
+	tlsConfig, err := s.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	s.logf("info", "mockserver", "[Mock Server] Parsing and compiling endpoints from %s...\n", s.cfg.File)
+	routes := compileRoutes(s.cfg.Requests)
+	for _, route := range routes {
+		s.logf("info", "mockserver", "  - %s\n", route.describe())
+	}
+	s.logRouteWarnings(routes)
+	s.routes.Store(&routes)
+
 	var db *sql.DB
-	if dbPath != "" {
-		broadcastLog("info", "mockserver", "[Mock Server] Opening SQLite database: %s\n", dbPath)
-		d, err := sql.Open("sqlite", dbPath)
+	if s.cfg.DBPath != "" {
+		s.logf("info", "mockserver", "[Mock Server] Opening SQLite database: %s\n", s.cfg.DBPath)
+		d, err := sql.Open("sqlite", s.cfg.DBPath)
 		if err != nil {
 			return fmt.Errorf("failed to open SQLite database: %w", err)
 		}
 		db = d
-		defer db.Close()
 
 		// Verify connection
 		if err := db.Ping(); err != nil {
+			_ = db.Close()
 			return fmt.Errorf("failed to connect to SQLite database: %w", err)
 		}
-		broadcastLog("info", "mockserver", "[Mock Server] SQLite database connection established.\n")
+		s.logf("info", "mockserver", "[Mock Server] SQLite database connection established.\n")
 	}
+	s.db = db
 
 	// Run mock initialization script if present
-	for _, req := range requests {
+	for _, req := range s.cfg.Requests {
 		if req.Method == "MOCKINIT" {
-			broadcastLog("info", "mockserver", "[Mock Server] Running database initialization script...\n")
-			executeMockInitScript(req, db)
+			s.logf("info", "mockserver", "[Mock Server] Running database initialization script...\n")
+			s.executeMockInitScript(req)
 		}
 	}
 
+	listener, err := net.Listen("tcp", net.JoinHostPort(s.host(), strconv.Itoa(s.cfg.Port)))
+	if err != nil {
+		if db != nil {
+			_ = db.Close()
+			s.db = nil
+		}
+		return err
+	}
 
-	handler := func(w http.ResponseWriter, r *http.Request) {
-		reqPath := r.URL.Path
-		reqMethod := strings.ToUpper(r.Method)
-
-		var matchedRoute *Route
-		var pathParams map[string]string
+	httpServer := &http.Server{Handler: s}
+	done := make(chan struct{})
+	s.httpServer = httpServer
+	s.done = done
+	s.serveErr = nil
+	s.port.Store(int64(listener.Addr().(*net.TCPAddr).Port))
 
-		for _, route := range routes {
-			if route.Method == reqMethod {
-				if matches := route.Regex.FindStringSubmatch(reqPath); matches != nil {
-					matchedRoute = &route
-					pathParams = make(map[string]string)
-					for i, val := range matches[1:] {
-						if i < len(route.ParamNames) {
-							pathParams[route.ParamNames[i]] = val
-						}
-					}
-					break
-				}
-			}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	s.logf("info", "mockserver", "[Mock Server] Ready! Listening on %s\n", s.URL())
+	if s.proxy != nil {
+		s.logf("info", "mockserver", "[Mock Server] Forwarding unmatched requests to %s\n", s.proxy.upstream)
+		if s.proxy.recordFile != "" {
+			s.logf("info", "mockserver", "[Mock Server] Recording proxied exchanges into %s\n", s.proxy.recordFile)
 		}
+	}
 
-		if matchedRoute == nil {
-			broadcastLog("warn", "mockserver", "[Mock Server] 404 Not Found: %s %s\n", reqMethod, reqPath)
-			w.WriteHeader(http.StatusNotFound)
-			w.Header().Set("Content-Type", "application/json")
-			_, _ = w.Write([]byte(`{"error": "Resource not matched by any mock endpoint in .http file"}`))
-			return
+	go func() {
+		err := httpServer.Serve(listener)
+		s.mu.Lock()
+		if err != nil && err != http.ErrServerClosed {
+			s.serveErr = err
+		}
+		if s.db != nil {
+			_ = s.db.Close()
+			s.db = nil
 		}
+		s.httpServer = nil
+		s.mu.Unlock()
+		close(done)
+	}()
+	return nil
+}
 
-		broadcastLog("info", "mockserver", "[Mock Server] Matched: %s %s -> Name: %s\n", reqMethod, reqPath, matchedRoute.Request.Name)
+// Wait blocks until the server stops and returns the error that stopped it,
+// if it was not a Close.
+func (s *Server) Wait() error {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	if done == nil {
+		return nil
+	}
+	<-done
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.serveErr
+}
+
+// ListenAndServe starts the server and blocks until it stops.
+func (s *Server) ListenAndServe() error {
+	if err := s.Start(); err != nil {
+		return err
+	}
+	return s.Wait()
+}
 
-		// Read Request Body
-		var reqBodyBytes []byte
-		if r.Body != nil {
-			reqBodyBytes, _ = io.ReadAll(r.Body)
+// Close stops the server and closes its database. Closing a server that is
+// not running is a no-op.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	httpServer := s.httpServer
+	done := s.done
+	s.mu.Unlock()
+	if httpServer == nil {
+		return nil
+	}
+	err := httpServer.Close()
+	s.closeSockets()
+	<-done
+	s.logf("info", "mockserver", "[Mock Server] Stopped successfully.\n")
+	return err
+}
+
+// Running reports whether the server is listening.
+func (s *Server) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.httpServer != nil
+}
+
+// Port returns the port the server listens on, or the configured port before
+// it has started.
+func (s *Server) Port() int {
+	if port := s.port.Load(); port != 0 {
+		return int(port)
+	}
+	return s.cfg.Port
+}
+
+func (s *Server) host() string {
+	if s.cfg.Host == "" {
+		return "127.0.0.1"
+	}
+	return strings.Trim(s.cfg.Host, "[]")
+}
+
+// URL returns the base URL clients reach the server at, e.g.
+// "https://localhost:8443".
+func (s *Server) URL() string {
+	scheme := "http"
+	if s.cfg.TLS || s.cfg.CertFile != "" {
+		scheme = "https"
+	}
+	host := s.host()
+	if host == "127.0.0.1" || isWildcardHost(host) {
+		host = "localhost"
+	}
+	return fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(host, strconv.Itoa(s.Port())))
+}
+
+// tlsConfig loads the configured certificate, or issues one from the
+// generated CA. It returns nil when TLS is off.
+func (s *Server) tlsConfig() (*tls.Config, error) {
+	if s.cfg.CertFile != "" || s.cfg.KeyFile != "" {
+		if s.cfg.CertFile == "" || s.cfg.KeyFile == "" {
+			return nil, fmt.Errorf("TLS needs both a certificate and a key file")
 		}
+		cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+	}
+	if !s.cfg.TLS {
+		return nil, nil
+	}
+	dir := s.cfg.CertDir
+	if dir == "" {
+		dir = DefaultCertDir()
+	}
+	cert, err := loadOrCreateCertificate(dir, certHosts(s.host()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare TLS certificate: %w", err)
+	}
+	s.logf("info", "mockserver", "[Mock Server] Using a certificate from the RawRequest mock CA; trust %s to avoid warnings\n", filepath.Join(dir, CAFileName))
+	return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+}
 
-		// Check if mock has custom scripts
-		hasScript := matchedRoute.Request.PreScript != "" || matchedRoute.Request.PostScript != ""
+// Config returns the configuration the server was created with.
+func (s *Server) Config() Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
 
-		if hasScript {
-			executeMockScript(w, r, matchedRoute, pathParams, reqBodyBytes, db)
-		} else {
-			executeFallbackMock(w, r, matchedRoute, pathParams, reqBodyBytes)
+func (s *Server) database() *sql.DB {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.db
+}
+
+// ServeHTTP dispatches r to the first route matching its method and path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reqPath := r.URL.Path
+	reqMethod := strings.ToUpper(r.Method)
+	if strings.HasPrefix(reqPath, AdminPrefix) {
+		s.serveAdmin(w, r)
+		return
+	}
+
+	// Read Request Body
+	var reqBodyBytes []byte
+	if r.Body != nil {
+		reqBodyBytes, _ = io.ReadAll(r.Body)
+	}
+
+	matchedRoute, pathParams := s.match(r, reqBodyBytes)
+	entry := s.recordRequest(r, reqBodyBytes, matchedRoute)
+	rec := &statusRecorder{ResponseWriter: w, upgrade: matchedRoute != nil && matchedRoute.Method == MethodWebSocket}
+	defer func() { s.journal.setStatus(entry, rec.status) }()
+	w = rec
+
+	if s.cfg.OpenAPI != nil && s.cfg.ValidateRequests {
+		if violations := s.cfg.OpenAPI.ValidateRequest(r, reqBodyBytes); len(violations) > 0 {
+			s.writeValidationError(w, r, violations)
+			return
 		}
 	}
 
-	addr := fmt.Sprintf(":%d", port)
+	if matchedRoute == nil && s.proxy != nil {
+		s.proxyRequest(w, r, reqBodyBytes)
+		return
+	}
+	if matchedRoute == nil {
+		s.logf("warn", "mockserver", "[Mock Server] 404 Not Found: %s %s\n", reqMethod, reqPath)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": "Resource not matched by any mock endpoint in .http file"}`))
+		return
+	}
+
+	s.logf("info", "mockserver", "[Mock Server] Matched: %s %s -> Name: %s\n", reqMethod, reqPath, matchedRoute.Request.Name)
+	s.advanceScenario(matchedRoute)
+
+	switch matchedRoute.Method {
+	case MethodWebSocket:
+		s.serveWebSocket(w, r, matchedRoute, pathParams)
+		return
+	case MethodSSE:
+		s.serveSSE(w, r, matchedRoute, pathParams)
+		return
+	}
+
+	// Check if mock has custom scripts
+	hasScript := matchedRoute.Request.PreScript != "" || matchedRoute.Request.PostScript != ""
 
-	serverMu.Lock()
-	if activeServer != nil {
-		serverMu.Unlock()
-		return fmt.Errorf("a mock server is already running")
+	if hasScript {
+		s.executeMockScript(w, r, matchedRoute, pathParams, reqBodyBytes)
+	} else {
+		s.executeFallbackMock(w, r, matchedRoute, pathParams, reqBodyBytes)
+	}
+}
+
+// match returns the first route, in priority order, whose method, path and
+// @match conditions accept the request.
+func (s *Server) match(r *http.Request, body []byte) (*Route, map[string]string) {
+	path := r.URL.Path
+	routes := s.routes.Load()
+	if routes == nil {
+		return nil, nil
+	}
+	for i := range *routes {
+		route := &(*routes)[i]
+		if !route.acceptsMethod(r) {
+			continue
+		}
+		matches := route.Regex.FindStringSubmatch(path)
+		if matches == nil || !route.accepts(r, body) || !s.acceptsScenario(route) {
+			continue
+		}
+		params := make(map[string]string)
+		for j, val := range matches[1:] {
+			if j < len(route.ParamNames) {
+				params[route.ParamNames[j]] = val
+			}
+		}
+		return route, params
 	}
-	activeServer = &http.Server{
-		Addr:    addr,
-		Handler: http.HandlerFunc(handler),
+	return nil, nil
+}
+
+// compileRoutes compiles the endpoint routes of requests, skipping @mockinit
+// blocks.
+func compileRoutes(requests []MockRequest) []Route {
+	var routes []Route
+	for _, req := range requests {
+		if req.Method == "MOCKINIT" {
+			continue
+		}
+		routes = append(routes, compileRoute(req))
 	}
-	serverMu.Unlock()
+	sortRoutes(routes)
+	return routes
+}
 
-	broadcastLog("info", "mockserver", "[Mock Server] Ready! Listening on http://localhost%s\n", addr)
-	
-	err := activeServer.ListenAndServe()
+func (route *Route) accepts(r *http.Request, body []byte) bool {
+	for _, m := range route.Matchers {
+		if !m.Matches(r, body) {
+			return false
+		}
+	}
+	return true
+}
 
-	serverMu.Lock()
-	activeServer = nil
-	serverMu.Unlock()
+// describe labels the route for logs, listing its conditions and priority.
+func (route *Route) describe() string {
+	label := fmt.Sprintf("%-7s %s", route.Method, route.PathPattern)
+	var extras []string
+	for _, m := range route.Matchers {
+		extras = append(extras, m.Raw)
+	}
+	if route.Scenario != nil {
+		extras = append(extras, route.Scenario.String())
+	}
+	if route.Priority != 0 {
+		extras = append(extras, fmt.Sprintf("priority %d", route.Priority))
+	}
+	if len(extras) > 0 {
+		label += " [" + strings.Join(extras, "; ") + "]"
+	}
+	return label
+}
 
-	if err != nil && err != http.ErrServerClosed {
-		return err
+// logRouteWarnings reports directives that could not be applied.
+func (s *Server) logRouteWarnings(routes []Route) {
+	for _, route := range routes {
+		for _, warning := range route.Warnings {
+			s.logf("warn", "mockserver", "[Mock Server] %s %s: %s\n", route.Method, route.PathPattern, warning)
+		}
 	}
-	return nil
 }
 
 func getRoutePath(rawURL string) string {
@@ -188,10 +523,9 @@ func getRoutePath(rawURL string) string {
 	return parts[0]
 }
 
-func compileRoute(req MockRequest) Route {
-	method := strings.ToUpper(req.Method)
-	pathTemplate := getRoutePath(req.URL)
-
+// compilePathPattern turns a route path such as "/users/:id" or
+// "/users/{{id}}" into an anchored regexp and its parameter names.
+func compilePathPattern(pathTemplate string) (*regexp.Regexp, []string) {
 	// Escape path literal segments while preserving parameters {{param}} or :param
 	paramRegex := regexp.MustCompile(`\{\{([a-zA-Z0-9_]+)\}\}|:([a-zA-Z0-9_]+)`)
 	matches := paramRegex.FindAllStringSubmatchIndex(pathTemplate, -1)
@@ -225,17 +559,31 @@ func compileRoute(req MockRequest) Route {
 	if err != nil {
 		re = regexp.MustCompile("^" + regexp.QuoteMeta(pathTemplate) + "$")
 	}
+	return re, paramNames
+}
 
-	return Route{
+func compileRoute(req MockRequest) Route {
+	method := strings.ToUpper(req.Method)
+	pathTemplate := getRoutePath(req.URL)
+	re, paramNames := compilePathPattern(pathTemplate)
+
+	route := Route{
 		Method:      method,
 		PathPattern: pathTemplate,
 		Regex:       re,
 		ParamNames:  paramNames,
 		Request:     req,
 	}
+	applyDirectives(&route)
+	return route
 }
 
-func executeFallbackMock(w http.ResponseWriter, r *http.Request, route *Route, params map[string]string, reqBody []byte) {
+func (s *Server) executeFallbackMock(w http.ResponseWriter, r *http.Request, route *Route, params map[string]string, reqBody []byte) {
+	if route.BodyFile != "" {
+		s.serveBodyFile(w, r, route)
+		return
+	}
+
 	// Set Headers defined in request as starting headers
 	for k, v := range route.Request.Headers {
 		w.Header().Set(k, v)
@@ -256,74 +604,39 @@ func executeFallbackMock(w http.ResponseWriter, r *http.Request, route *Route, p
 		}
 	}
 
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write([]byte(body))
+	s.deliver(w, r, route.Faults, route.status(), []byte(body))
 }
 
-func executeMockScript(w http.ResponseWriter, r *http.Request, route *Route, params map[string]string, reqBody []byte, db *sql.DB) {
-	vm := goja.New()
-
-	// Assemble query params map
-	queryMap := make(map[string]interface{})
-	for k, vals := range r.URL.Query() {
-		if len(vals) == 1 {
-			queryMap[k] = vals[0]
-		} else {
-			queryMap[k] = vals
-		}
-	}
-
-	// Assemble request headers map
-	headersMap := make(map[string]string)
-	for k, vals := range r.Header {
-		if len(vals) > 0 {
-			headersMap[k] = vals[0]
-		}
+func (s *Server) scriptTimeout() time.Duration {
+	if s.cfg.ScriptTimeout > 0 {
+		return s.cfg.ScriptTimeout
 	}
+	return eventloop.DefaultTimeout
+}
 
-	// Assemble path params map
-	paramsMap := make(map[string]interface{})
-	for k, v := range params {
-		paramsMap[k] = v
+func (route *Route) status() int {
+	if route.Status != 0 {
+		return route.Status
 	}
+	return http.StatusOK
+}
 
-	// Inject JS `request` object
-	reqObj := vm.NewObject()
-	_ = reqObj.Set("method", route.Method)
-	_ = reqObj.Set("path", r.URL.Path)
-	_ = reqObj.Set("params", paramsMap)
-	_ = reqObj.Set("query", queryMap)
-	_ = reqObj.Set("headers", headersMap)
-	_ = reqObj.Set("body", string(reqBody))
-	_ = vm.Set("request", reqObj)
+func (s *Server) executeMockScript(w http.ResponseWriter, r *http.Request, route *Route, params map[string]string, reqBody []byte) {
+	vm := s.acquireVM()
+	_ = vm.Set("request", newRequestObject(vm, r, route.Method, params, reqBody))
 
 	// Inject JS `response` object
 	respObj := vm.NewObject()
-	_ = respObj.Set("status", 200)
+	_ = respObj.Set("status", route.status())
 	_ = respObj.Set("headers", vm.NewObject())
 	_ = respObj.Set("body", "")
+	faults := route.Faults
+	installFaultControls(vm, respObj, &faults)
+	var body payload
+	s.installPayloadControls(vm, respObj, &body)
 	_ = vm.Set("response", respObj)
 
-	// Inject JS `console` helper
-	consoleObj := vm.NewObject()
-	logFn := func(call goja.FunctionCall) goja.Value {
-		var args []string
-		for _, arg := range call.Arguments {
-			args = append(args, arg.String())
-		}
-		broadcastLog("log", "console", "[Mock Script Log] %s\n", strings.Join(args, " "))
-		return goja.Undefined()
-	}
-	_ = consoleObj.Set("log", logFn)
-	_ = consoleObj.Set("info", logFn)
-	_ = consoleObj.Set("warn", logFn)
-	_ = consoleObj.Set("error", logFn)
-	_ = vm.Set("console", consoleObj)
-
-	// Inject JS `db` object if SQLite is active
-	if db != nil {
-		_ = vm.Set("db", createDbObject(vm, db))
-	}
+	s.installScriptGlobals(r.Context(), vm, s.database(), "[Mock Script Log]", scriptSource("mock", route.Request))
 
 	// Combine and clean PreScript and PostScript
 	script := ""
@@ -334,23 +647,60 @@ func executeMockScript(w http.ResponseWriter, r *http.Request, route *Route, par
 		script += cleanScript(route.Request.PostScript) + "\n"
 	}
 
-	// Wrap execution in a nice enclosure that safely injects request and response
+	// Wrap execution in a nice enclosure that safely injects request and
+	// response. The inner function is async so scripts can use top-level
+	// `await`, with timers from the shared event loop, like client scripts.
 	wrappedScript := fmt.Sprintf(
 		"(function(__g){\n"+
-			"  (function(request, response){\n%s\n"+
+			"  return (async function(request, response){\n%s\n"+
 			"  })(__g.request, __g.response);\n"+
 			"})(Function('return this')());",
 		script,
 	)
 
-	_, err := vm.RunString(wrappedScript)
+	timeout := s.scriptTimeout()
+	loop := eventloop.Install(vm)
+	clock := eventloop.StartWatchdog(vm, timeout)
+	result, err := vm.RunString(wrappedScript)
+	if err == nil {
+		err = loop.Run(clock.Remaining, r.Context().Done())
+	}
+	timedOut := clock.Stop()
+	if err == nil && r.Context().Err() != nil {
+		// The client went away while the script waited.
+		return
+	}
+	if err == nil {
+		if main, _ := result.Export().(*goja.Promise); main != nil {
+			switch main.State() {
+			case goja.PromiseStateRejected:
+				err = fmt.Errorf("%s", main.Result())
+			case goja.PromiseStatePending:
+				err = fmt.Errorf("script finished with an await that never settled")
+			}
+		}
+	}
 	if err != nil {
-		broadcastLog("error", "console", "[Mock Script Error] Runtime Exception: %v\n", err)
+		detail := err.Error()
+		if eventloop.IsTimeout(err) {
+			detail = fmt.Sprintf("script timed out after %s", timeout)
+		}
+		s.logf("error", "console", "[Mock Script Error] Runtime Exception: %s\n", detail)
+		if s.cfg.ScriptLog != nil {
+			s.cfg.ScriptLog("error", scriptSource("mock", route.Request), "runtime error: "+detail)
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusInternalServerError)
-		_, _ = w.Write([]byte(fmt.Sprintf(`{"error": "Mock script execution failed", "details": %q}`, err.Error())))
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"error": "Mock script execution failed", "details": %q}`, detail)))
 		return
 	}
+	release := func() {
+		// A timeout that fired just as the script finished still leaves the
+		// runtime interrupted, so it is not reused.
+		if !timedOut {
+			s.releaseVM(vm)
+		}
+	}
 
 	// Extract status
 	statusVal := respObj.Get("status")
@@ -359,20 +709,42 @@ func executeMockScript(w http.ResponseWriter, r *http.Request, route *Route, par
 		status = int(statusVal.ToInteger())
 	}
 
-	// Extract headers
+	// Extract headers; an array value sends the header once per element
 	headersVal := respObj.Get("headers")
 	if headersVal != nil {
 		if hObj, ok := headersVal.Export().(map[string]interface{}); ok {
 			for k, v := range hObj {
+				if list, ok := v.([]interface{}); ok {
+					w.Header().Del(k)
+					for _, item := range list {
+						w.Header().Add(k, fmt.Sprintf("%v", item))
+					}
+					continue
+				}
 				w.Header().Set(k, fmt.Sprintf("%v", v))
 			}
 		}
 	}
 
+	// response.file, response.bytes and response.stream replace the body
+	if body.set {
+		release()
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", body.contentType)
+		}
+		if body.chunks != nil {
+			s.deliverChunks(w, r, faults, status, body.chunks, body.interval)
+			return
+		}
+		s.deliver(w, r, faults, status, body.body)
+		return
+	}
+
 	// Extract body
 	bodyVal := respObj.Get("body")
 	var bodyBytes []byte
 	isJSON := false
+	isBinary := false
 
 	if bodyVal != nil {
 		exported := bodyVal.Export()
@@ -390,6 +762,13 @@ func executeMockScript(w http.ResponseWriter, r *http.Request, route *Route, par
 			}
 		case nil:
 			bodyBytes = []byte("")
+		case []byte:
+			// Uint8Array: raw bytes, sent as they are
+			bodyBytes = append([]byte(nil), v...)
+			isBinary = true
+		case goja.ArrayBuffer:
+			bodyBytes = append([]byte(nil), v.Bytes()...)
+			isBinary = true
 		default:
 			// serialize to JSON
 			bytes, err := json.Marshal(v)
@@ -405,13 +784,15 @@ func executeMockScript(w http.ResponseWriter, r *http.Request, route *Route, par
 	if w.Header().Get("Content-Type") == "" {
 		if isJSON {
 			w.Header().Set("Content-Type", "application/json")
+		} else if isBinary {
+			w.Header().Set("Content-Type", "application/octet-stream")
 		} else {
 			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
 		}
 	}
 
-	w.WriteHeader(status)
-	_, _ = w.Write(bodyBytes)
+	release()
+	s.deliver(w, r, faults, status, bodyBytes)
 }
 
 func createDbObject(vm *goja.Runtime, db *sql.DB) *goja.Object {
@@ -553,7 +934,7 @@ func cleanScript(script string) string {
 	if !strings.HasPrefix(first, "<") && !strings.HasPrefix(first, ">") {
 		return script
 	}
-	
+
 	lines = lines[1:]
 	lines = trimScriptEdges(lines)
 	if len(lines) == 0 {
@@ -579,29 +960,9 @@ func trimScriptEdges(lines []string) []string {
 	return lines
 }
 
-func executeMockInitScript(req MockRequest, db *sql.DB) {
+func (s *Server) executeMockInitScript(req MockRequest) {
 	vm := goja.New()
-
-	// Inject JS `console` helper
-	consoleObj := vm.NewObject()
-	logFn := func(call goja.FunctionCall) goja.Value {
-		var args []string
-		for _, arg := range call.Arguments {
-			args = append(args, arg.String())
-		}
-		broadcastLog("log", "console", "[Mock Init Log] %s\n", strings.Join(args, " "))
-		return goja.Undefined()
-	}
-	_ = consoleObj.Set("log", logFn)
-	_ = consoleObj.Set("info", logFn)
-	_ = consoleObj.Set("warn", logFn)
-	_ = consoleObj.Set("error", logFn)
-	_ = vm.Set("console", consoleObj)
-
-	// Inject JS `db` object if SQLite is active
-	if db != nil {
-		_ = vm.Set("db", createDbObject(vm, db))
-	}
+	s.installScriptGlobals(context.Background(), vm, s.db, "[Mock Init Log]", scriptSource("mockinit", req))
 
 	// Combine and clean PreScript and PostScript
 	script := ""
@@ -612,11 +973,23 @@ func executeMockInitScript(req MockRequest, db *sql.DB) {
 		script += cleanScript(req.PostScript) + "\n"
 	}
 
-	_, err := vm.RunString(script)
+	// Like route scripts, the init script may await timers and promises.
+	wrapped := "(async function(){\n" + script + "\n})();"
+	loop := eventloop.Install(vm)
+	clock := eventloop.StartWatchdog(vm, s.scriptTimeout())
+	result, err := vm.RunString(wrapped)
+	if err == nil {
+		err = loop.Run(clock.Remaining, nil)
+	}
+	clock.Stop()
+	if err == nil {
+		if main, _ := result.Export().(*goja.Promise); main != nil && main.State() == goja.PromiseStateRejected {
+			err = fmt.Errorf("%s", main.Result())
+		}
+	}
 	if err != nil {
-		broadcastLog("error", "console", "[Mock Init Error] Runtime Exception: %v\n", err)
+		s.logf("error", "console", "[Mock Init Error] Runtime Exception: %v\n", err)
 	} else {
-		broadcastLog("info", "mockserver", "[Mock Server] Database initialization script completed successfully.\n")
+		s.logf("info", "mockserver", "[Mock Server] Database initialization script completed successfully.\n")
 	}
 }
-
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -69,7 +70,7 @@ func TestExecuteFallbackMock(t *testing.T) {
 	rec := httptest.NewRecorder()
 	r := httptest.NewRequest("GET", "/users/999", nil)
 
-	executeFallbackMock(rec, r, &route, params, nil)
+	New(Config{}).executeFallbackMock(rec, r, &route, params, nil)
 
 	if rec.Code != http.StatusOK {
 		t.Errorf("Expected status 200, got %d", rec.Code)
@@ -118,12 +119,13 @@ func TestExecuteMockScriptWithDB(t *testing.T) {
 	}
 
 	route := compileRoute(req)
+	s := &Server{db: db}
 
 	// Test 1: GET All todos
 	{
 		rec := httptest.NewRecorder()
 		r := httptest.NewRequest("GET", "/todos", nil)
-		executeMockScript(rec, r, &route, map[string]string{}, nil, db)
+		s.executeMockScript(rec, r, &route, map[string]string{}, nil)
 
 		if rec.Code != http.StatusOK {
 			t.Errorf("Expected status 200, got %d", rec.Code)
@@ -147,7 +149,7 @@ func TestExecuteMockScriptWithDB(t *testing.T) {
 	{
 		rec := httptest.NewRecorder()
 		r := httptest.NewRequest("GET", "/todos/2", nil)
-		executeMockScript(rec, r, &route, map[string]string{"id": "2"}, nil, db)
+		s.executeMockScript(rec, r, &route, map[string]string{"id": "2"}, nil)
 
 		if rec.Code != http.StatusOK {
 			t.Errorf("Expected status 200, got %d", rec.Code)
@@ -195,7 +197,7 @@ func TestStartMockServerWithSQLiteFilePersistsStateAcrossRestart(t *testing.T) {
 	}
 
 	port := getFreePort(t)
-	runMockServerAsync(t, "test.http", port, dbPath, requests)
+	first := runMockServerAsync(t, "test.http", port, dbPath, requests)
 
 	postJSON(t, port, "/users", `{"name":"Alice"}`)
 	users := getJSON[[]map[string]interface{}](t, port, "/users")
@@ -203,8 +205,8 @@ func TestStartMockServerWithSQLiteFilePersistsStateAcrossRestart(t *testing.T) {
 		t.Fatalf("users after first run = %v, want one Alice row", users)
 	}
 
-	if err := StopMockServer(); err != nil {
-		t.Fatalf("StopMockServer() error = %v", err)
+	if err := first.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
 	}
 
 	port = getFreePort(t)
@@ -224,28 +226,81 @@ func TestStartMockServerWithSQLiteFilePersistsStateAcrossRestart(t *testing.T) {
 	}
 }
 
-func runMockServerAsync(t *testing.T, file string, port int, dbPath string, requests []MockRequest) {
+func TestServersRunConcurrentlyWithIsolatedState(t *testing.T) {
+	for _, service := range []string{"users", "orders", "billing"} {
+		t.Run(service, func(t *testing.T) {
+			t.Parallel()
+
+			var mu sync.Mutex
+			var logs []string
+			srv := New(Config{
+				File:   service + ".http",
+				DBPath: filepath.Join(t.TempDir(), service+".db"),
+				Requests: []MockRequest{
+					{Method: "MOCKINIT", URL: "@mockinit", PreScript: `db.exec("CREATE TABLE names (name TEXT)");`},
+					{Method: "POST", URL: "/users", PreScript: `
+						db.exec("INSERT INTO names (name) VALUES (?)", JSON.parse(request.body).name);
+						response.status = 201;
+						response.body = {};
+					`},
+					{Method: "GET", URL: "/users", PreScript: `response.body = db.query("SELECT name FROM names");`},
+				},
+				LogListener: func(level, source, message string) {
+					mu.Lock()
+					logs = append(logs, message)
+					mu.Unlock()
+				},
+			})
+			if err := srv.Start(); err != nil {
+				t.Fatalf("Start() error = %v", err)
+			}
+			t.Cleanup(func() { _ = srv.Close() })
+			if srv.Port() == 0 || !srv.Running() {
+				t.Fatalf("Port() = %d, Running() = %v after Start", srv.Port(), srv.Running())
+			}
+
+			postJSON(t, srv.Port(), "/users", fmt.Sprintf(`{"name":%q}`, service))
+			names := getJSON[[]map[string]interface{}](t, srv.Port(), "/users")
+			if len(names) != 1 || names[0]["name"] != service {
+				t.Fatalf("names = %v, want only %q", names, service)
+			}
+
+			if err := srv.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if srv.Running() {
+				t.Fatal("Running() = true after Close")
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, line := range logs {
+				if strings.Contains(line, "Parsing and compiling endpoints from") && !strings.Contains(line, service+".http") {
+					t.Fatalf("log %q leaked from another server", line)
+				}
+			}
+		})
+	}
+}
+
+func runMockServerAsync(t *testing.T, file string, port int, dbPath string, requests []MockRequest) *Server {
 	t.Helper()
-	serverErr := make(chan error, 1)
-	go func() {
-		serverErr <- StartMockServer(file, port, dbPath, requests)
-	}()
+	srv := New(Config{File: file, Port: port, DBPath: dbPath, Requests: requests})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
 
-	waitForServer(t, port, "/users")
+	waitForServer(t, srv.Port(), "/users")
 
 	t.Cleanup(func() {
-		if err := StopMockServer(); err != nil {
-			t.Fatalf("StopMockServer() cleanup error = %v", err)
+		if err := srv.Close(); err != nil {
+			t.Fatalf("Close() cleanup error = %v", err)
 		}
-		select {
-		case err := <-serverErr:
-			if err != nil {
-				t.Fatalf("StartMockServer() exited with error: %v", err)
-			}
-		case <-time.After(2 * time.Second):
-			t.Fatal("timed out waiting for mock server goroutine to exit")
+		if err := srv.Wait(); err != nil {
+			t.Fatalf("Wait() returned error: %v", err)
 		}
 	})
+	return srv
 }
 
 func waitForServer(t *testing.T, port int, path string) {
@@ -0,0 +1,96 @@
+package mockserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServerServesHTTPSWithGeneratedCA(t *testing.T) {
+	certDir := t.TempDir()
+	requests := []MockRequest{{Method: "GET", URL: "/health", Body: "secure"}}
+
+	srv := New(Config{Requests: requests, TLS: true, CertDir: certDir})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if !strings.HasPrefix(srv.URL(), "https://localhost:") {
+		t.Fatalf("URL() = %q, want an https localhost URL", srv.URL())
+	}
+
+	caPEM, err := os.ReadFile(filepath.Join(certDir, CAFileName))
+	if err != nil {
+		t.Fatalf("ReadFile(ca) error = %v", err)
+	}
+	if body := getTrusting(t, caPEM, srv.URL()+"/health"); body != "secure" {
+		t.Fatalf("GET /health = %q, want secure", body)
+	}
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// A restart reuses the persisted CA, so clients only trust it once.
+	again := New(Config{Requests: requests, TLS: true, CertDir: certDir})
+	if err := again.Start(); err != nil {
+		t.Fatalf("Start() again error = %v", err)
+	}
+	t.Cleanup(func() { _ = again.Close() })
+	if body := getTrusting(t, caPEM, again.URL()+"/health"); body != "secure" {
+		t.Fatalf("GET /health after restart = %q, want secure", body)
+	}
+
+	exported := filepath.Join(t.TempDir(), "exported.pem")
+	if err := ExportCA(certDir, exported); err != nil {
+		t.Fatalf("ExportCA() error = %v", err)
+	}
+	if data, _ := os.ReadFile(exported); string(data) != string(caPEM) {
+		t.Fatal("ExportCA() wrote a different certificate than the CA in use")
+	}
+}
+
+func TestServerUsesProvidedCertificate(t *testing.T) {
+	certDir := t.TempDir()
+	if _, err := loadOrCreateCertificate(certDir, certHosts("")); err != nil {
+		t.Fatalf("loadOrCreateCertificate() error = %v", err)
+	}
+	srv := New(Config{
+		Host:     "127.0.0.1",
+		Requests: []MockRequest{{Method: "GET", URL: "/health", Body: "provided"}},
+		CertFile: filepath.Join(certDir, leafFileName),
+		KeyFile:  filepath.Join(certDir, leafKeyFileName),
+	})
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { _ = srv.Close() })
+
+	caPEM, _ := os.ReadFile(filepath.Join(certDir, CAFileName))
+	if body := getTrusting(t, caPEM, srv.URL()+"/health"); body != "provided" {
+		t.Fatalf("GET /health = %q, want provided", body)
+	}
+
+	if err := New(Config{CertFile: "only-cert.pem"}).Start(); err == nil {
+		t.Fatal("Start() accepted a certificate without a key")
+	}
+}
+
+func getTrusting(t *testing.T, caPEM []byte, url string) string {
+	t.Helper()
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		t.Fatal("CA certificate did not parse")
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("Get(%s) error = %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
@@ -214,6 +214,14 @@ func Parse(content string, variables map[string]string, envVars map[string]strin
 			currentRequest = make(map[string]interface{})
 		}
 
+		// Declarative assertions (@assert status == 200) may sit anywhere in the
+		// block before the body; inside the body they are body text.
+		if !inBody && strings.HasPrefix(trimmed, "@assert ") {
+			list, _ := currentRequest["assertions"].([]string)
+			currentRequest["assertions"] = append(list, strings.TrimSpace(trimmed[len("@assert"):]))
+			continue
+		}
+
 		// Request line.
 		if !inHeaders && !inBody && strings.Contains(trimmed, " ") && (strings.HasPrefix(trimmed, "GET ") || strings.HasPrefix(trimmed, "POST ") || strings.HasPrefix(trimmed, "PUT ") || strings.HasPrefix(trimmed, "DELETE ") || strings.HasPrefix(trimmed, "PATCH ") || strings.HasPrefix(trimmed, "HEAD ") || strings.HasPrefix(trimmed, "OPTIONS ")) {
 			parts := strings.Fields(trimmed)
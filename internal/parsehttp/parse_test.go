@@ -0,0 +1,51 @@
+package parsehttp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_CollectsAssertions(t *testing.T) {
+	content := `### Create User
+@assert status == 201
+POST http://localhost/users
+Content-Type: application/json
+@assert jsonpath $.id exists
+
+{"name": "Ann"}
+`
+
+	requests := Parse(content, nil, nil, nil, nil)
+	if len(requests) != 1 {
+		t.Fatalf("requests=%d want 1", len(requests))
+	}
+	want := []string{"status == 201", "jsonpath $.id exists"}
+	if got := requests[0]["assertions"]; !reflect.DeepEqual(got, want) {
+		t.Fatalf("assertions=%#v want %#v", got, want)
+	}
+	if got := requests[0]["method"]; got != "POST" {
+		t.Fatalf("method=%v want POST", got)
+	}
+	if _, ok := requests[0]["headers"].(map[string]string)["@assert jsonpath $.id exists"]; ok {
+		t.Fatalf("@assert line was read as a header")
+	}
+}
+
+func TestParse_AssertInBodyIsBodyText(t *testing.T) {
+	content := `POST http://localhost/notes
+Content-Type: text/plain
+
+@assert foo
+`
+
+	requests := Parse(content, nil, nil, nil, nil)
+	if len(requests) != 1 {
+		t.Fatalf("requests=%d want 1", len(requests))
+	}
+	if got, ok := requests[0]["assertions"]; ok {
+		t.Fatalf("assertions=%#v want none", got)
+	}
+	if got, _ := requests[0]["body"].(string); got != "@assert foo" {
+		t.Fatalf("body=%q want %q", got, "@assert foo")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strings"
 
+	"rawrequest/internal/assertions"
 	sr "rawrequest/internal/scriptruntime"
 )
 
@@ -22,7 +23,6 @@ type Dependencies struct {
 }
 
 func Execute(ctx context.Context, requests []map[string]interface{}, deps Dependencies) string {
-	var results []string
 	responseStore := make(map[string]map[string]interface{})
 
 	resolveHeaders := func(headers map[string]string) map[string]string {
@@ -106,7 +106,38 @@ func Execute(ctx context.Context, requests []map[string]interface{}, deps Depend
 		return headers
 	}
 
+	names := make([]string, len(requests))
 	for i, req := range requests {
+		names[i], _ = req["name"].(string)
+	}
+	stepper := NewStepper(names)
+	for i, req := range requests {
+		stepper.SetMaxRuns(i, readMaxRuns(req))
+	}
+
+	// One entry per request, in request order, so callers can pair entries
+	// with requests by position. A request that runs again (chain.next()
+	// loops) replaces its entry with the latest run.
+	entries := make([]chainEntry, len(requests))
+	last := -1
+	record := func(i int, entry chainEntry) {
+		entries[i] = entry
+		if i > last {
+			last = i
+		}
+	}
+
+	for {
+		i, iteration, ok, err := stepper.Next()
+		if err != nil {
+			// The request at i has already run as often as its limit allows.
+			entries[i].flow.Error = err.Error()
+			break
+		}
+		if !ok {
+			break
+		}
+		req := requests[i]
 		if ctx.Err() == context.Canceled {
 			return deps.CancelledResponse
 		}
@@ -116,6 +147,7 @@ func Execute(ctx context.Context, requests []map[string]interface{}, deps Depend
 			Variables:     safeSnapshot(deps.VariablesSnapshot),
 			ResponseStore: responseStore,
 			Assertions:    make([]sr.AssertionResult, 0),
+			Iteration:     iteration,
 		}
 
 		// Ensure headers exists in request map so scripts can safely mutate it.
@@ -138,12 +170,29 @@ func Execute(ctx context.Context, requests []map[string]interface{}, deps Depend
 			}
 		}
 
+		advance := func(entry chainEntry) {
+			entry.flow.FlowControl = scriptCtx.Flow
+			entry.flow.Runs = iteration
+			if err := stepper.Advance(i, scriptCtx.Flow); err != nil {
+				entry.flow.Error = err.Error()
+			}
+			record(i, entry)
+		}
+
+		// request.skip() and chain.stop() in a pre-script keep the request from being sent.
+		if scriptCtx.Flow.Skipped || scriptCtx.Flow.Stopped {
+			advance(unsentEntry(flowStatusText(scriptCtx.Flow), DescribeFlow(scriptCtx.Flow)))
+			continue
+		}
+
 		method, ok := req["method"].(string)
 		if !ok {
+			advance(unsentEntry("Not Sent", "request has no method"))
 			continue
 		}
 		url, ok := req["url"].(string)
 		if !ok {
+			advance(unsentEntry("Not Sent", "request has no url"))
 			continue
 		}
 		body, _ := req["body"].(string)
@@ -165,7 +214,7 @@ func Execute(ctx context.Context, requests []map[string]interface{}, deps Depend
 		// On any request error (including timeout), stop the chain and return partial results.
 		// The caller can parse/display the error response as the final chain step.
 		if strings.HasPrefix(resultRaw, "Error:") || strings.HasPrefix(resultRaw, "Error ") {
-			results = append(results, resultRaw)
+			record(i, chainEntry{result: resultRaw})
 			break
 		}
 
@@ -191,18 +240,94 @@ func Execute(ctx context.Context, requests []map[string]interface{}, deps Depend
 			}
 		}
 
+		// Declarative @assert lines are evaluated in Go after the post-script.
+		if exprs := assertions.FromValue(req["assertions"]); len(exprs) > 0 {
+			scriptCtx.Assertions = append(scriptCtx.Assertions, assertions.Evaluate(exprs, responseData)...)
+		}
+
 		result := resultRaw
 		if len(scriptCtx.Assertions) > 0 {
 			if b, err := json.Marshal(scriptCtx.Assertions); err == nil {
 				result += "\nAsserts: " + string(b)
 			}
 		}
-		results = append(results, result)
+		advance(chainEntry{result: result})
 	}
 
+	results := make([]string, 0, last+1)
+	for _, entry := range entries[:last+1] {
+		if entry.result == "" {
+			// chain.next() jumped past this request.
+			entry = unsentEntry("Not Run", "not run")
+		}
+		results = append(results, entry.String())
+	}
 	return strings.Join(results, "\n\n")
 }
 
+// chainEntry is the output for one request of a chain.
+type chainEntry struct {
+	result string
+	flow   chainFlow
+}
+
+// chainFlow is the "Flow: {json}" line of a chain entry: the flow decisions
+// of the request's last run, how often it ran and the error that ended the
+// chain there, if any.
+type chainFlow struct {
+	sr.FlowControl
+	Runs  int    `json:"runs,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+func (f chainFlow) isZero() bool {
+	return f.FlowControl.IsZero() && f.Runs <= 1 && f.Error == ""
+}
+
+func (e chainEntry) String() string {
+	// Request errors are passed through as-is; they always end the chain.
+	if e.flow.isZero() || strings.HasPrefix(e.result, "Error") {
+		return e.result
+	}
+	if e.flow.Runs <= 1 {
+		e.flow.Runs = 0
+	}
+	return withFlowLine(e.result, e.flow)
+}
+
+// unsentEntry is the chain entry for a request that was not sent, e.g.
+// because a pre-script skipped it or the chain stopped.
+func unsentEntry(statusText, reason string) chainEntry {
+	return chainEntry{result: fmt.Sprintf("Status: 0 %s\nBody: %s", statusText, singleLine(reason))}
+}
+
+func flowStatusText(flow sr.FlowControl) string {
+	if flow.Stopped && !flow.Skipped {
+		return "Stopped"
+	}
+	return "Skipped"
+}
+
+// withFlowLine adds a "Flow: {json}" line ahead of the body, since everything
+// after "Body: " is read as the body.
+func withFlowLine(result string, flow chainFlow) string {
+	b, err := json.Marshal(flow)
+	if err != nil {
+		return result
+	}
+	line := "Flow: " + string(b)
+	if idx := strings.Index(result, "\nBody: "); idx >= 0 {
+		return result[:idx+1] + line + result[idx:]
+	}
+	return result + "\n" + line
+}
+
+// singleLine keeps script-provided reasons from splitting a chain result,
+// which are separated by blank lines.
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
 func safeSnapshot(snapshot func() map[string]string) map[string]string {
 	if snapshot == nil {
 		return map[string]string{}
@@ -213,3 +338,16 @@ func safeSnapshot(snapshot func() map[string]string) map[string]string {
 	}
 	return vars
 }
+
+// readMaxRuns returns the request's `@max-runs` limit from options.maxRuns,
+// or 0 when it has none.
+func readMaxRuns(req map[string]interface{}) int {
+	options, _ := req["options"].(map[string]interface{})
+	switch n := options["maxRuns"].(type) {
+	case float64:
+		return int(n)
+	case int:
+		return n
+	}
+	return 0
+}
@@ -157,3 +157,34 @@ func TestExecute_ErrorStopsChain(t *testing.T) {
 		t.Fatalf("got %q", got)
 	}
 }
+
+func TestExecute_EvaluatesDeclarativeAssertionsAfterPostScript(t *testing.T) {
+	deps := Dependencies{
+		CancelledResponse: "__CANCELLED__",
+		PerformRequest: func(_ context.Context, _, _, _, _, _ string, _ int) string {
+			return "resp"
+		},
+		ParseResponse: func(_ string) map[string]interface{} {
+			return map[string]interface{}{"status": 404, "body": `{"ok":false}`}
+		},
+		ExecuteScript: func(_ string, ctx *sr.ExecutionContext, _ string) {
+			ctx.Assertions = append(ctx.Assertions, sr.AssertionResult{Passed: true, Message: "js", Stage: "post"})
+		},
+	}
+
+	requests := []map[string]interface{}{
+		{
+			"method":     "GET",
+			"url":        "http://example.com",
+			"postScript": "assert(true, 'js')",
+			"assertions": []interface{}{"status == 404", "jsonpath $.ok == true"},
+		},
+	}
+
+	got := Execute(context.Background(), requests, deps)
+	want := `resp
+Asserts: [{"passed":true,"message":"js","stage":"post"},{"passed":true,"message":"status == 404","stage":"assert"},{"passed":false,"message":"jsonpath $.ok == true (got false)","stage":"assert"}]`
+	if got != want {
+		t.Fatalf("got %q\nwant %q", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package requestchain
+
+import (
+	"fmt"
+	"strings"
+
+	sr "rawrequest/internal/scriptruntime"
+)
+
+// MaxRequestRuns bounds how often one request may run within a chain, so a
+// chain.next() polling loop without its own exit condition still ends. A
+// request can raise or lower its own limit with `@max-runs`.
+const MaxRequestRuns = 100
+
+// Stepper walks a list of requests, following chain.next() jumps and
+// counting how often each request has run. Both the app chain executor and
+// the CLI use it so flow control behaves the same everywhere.
+type Stepper struct {
+	names  []string
+	runs   []int
+	limits []int
+	pos    int
+}
+
+// NewStepper creates a stepper over requests with the given names, starting
+// at the first one. Names are matched case-insensitively; empty names can
+// only be reached sequentially.
+func NewStepper(names []string) *Stepper {
+	return &Stepper{names: names, runs: make([]int, len(names)), limits: make([]int, len(names))}
+}
+
+// SetMaxRuns overrides MaxRequestRuns for the request at index. n <= 0
+// restores the default.
+func (s *Stepper) SetMaxRuns(index, n int) {
+	if index >= 0 && index < len(s.limits) {
+		s.limits[index] = n
+	}
+}
+
+// Next returns the index of the request to run and its 1-based iteration.
+// ok is false once the list is exhausted. err is set when a request would
+// exceed its run limit.
+func (s *Stepper) Next() (index, iteration int, ok bool, err error) {
+	if s.pos < 0 || s.pos >= len(s.names) {
+		return 0, 0, false, nil
+	}
+	index = s.pos
+	if limit := s.maxRuns(index); s.runs[index] >= limit {
+		return index, s.runs[index], false, fmt.Errorf("request %s ran %d times (@max-runs limit); stopping chain", s.label(index), limit)
+	}
+	s.runs[index]++
+	return index, s.runs[index], true, nil
+}
+
+// Advance moves past the request at index according to the flow decisions
+// its scripts made. It returns an error when chain.next() names an unknown
+// request; the chain stops in that case.
+func (s *Stepper) Advance(index int, flow sr.FlowControl) error {
+	switch {
+	case flow.Stopped:
+		s.pos = len(s.names)
+	case flow.Next != "":
+		target := s.find(flow.Next)
+		if target < 0 {
+			s.pos = len(s.names)
+			return fmt.Errorf("chain.next(): no request named %q", flow.Next)
+		}
+		s.pos = target
+	default:
+		s.pos = index + 1
+	}
+	return nil
+}
+
+func (s *Stepper) maxRuns(index int) int {
+	if n := s.limits[index]; n > 0 {
+		return n
+	}
+	return MaxRequestRuns
+}
+
+func (s *Stepper) find(name string) int {
+	name = strings.TrimSpace(name)
+	for i, candidate := range s.names {
+		if candidate != "" && strings.EqualFold(candidate, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *Stepper) label(index int) string {
+	if name := s.names[index]; name != "" {
+		return fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf("#%d", index+1)
+}
+
+// DescribeFlow renders the flow state for output, e.g.
+// "skipped: user exists" or "chain stopped: job failed".
+func DescribeFlow(flow sr.FlowControl) string {
+	var parts []string
+	if flow.Skipped {
+		parts = append(parts, withReason("skipped", flow.SkipReason))
+	}
+	if flow.Stopped {
+		parts = append(parts, withReason("chain stopped", flow.StopReason))
+	}
+	if flow.Next != "" && !flow.Stopped {
+		parts = append(parts, "next: "+flow.Next)
+	}
+	return strings.Join(parts, "; ")
+}
+
+func withReason(label, reason string) string {
+	if reason = strings.TrimSpace(reason); reason != "" {
+		return label + ": " + reason
+	}
+	return label
+}
@@ -0,0 +1,229 @@
+package requestchain
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	so "rawrequest/internal/scriptops"
+	sr "rawrequest/internal/scriptruntime"
+)
+
+func flowDeps(sent *[]string, scripts map[string]func(ctx *sr.ExecutionContext)) Dependencies {
+	return Dependencies{
+		CancelledResponse: "__CANCELLED__",
+		ExecuteScript: func(script string, ctx *sr.ExecutionContext, _ string) {
+			if fn := scripts[script]; fn != nil {
+				fn(ctx)
+			}
+		},
+		PerformRequest: func(_ context.Context, _, _, url, _, _ string, _ int) string {
+			*sent = append(*sent, url)
+			return "Status: 200 OK\nBody: " + url
+		},
+		ParseResponse: func(resp string) map[string]interface{} {
+			return map[string]interface{}{"body": resp}
+		},
+	}
+}
+
+func TestExecute_PreScriptSkipDoesNotSend(t *testing.T) {
+	var sent []string
+	deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+		"skip": func(ctx *sr.ExecutionContext) { so.Skip(ctx, "user exists") },
+	})
+	requests := []map[string]interface{}{
+		{"name": "createUser", "method": "POST", "url": "/users", "preScript": "skip"},
+		{"name": "getUser", "method": "GET", "url": "/users/1"},
+	}
+
+	got := Execute(context.Background(), requests, deps)
+	if strings.Join(sent, ",") != "/users/1" {
+		t.Fatalf("sent=%v want only /users/1", sent)
+	}
+	parts := strings.Split(got, "\n\n")
+	if len(parts) != 2 {
+		t.Fatalf("results=%d want 2: %q", len(parts), got)
+	}
+	want := "Status: 0 Skipped\nFlow: {\"skipped\":true,\"skipReason\":\"user exists\"}\nBody: skipped: user exists"
+	if parts[0] != want {
+		t.Fatalf("skipped result=%q want %q", parts[0], want)
+	}
+}
+
+func TestExecute_ChainNextLoopsUntilDone(t *testing.T) {
+	var sent []string
+	deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+		"poll": func(ctx *sr.ExecutionContext) {
+			if ctx.Iteration < 3 {
+				so.NextRequest(ctx, "GETJOBSTATUS")
+			}
+		},
+	})
+	requests := []map[string]interface{}{
+		{"name": "startJob", "method": "POST", "url": "/jobs"},
+		{"name": "getJobStatus", "method": "GET", "url": "/jobs/1", "postScript": "poll"},
+		{"name": "cleanup", "method": "DELETE", "url": "/jobs/1/tmp"},
+	}
+
+	got := Execute(context.Background(), requests, deps)
+	if sentURLs := strings.Join(sent, ","); sentURLs != "/jobs,/jobs/1,/jobs/1,/jobs/1,/jobs/1/tmp" {
+		t.Fatalf("sent=%s", sentURLs)
+	}
+	parts := strings.Split(got, "\n\n")
+	if len(parts) != 3 {
+		t.Fatalf("results=%d want one per request: %q", len(parts), got)
+	}
+	if want := "Status: 200 OK\nFlow: {\"runs\":3}\nBody: /jobs/1"; parts[1] != want {
+		t.Fatalf("looped result=%q want %q", parts[1], want)
+	}
+}
+
+func TestExecute_ChainNextJumpLeavesNotRunEntry(t *testing.T) {
+	var sent []string
+	deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+		"jump": func(ctx *sr.ExecutionContext) { so.NextRequest(ctx, "c") },
+	})
+	requests := []map[string]interface{}{
+		{"name": "a", "method": "GET", "url": "/a", "postScript": "jump"},
+		{"name": "b", "method": "GET", "url": "/b"},
+		{"name": "c", "method": "GET", "url": "/c"},
+	}
+
+	got := Execute(context.Background(), requests, deps)
+	parts := strings.Split(got, "\n\n")
+	if len(parts) != 3 {
+		t.Fatalf("results=%d want 3: %q", len(parts), got)
+	}
+	if parts[1] != "Status: 0 Not Run\nBody: not run" || parts[2] != "Status: 200 OK\nBody: /c" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestExecute_PreScriptFlowKeptWithoutURL(t *testing.T) {
+	var sent []string
+	deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+		"jump": func(ctx *sr.ExecutionContext) { so.NextRequest(ctx, "c") },
+	})
+	requests := []map[string]interface{}{
+		{"name": "a", "method": "GET", "preScript": "jump"},
+		{"name": "b", "method": "GET", "url": "/b"},
+		{"name": "c", "method": "GET", "url": "/c"},
+	}
+
+	got := Execute(context.Background(), requests, deps)
+	if strings.Join(sent, ",") != "/c" {
+		t.Fatalf("sent=%v want only /c", sent)
+	}
+	if !strings.HasPrefix(got, "Status: 0 Not Sent\nFlow: {\"next\":\"c\"}\nBody: request has no url\n\n") {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestExecute_ChainStop(t *testing.T) {
+	t.Run("post-script keeps current result", func(t *testing.T) {
+		var sent []string
+		deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+			"stop": func(ctx *sr.ExecutionContext) { so.StopChain(ctx, "job failed") },
+		})
+		requests := []map[string]interface{}{
+			{"method": "GET", "url": "/a", "postScript": "stop"},
+			{"method": "GET", "url": "/b"},
+		}
+		got := Execute(context.Background(), requests, deps)
+		if strings.Join(sent, ",") != "/a" {
+			t.Fatalf("sent=%v want only /a", sent)
+		}
+		want := "Status: 200 OK\nFlow: {\"stopped\":true,\"stopReason\":\"job failed\"}\nBody: /a"
+		if got != want {
+			t.Fatalf("got %q want %q", got, want)
+		}
+	})
+
+	t.Run("pre-script prevents sending", func(t *testing.T) {
+		var sent []string
+		deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+			"stop": func(ctx *sr.ExecutionContext) { so.StopChain(ctx, "") },
+		})
+		requests := []map[string]interface{}{
+			{"method": "GET", "url": "/a", "preScript": "stop"},
+			{"method": "GET", "url": "/b"},
+		}
+		got := Execute(context.Background(), requests, deps)
+		if len(sent) != 0 {
+			t.Fatalf("sent=%v want none", sent)
+		}
+		if !strings.HasPrefix(got, "Status: 0 Stopped\n") || strings.Contains(got, "\n\n") {
+			t.Fatalf("got %q want a single Stopped result", got)
+		}
+	})
+}
+
+func TestExecute_ChainNextErrors(t *testing.T) {
+	t.Run("unknown request", func(t *testing.T) {
+		var sent []string
+		deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+			"jump": func(ctx *sr.ExecutionContext) { so.NextRequest(ctx, "missing") },
+		})
+		requests := []map[string]interface{}{
+			{"method": "GET", "url": "/a", "postScript": "jump"},
+			{"method": "GET", "url": "/b"},
+		}
+		got := Execute(context.Background(), requests, deps)
+		want := "Status: 200 OK\nFlow: {\"next\":\"missing\",\"error\":\"chain.next(): no request named \\\"missing\\\"\"}\nBody: /a"
+		if len(sent) != 1 || got != want {
+			t.Fatalf("sent=%v got %q", sent, got)
+		}
+	})
+
+	t.Run("run limit", func(t *testing.T) {
+		var sent []string
+		deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+			"again": func(ctx *sr.ExecutionContext) { so.NextRequest(ctx, "poll") },
+		})
+		requests := []map[string]interface{}{
+			{"name": "poll", "method": "GET", "url": "/a", "postScript": "again"},
+		}
+		got := Execute(context.Background(), requests, deps)
+		if len(sent) != MaxRequestRuns {
+			t.Fatalf("sent %d requests want %d", len(sent), MaxRequestRuns)
+		}
+		if !strings.Contains(got, `"runs":100,"error":"request \"poll\" ran 100 times (@max-runs limit); stopping chain"`) || strings.Contains(got, "\n\n") {
+			t.Fatalf("want a single entry with the run-limit error, got %q", got)
+		}
+	})
+
+	t.Run("per-request run limit", func(t *testing.T) {
+		var sent []string
+		deps := flowDeps(&sent, map[string]func(*sr.ExecutionContext){
+			"again": func(ctx *sr.ExecutionContext) { so.NextRequest(ctx, "poll") },
+		})
+		requests := []map[string]interface{}{
+			{"name": "poll", "method": "GET", "url": "/a", "postScript": "again", "options": map[string]interface{}{"maxRuns": float64(3)}},
+		}
+		got := Execute(context.Background(), requests, deps)
+		if len(sent) != 3 {
+			t.Fatalf("sent %d requests want 3", len(sent))
+		}
+		if !strings.Contains(got, `"error":"request \"poll\" ran 3 times (@max-runs limit); stopping chain"`) {
+			t.Fatalf("want the per-request run-limit error, got %q", got)
+		}
+	})
+}
+
+func TestDescribeFlow(t *testing.T) {
+	cases := []struct {
+		flow sr.FlowControl
+		want string
+	}{
+		{sr.FlowControl{}, ""},
+		{sr.FlowControl{Skipped: true}, "skipped"},
+		{sr.FlowControl{Skipped: true, SkipReason: "exists", Next: "b"}, "skipped: exists; next: b"},
+		{sr.FlowControl{Stopped: true, StopReason: "done", Next: "b"}, "chain stopped: done"},
+	}
+	for _, tc := range cases {
+		if got := DescribeFlow(tc.flow); got != tc.want {
+			t.Fatalf("DescribeFlow(%+v)=%q want %q", tc.flow, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package scriptdebug
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Action tells a paused script how to resume.
+type Action string
+
+const (
+	ActionContinue Action = "continue"
+	ActionStep     Action = "step"
+)
+
+// Pause reasons reported in PausedState.
+const (
+	ReasonDebugger   = "debugger"
+	ReasonBreakpoint = "breakpoint"
+	ReasonStep       = "step"
+)
+
+// DefaultPauseTimeout bounds how long a script stays paused when no client
+// resumes it, so a closed UI cannot hang a request chain forever.
+const DefaultPauseTimeout = 10 * time.Minute
+
+// PausedState is the snapshot published when a script pauses. All values are
+// copies, so it is safe to encode after the script has resumed.
+type PausedState struct {
+	ID        string                 `json:"id"`
+	Source    string                 `json:"source"`
+	Stage     string                 `json:"stage"`
+	Line      int                    `json:"line"`
+	Reason    string                 `json:"reason"`
+	Locals    map[string]interface{} `json:"locals"`
+	Request   interface{}            `json:"request"`
+	Response  interface{}            `json:"response"`
+	Variables map[string]string      `json:"variables"`
+}
+
+// Controller holds the debug settings shared by every script run and the set
+// of currently paused scripts. Its zero value is not usable; use
+// NewController.
+type Controller struct {
+	mu          sync.Mutex
+	enabled     bool
+	breakpoints map[string]map[int]bool
+	paused      map[string]chan Action
+	nextID      uint64
+
+	onPause  func(PausedState)
+	onResume func(id string, action Action)
+	timeout  time.Duration
+}
+
+// NewController creates a disabled controller. onPause and onResume are
+// called outside the controller lock and may be nil.
+func NewController(onPause func(PausedState), onResume func(id string, action Action)) *Controller {
+	return &Controller{
+		breakpoints: make(map[string]map[int]bool),
+		paused:      make(map[string]chan Action),
+		onPause:     onPause,
+		onResume:    onResume,
+		timeout:     DefaultPauseTimeout,
+	}
+}
+
+// Configure switches debug mode and replaces the breakpoints. Breakpoints are
+// keyed by script source (e.g. "pre:login"); the "*" key applies to every
+// script. Disabling debug mode resumes every paused script.
+func (c *Controller) Configure(enabled bool, breakpoints map[string][]int) {
+	c.mu.Lock()
+	c.enabled = enabled
+	c.breakpoints = make(map[string]map[int]bool, len(breakpoints))
+	for source, lines := range breakpoints {
+		source = strings.TrimSpace(source)
+		if len(lines) == 0 {
+			continue
+		}
+		set := make(map[int]bool, len(lines))
+		for _, line := range lines {
+			set[line] = true
+		}
+		c.breakpoints[source] = set
+	}
+	var release []string
+	if !enabled {
+		for id := range c.paused {
+			release = append(release, id)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, id := range release {
+		_ = c.Resume(id, ActionContinue)
+	}
+}
+
+// Enabled reports whether scripts should be instrumented.
+func (c *Controller) Enabled() bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.enabled
+}
+
+// ShouldPause decides whether the statement at line pauses, returning the
+// reason when it does. stepping is true after the previous pause resumed
+// with ActionStep.
+func (c *Controller) ShouldPause(source string, line int, debuggerStatement, stepping bool) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.enabled {
+		return "", false
+	}
+	switch {
+	case debuggerStatement:
+		return ReasonDebugger, true
+	case c.breakpoints[source][line] || c.breakpoints["*"][line]:
+		return ReasonBreakpoint, true
+	case stepping:
+		return ReasonStep, true
+	}
+	return "", false
+}
+
+// Pause publishes state and blocks until Resume is called with its ID, debug
+// mode is disabled, or the pause timeout elapses (which continues).
+func (c *Controller) Pause(state PausedState) Action {
+	ch := make(chan Action, 1)
+	c.mu.Lock()
+	c.nextID++
+	state.ID = strconv.FormatUint(c.nextID, 10)
+	c.paused[state.ID] = ch
+	timeout := c.timeout
+	c.mu.Unlock()
+
+	if c.onPause != nil {
+		c.onPause(state)
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case action := <-ch:
+		return action
+	case <-timer.C:
+		c.mu.Lock()
+		delete(c.paused, state.ID)
+		c.mu.Unlock()
+		if c.onResume != nil {
+			c.onResume(state.ID, ActionContinue)
+		}
+		return ActionContinue
+	}
+}
+
+// Resume releases the paused script with the given ID.
+func (c *Controller) Resume(id string, action Action) error {
+	if action != ActionContinue && action != ActionStep {
+		return fmt.Errorf("unknown debug action %q", action)
+	}
+	c.mu.Lock()
+	ch, ok := c.paused[id]
+	if ok {
+		delete(c.paused, id)
+	}
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("no paused script with id %q", id)
+	}
+	ch <- action
+	if c.onResume != nil {
+		c.onResume(id, action)
+	}
+	return nil
+}
+
+// PausedIDs lists the IDs of scripts that are currently paused, oldest first.
+func (c *Controller) PausedIDs() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ids := make([]string, 0, len(c.paused))
+	for id := range c.paused {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.ParseUint(ids[i], 10, 64)
+		b, _ := strconv.ParseUint(ids[j], 10, 64)
+		return a < b
+	})
+	return ids
+}
@@ -0,0 +1,95 @@
+package scriptdebug
+
+import (
+	"testing"
+	"time"
+)
+
+func TestController_ShouldPause(t *testing.T) {
+	c := NewController(nil, nil)
+	if _, ok := c.ShouldPause("pre:a", 1, true, false); ok {
+		t.Fatalf("disabled controller must not pause")
+	}
+	c.Configure(true, map[string][]int{"pre:a": {3}, "*": {7}})
+
+	cases := []struct {
+		source    string
+		line      int
+		debugger  bool
+		stepping  bool
+		want      string
+		wantPause bool
+	}{
+		{"pre:a", 1, true, false, ReasonDebugger, true},
+		{"pre:a", 3, false, false, ReasonBreakpoint, true},
+		{"post:b", 3, false, false, "", false},
+		{"post:b", 7, false, false, ReasonBreakpoint, true},
+		{"post:b", 4, false, true, ReasonStep, true},
+	}
+	for _, tc := range cases {
+		got, ok := c.ShouldPause(tc.source, tc.line, tc.debugger, tc.stepping)
+		if ok != tc.wantPause || got != tc.want {
+			t.Fatalf("ShouldPause(%s,%d)=(%q,%v) want (%q,%v)", tc.source, tc.line, got, ok, tc.want, tc.wantPause)
+		}
+	}
+}
+
+func TestController_PauseAndResume(t *testing.T) {
+	paused := make(chan PausedState, 1)
+	var resumed []Action
+	c := NewController(func(s PausedState) { paused <- s }, func(_ string, a Action) { resumed = append(resumed, a) })
+	c.Configure(true, nil)
+
+	done := make(chan Action, 1)
+	go func() { done <- c.Pause(PausedState{Line: 2}) }()
+
+	state := <-paused
+	if state.ID == "" || state.Line != 2 {
+		t.Fatalf("unexpected paused state %+v", state)
+	}
+	if ids := c.PausedIDs(); len(ids) != 1 || ids[0] != state.ID {
+		t.Fatalf("PausedIDs=%v want [%s]", ids, state.ID)
+	}
+	if err := c.Resume(state.ID, ActionStep); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if got := <-done; got != ActionStep {
+		t.Fatalf("action=%q want step", got)
+	}
+	if err := c.Resume(state.ID, ActionContinue); err == nil {
+		t.Fatalf("resuming twice should fail")
+	}
+	if len(resumed) != 1 || resumed[0] != ActionStep {
+		t.Fatalf("onResume calls=%v", resumed)
+	}
+}
+
+func TestController_DisableReleasesPaused(t *testing.T) {
+	c := NewController(nil, nil)
+	c.Configure(true, nil)
+	done := make(chan Action, 1)
+	go func() { done <- c.Pause(PausedState{}) }()
+	for len(c.PausedIDs()) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	c.Configure(false, nil)
+	select {
+	case got := <-done:
+		if got != ActionContinue {
+			t.Fatalf("action=%q want continue", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("disabling debug mode did not release the paused script")
+	}
+}
+
+func TestController_PauseTimeoutContinues(t *testing.T) {
+	c := NewController(nil, nil)
+	c.timeout = 10 * time.Millisecond
+	if got := c.Pause(PausedState{}); got != ActionContinue {
+		t.Fatalf("action=%q want continue", got)
+	}
+	if ids := c.PausedIDs(); len(ids) != 0 {
+		t.Fatalf("timed-out pause still registered: %v", ids)
+	}
+}
@@ -0,0 +1,317 @@
+package scriptdebug
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dop251/goja/ast"
+	"github.com/dop251/goja/parser"
+)
+
+// HookName is the global function instrumented scripts call before each
+// statement. scriptexec installs it on the VM when debugging is enabled.
+const HookName = "__rrDebug"
+
+// instrumentPrefix mirrors the wrapper scriptexec runs scripts in, so scripts
+// that `return` early, use top-level `await` or start with 'use strict' parse
+// the same way here.
+const instrumentPrefix = "(async function(context, request, response){\n"
+
+type insertion struct {
+	offset   int
+	line     int
+	debugger bool
+	names    []string
+}
+
+// Instrument rewrites a cleaned script so every statement in a statement list
+// is preceded by a call to HookName:
+//
+//	__rrDebug(line, isDebuggerStatement, ["local", ...], function(__rrExpr){ return eval(__rrExpr) });
+//
+// The eval closure is created at the statement's position, so the hook can
+// read locals that are in scope there. Lines are 1-based within the script and
+// no newlines are added, so runtime error positions are unchanged.
+func Instrument(script string) (string, error) {
+	src := instrumentPrefix + script + "\n})"
+	program, err := parser.ParseFile(nil, "", src, 0)
+	if err != nil {
+		return "", fmt.Errorf("parse script: %w", err)
+	}
+
+	w := &walker{src: src}
+	w.visit(reflect.ValueOf(program.Body), nil)
+	if len(w.inserts) == 0 {
+		return script, nil
+	}
+
+	sort.SliceStable(w.inserts, func(i, j int) bool { return w.inserts[i].offset < w.inserts[j].offset })
+	var b strings.Builder
+	last := len(instrumentPrefix)
+	for _, ins := range w.inserts {
+		if ins.offset < last {
+			continue
+		}
+		b.WriteString(src[last:ins.offset])
+		b.WriteString(hookCall(ins))
+		last = ins.offset
+	}
+	b.WriteString(src[last : len(src)-len("\n})")])
+	return b.String(), nil
+}
+
+func hookCall(ins insertion) string {
+	quoted := make([]string, len(ins.names))
+	for i, name := range ins.names {
+		quoted[i] = strconv.Quote(name)
+	}
+	return fmt.Sprintf(";%s(%d,%t,[%s],function(__rrExpr){return eval(__rrExpr)});",
+		HookName, ins.line, ins.debugger, strings.Join(quoted, ","))
+}
+
+type walker struct {
+	src     string
+	inserts []insertion
+	// depth counts nested functions; the outermost one is the scriptexec
+	// wrapper, whose body is the user script.
+	depth int
+}
+
+var statementListType = reflect.TypeOf([]ast.Statement(nil))
+
+// visit walks the AST generically via reflection, only special-casing the
+// nodes that introduce a scope or hold a statement list. names is the list of
+// locals visible from the enclosing functions.
+func (w *walker) visit(v reflect.Value, names []string) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Ptr {
+			switch n := v.Interface().(type) {
+			case *ast.FunctionLiteral:
+				w.visitFunction(n.ParameterList, n.Body, n.Name, names)
+				return
+			case *ast.ArrowFunctionLiteral:
+				body, _ := n.Body.(*ast.BlockStatement)
+				if body == nil {
+					w.visit(reflect.ValueOf(n.Body), names)
+					return
+				}
+				w.visitFunction(n.ParameterList, body, nil, names)
+				return
+			case *ast.BlockStatement:
+				w.visitList(n.List, names, false)
+				return
+			case *ast.CaseStatement:
+				w.visit(reflect.ValueOf(n.Test), names)
+				w.visitList(n.Consequent, names, false)
+				return
+			}
+		}
+		w.visit(v.Elem(), names)
+	case reflect.Slice:
+		if v.Type() == statementListType {
+			w.visitList(v.Interface().([]ast.Statement), names, false)
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			w.visit(v.Index(i), names)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				w.visit(v.Field(i), names)
+			}
+		}
+	}
+}
+
+func (w *walker) visitFunction(params *ast.ParameterList, body *ast.BlockStatement, name *ast.Identifier, outer []string) {
+	if body == nil {
+		return
+	}
+	w.depth++
+	defer func() { w.depth-- }()
+
+	var names []string
+	// The wrapper's own parameters are published separately as request,
+	// response and context, so they are left out of locals.
+	if w.depth > 1 {
+		names = append(names, outer...)
+		if name != nil {
+			names = append(names, string(name.Name))
+		}
+		if params != nil {
+			collectBindingNames(reflect.ValueOf(params), &names)
+		}
+	}
+	collectDeclaredNames(reflect.ValueOf(body.List), &names)
+	if params != nil {
+		for _, p := range params.List {
+			if p != nil && p.Initializer != nil {
+				w.visit(reflect.ValueOf(p.Initializer), outer)
+			}
+		}
+	}
+	w.visitList(body.List, dedupe(names), true)
+}
+
+func (w *walker) visitList(list []ast.Statement, names []string, functionBody bool) {
+	prologue := functionBody
+	for _, stmt := range list {
+		if stmt == nil {
+			continue
+		}
+		// Hooks must not precede a directive prologue such as 'use strict'.
+		if prologue && isDirective(stmt) {
+			continue
+		}
+		prologue = false
+		if w.depth > 0 {
+			_, isDebugger := stmt.(*ast.DebuggerStatement)
+			offset := w.statementOffset(stmt)
+			if offset >= len(instrumentPrefix) && offset < len(w.src) {
+				w.inserts = append(w.inserts, insertion{
+					offset:   offset,
+					line:     strings.Count(w.src[:offset], "\n"),
+					debugger: isDebugger,
+					names:    names,
+				})
+			}
+		}
+		w.visit(reflect.ValueOf(stmt), names)
+	}
+}
+
+// statementOffset returns the source offset a statement starts at. goja's
+// parser leaves IfStatement.If unset, so for `if` the keyword is found by
+// scanning back from the condition.
+func (w *walker) statementOffset(stmt ast.Statement) int {
+	if n, ok := stmt.(*ast.IfStatement); ok && n.If == 0 && n.Test != nil {
+		paren := strings.LastIndexByte(w.src[:int(n.Test.Idx0())-1], '(')
+		if paren < 0 {
+			return -1
+		}
+		return strings.LastIndex(w.src[:paren], "if")
+	}
+	return int(stmt.Idx0()) - 1
+}
+
+func isDirective(stmt ast.Statement) bool {
+	expr, ok := stmt.(*ast.ExpressionStatement)
+	if !ok {
+		return false
+	}
+	_, ok = expr.Expression.(*ast.StringLiteral)
+	return ok
+}
+
+// collectDeclaredNames gathers var/let/const/function/class and catch/loop
+// bindings declared in a function body without descending into nested
+// functions. Block-scoped names from sibling blocks are included too; reading
+// one that is out of scope simply fails and is skipped by the hook.
+func collectDeclaredNames(v reflect.Value, names *[]string) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Ptr {
+			switch n := v.Interface().(type) {
+			case *ast.FunctionDeclaration:
+				if n.Function != nil && n.Function.Name != nil {
+					*names = append(*names, string(n.Function.Name.Name))
+				}
+				return
+			case *ast.ClassDeclaration:
+				if n.Class != nil && n.Class.Name != nil {
+					*names = append(*names, string(n.Class.Name.Name))
+				}
+				return
+			case *ast.FunctionLiteral, *ast.ArrowFunctionLiteral, *ast.ClassLiteral:
+				return
+			case *ast.Binding:
+				collectBindingNames(reflect.ValueOf(n.Target), names)
+				return
+			case *ast.ForDeclaration:
+				collectBindingNames(reflect.ValueOf(n.Target), names)
+				return
+			case *ast.CatchStatement:
+				collectBindingNames(reflect.ValueOf(n.Parameter), names)
+				collectDeclaredNames(reflect.ValueOf(n.Body), names)
+				return
+			}
+		}
+		collectDeclaredNames(v.Elem(), names)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectDeclaredNames(v.Index(i), names)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectDeclaredNames(v.Field(i), names)
+			}
+		}
+	}
+}
+
+// collectBindingNames records every identifier bound by a binding target,
+// including those nested in destructuring patterns. Default-value expressions
+// are skipped.
+func collectBindingNames(v reflect.Value, names *[]string) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return
+		}
+		if v.Kind() == reflect.Ptr {
+			switch n := v.Interface().(type) {
+			case *ast.Identifier:
+				*names = append(*names, string(n.Name))
+				return
+			case *ast.Binding:
+				collectBindingNames(reflect.ValueOf(n.Target), names)
+				return
+			case *ast.PropertyShort:
+				*names = append(*names, string(n.Name.Name))
+				return
+			case *ast.PropertyKeyed:
+				collectBindingNames(reflect.ValueOf(n.Value), names)
+				return
+			case *ast.AssignExpression:
+				collectBindingNames(reflect.ValueOf(n.Left), names)
+				return
+			}
+		}
+		collectBindingNames(v.Elem(), names)
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			collectBindingNames(v.Index(i), names)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				collectBindingNames(v.Field(i), names)
+			}
+		}
+	}
+}
+
+func dedupe(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		out = append(out, name)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package scriptdebug
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInstrument_InsertsHookPerStatement(t *testing.T) {
+	script := "'use strict';\nvar a = 1;\nif (a) {\n  let b = a + 1;\n  debugger;\n}"
+	out, err := Instrument(script)
+	if err != nil {
+		t.Fatalf("Instrument: %v", err)
+	}
+	if !strings.HasPrefix(out, "'use strict';") {
+		t.Fatalf("directive prologue must stay first, got %q", out)
+	}
+	if strings.Count(out, "\n") != strings.Count(script, "\n") {
+		t.Fatalf("instrumentation must not add lines:\n%s", out)
+	}
+	for _, want := range []string{
+		HookName + `(2,false,["a","b"]`,
+		HookName + `(3,false,["a","b"]`,
+		HookName + `(4,false,["a","b"]`,
+		HookName + `(5,true,["a","b"]`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestInstrument_NestedFunctionSeesParamsAndOuterLocals(t *testing.T) {
+	script := "const items = [1];\nitems.forEach(function (item, idx) {\n  const { id: itemId } = { id: item };\n  console.log(itemId);\n});"
+	out, err := Instrument(script)
+	if err != nil {
+		t.Fatalf("Instrument: %v", err)
+	}
+	if !strings.Contains(out, HookName+`(4,false,["items","item","idx","itemId"]`) {
+		t.Fatalf("nested hook missing expected locals:\n%s", out)
+	}
+}
+
+func TestInstrument_AllowsTopLevelReturn(t *testing.T) {
+	if _, err := Instrument("if (!response) return;\nconsole.log(1);"); err != nil {
+		t.Fatalf("Instrument: %v", err)
+	}
+}
+
+func TestInstrument_SyntaxError(t *testing.T) {
+	if _, err := Instrument("var = ;"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestInstrument_AllowsTopLevelAwait(t *testing.T) {
+	out, err := Instrument("const v = await Promise.resolve(1);\ndebugger;")
+	if err != nil {
+		t.Fatalf("Instrument: %v", err)
+	}
+	if !strings.Contains(out, HookName+`(2,true,["v"]`) {
+		t.Fatalf("missing debugger hook:\n%s", out)
+	}
+}
@@ -0,0 +1,115 @@
+package scriptexec
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"rawrequest/internal/eventloop"
+	sd "rawrequest/internal/scriptdebug"
+	sr "rawrequest/internal/scriptruntime"
+
+	"github.com/dop251/goja"
+)
+
+// installDebugHook instruments the script and registers the hook it calls
+// before each statement. It returns the script to run; when instrumentation
+// fails the original script runs undebugged.
+func installDebugHook(vm *goja.Runtime, debugger *sd.Controller, cleanScript string, ctx *sr.ExecutionContext, source string, clock *eventloop.Watchdog, log func(level, message string)) string {
+	instrumented, err := sd.Instrument(cleanScript)
+	if err != nil {
+		log("warn", fmt.Sprintf("debugger disabled for this script: %v", err))
+		return cleanScript
+	}
+
+	stepping := false
+	_ = vm.Set(sd.HookName, func(call goja.FunctionCall) goja.Value {
+		line := int(call.Argument(0).ToInteger())
+		isDebugger := call.Argument(1).ToBoolean()
+		reason, ok := debugger.ShouldPause(source, line, isDebugger, stepping)
+		if !ok {
+			return goja.Undefined()
+		}
+		state := sd.PausedState{
+			Source:    source,
+			Stage:     ctx.Stage,
+			Line:      line,
+			Reason:    reason,
+			Locals:    readLocals(vm, call.Argument(2), call.Argument(3)),
+			Request:   jsonSafe(ctx.Request),
+			Response:  jsonSafe(ctx.Response),
+			Variables: cloneStringMap(ctx.Variables),
+		}
+		// Time spent paused does not count against the script timeout.
+		clock.Suspend()
+		stepping = debugger.Pause(state) == sd.ActionStep
+		clock.Resume()
+		return goja.Undefined()
+	})
+	return instrumented
+}
+
+// readLocals evaluates each candidate name through the eval closure created at
+// the paused statement. Names that are not initialized yet or not in scope
+// there throw and are skipped.
+func readLocals(vm *goja.Runtime, namesValue, evalValue goja.Value) map[string]interface{} {
+	locals := map[string]interface{}{}
+	evalFn, ok := goja.AssertFunction(evalValue)
+	if !ok {
+		return locals
+	}
+	var names []string
+	if err := vm.ExportTo(namesValue, &names); err != nil {
+		return locals
+	}
+	for _, name := range names {
+		v, err := evalFn(goja.Undefined(), vm.ToValue(name))
+		if err != nil {
+			continue
+		}
+		if _, isFn := goja.AssertFunction(v); isFn {
+			locals[name] = "[function]"
+			continue
+		}
+		locals[name] = jsonSafe(v.Export())
+	}
+	return locals
+}
+
+// jsonSafe deep-copies v into plain JSON values so the paused state can be
+// encoded after the script mutates or releases the originals.
+func jsonSafe(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(stripFuncs(v))
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return string(data)
+	}
+	return out
+}
+
+func stripFuncs(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(t))
+		for k, item := range t {
+			out[k] = stripFuncs(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(t))
+		for i, item := range t {
+			out[i] = stripFuncs(item)
+		}
+		return out
+	}
+	if v != nil && reflect.TypeOf(v).Kind() == reflect.Func {
+		return "[function]"
+	}
+	return v
+}
@@ -6,6 +6,8 @@ import (
 	"sync"
 	"time"
 
+	"rawrequest/internal/eventloop"
+	sd "rawrequest/internal/scriptdebug"
 	sh "rawrequest/internal/scripthelpers"
 	so "rawrequest/internal/scriptops"
 	sr "rawrequest/internal/scriptruntime"
@@ -13,6 +15,10 @@ import (
 	"github.com/dop251/goja"
 )
 
+// DefaultTimeout bounds a script run, including the time spent waiting on
+// timers and promises after the script body returns.
+const DefaultTimeout = eventloop.DefaultTimeout
+
 var vmPool = sync.Pool{
 	New: func() interface{} {
 		return goja.New()
@@ -25,6 +31,12 @@ type Dependencies struct {
 	SetVar            func(key, value string)
 	AppendLog         func(level, source, message string)
 	Sleep             func(time.Duration)
+	// Timeout bounds the whole run, including pending timers and promises.
+	// Zero means DefaultTimeout.
+	Timeout time.Duration
+	// Debugger, when enabled, instruments the script so `debugger;`
+	// statements and breakpoints pause it.
+	Debugger *sd.Controller
 }
 
 type assertionFailure struct {
@@ -66,16 +78,22 @@ func Execute(cleanScript string, ctx *sr.ExecutionContext, stage string, deps De
 	}
 
 	vm := vmPool.Get().(*goja.Runtime)
+	// A Go panic (e.g. a failed assert) or an interrupted run can leave goja's
+	// call stack dirty, which stops promise jobs from ever running on that VM
+	// again, so only VMs whose run completed go back to the pool.
+	discard := false
 	defer func() {
+		if discard {
+			return
+		}
 		vm.ClearInterrupt()
 		vmPool.Put(vm)
 	}()
 	_ = vm.Set("context", ctx)
 
 	// Provide top-level aliases commonly used by scripts/examples.
-	// `request` is always available (mutable via helpers).
+	// `request` is always available (mutable via helpers, see installFlowControl).
 	// `response` is always defined; for pre-scripts it is null.
-	_ = vm.Set("request", ctx.Request)
 	if ctx.Response == nil {
 		_ = vm.Set("response", goja.Null())
 	} else {
@@ -90,6 +108,8 @@ func Execute(cleanScript string, ctx *sr.ExecutionContext, stage string, deps De
 		}
 	}
 
+	installFlowControl(vm, ctx, stage, log)
+
 	_ = vm.Set("setVar", func(call goja.FunctionCall) goja.Value {
 		if len(call.Arguments) < 2 {
 			return goja.Undefined()
@@ -185,6 +205,7 @@ func Execute(cleanScript string, ctx *sr.ExecutionContext, stage string, deps De
 
 	defer func() {
 		if r := recover(); r != nil {
+			discard = true
 			if _, ok := r.(assertionFailure); ok {
 				return
 			}
@@ -192,20 +213,70 @@ func Execute(cleanScript string, ctx *sr.ExecutionContext, stage string, deps De
 		}
 	}()
 
+	timeout := deps.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	clock := eventloop.StartWatchdog(vm, timeout)
+	defer func() {
+		// A timeout may interrupt the VM at any point, even after the run
+		// returned, so such a VM is never pooled.
+		if clock.Stop() {
+			discard = true
+		}
+	}()
+	loop := eventloop.Install(vm)
+	rejections := &rejectionTracker{}
+	vm.SetPromiseRejectionTracker(rejections.track)
+	defer vm.SetPromiseRejectionTracker(nil)
+
+	if deps.Debugger.Enabled() {
+		cleanScript = installDebugHook(vm, deps.Debugger, cleanScript, ctx, source, clock, log)
+	}
+
 	// Execute inside a wrapper function that provides common globals as parameters.
 	// This avoids ReferenceError issues if scripts assume `response` exists even in pre-scripts
-	// and keeps any script-provided 'use strict' directive valid.
+	// and keeps any script-provided 'use strict' directive valid. The inner function is
+	// async so scripts can use top-level `await`; it runs synchronously until the first one.
 	wrappedScript := fmt.Sprintf(
 		"(function(__g){\n"+
-			"(function(context, request, response){\n%s\n"+
+			"return (async function(context, request, response){\n%s\n"+
 			"})(__g.context, __g.request, __g.response);\n"+
 			"})(Function('return this')());",
 		cleanScript,
 	)
 
-	if _, err := vm.RunString(wrappedScript); err != nil {
-		log("error", fmt.Sprintf("runtime error: %v", err))
+	result, err := vm.RunString(wrappedScript)
+	if err == nil {
+		err = loop.Run(clock.Remaining, nil)
+	}
+	if err != nil {
+		discard = true
+		log("error", describeRunError(err, timeout))
+		return
+	}
+
+	main, _ := result.Export().(*goja.Promise)
+	if main != nil {
+		switch main.State() {
+		case goja.PromiseStateRejected:
+			log("error", fmt.Sprintf("runtime error: %s", describeRejection(main.Result())))
+		case goja.PromiseStatePending:
+			log("warn", "script finished with an await that never settled")
+		}
+	}
+	for _, p := range rejections.unhandled {
+		if p != main {
+			log("error", fmt.Sprintf("unhandled promise rejection: %s", describeRejection(p.Result())))
+		}
+	}
+}
+
+func describeRunError(err error, timeout time.Duration) string {
+	if eventloop.IsTimeout(err) {
+		return fmt.Sprintf("script timed out after %s", timeout)
 	}
+	return fmt.Sprintf("runtime error: %v", err)
 }
 
 func cloneStringMap(in map[string]string) map[string]string {
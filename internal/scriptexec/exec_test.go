@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	sd "rawrequest/internal/scriptdebug"
 	sr "rawrequest/internal/scriptruntime"
 )
 
@@ -164,3 +165,192 @@ func TestExecute_ResponseIsAvailableInPostScripts(t *testing.T) {
 		t.Fatalf("unexpected error logs: %d", count)
 	}
 }
+
+func TestExecute_DebuggerPausesWithLocals(t *testing.T) {
+	var states []sd.PausedState
+	var ctl *sd.Controller
+	ctl = sd.NewController(func(s sd.PausedState) {
+		states = append(states, s)
+		action := sd.ActionContinue
+		if len(states) == 1 {
+			action = sd.ActionStep
+		}
+		_ = ctl.Resume(s.ID, action)
+	}, nil)
+	ctl.Configure(true, nil)
+
+	ctx := &sr.ExecutionContext{
+		Request:   map[string]interface{}{"method": "GET", "url": "http://example.com"},
+		Response:  map[string]interface{}{"status": 200},
+		Variables: map[string]string{"token": "abc"},
+	}
+	script := "const user = { id: 7 };\ndebugger;\nsetVar('uid', String(user.id));\nconsole.log('done');"
+	Execute(script, ctx, "post", Dependencies{Debugger: ctl})
+
+	if len(states) != 2 {
+		t.Fatalf("pauses=%d want 2 (debugger then step)", len(states))
+	}
+	first := states[0]
+	if first.Reason != sd.ReasonDebugger || first.Line != 2 || first.Stage != "post" {
+		t.Fatalf("unexpected first pause %+v", first)
+	}
+	user, _ := first.Locals["user"].(map[string]interface{})
+	if user["id"] != float64(7) {
+		t.Fatalf("locals.user=%v", first.Locals["user"])
+	}
+	if first.Variables["token"] != "abc" {
+		t.Fatalf("variables=%v", first.Variables)
+	}
+	if states[1].Reason != sd.ReasonStep || states[1].Line != 3 {
+		t.Fatalf("unexpected step pause %+v", states[1])
+	}
+	if ctx.Variables["uid"] != "7" {
+		t.Fatalf("script did not finish after resume, vars=%v", ctx.Variables)
+	}
+}
+
+func TestExecute_DebuggerDisabledSkipsInstrumentation(t *testing.T) {
+	ctl := sd.NewController(func(sd.PausedState) {
+		t.Fatalf("disabled debugger must not pause")
+	}, nil)
+	ctx := &sr.ExecutionContext{}
+	Execute("debugger; setVar('ok', '1');", ctx, "pre", Dependencies{Debugger: ctl})
+	if ctx.Variables["ok"] != "1" {
+		t.Fatalf("script did not run, vars=%v", ctx.Variables)
+	}
+}
+
+func TestExecute_AwaitsTimersAndPromises(t *testing.T) {
+	ctx := &sr.ExecutionContext{}
+	var logs []logEntry
+	script := `
+const wait = (ms, v) => new Promise(resolve => setTimeout(resolve, ms, v));
+const order = [];
+setTimeout(() => order.push('late'), 20);
+setTimeout(() => order.push('early'), 5);
+queueMicrotask(() => order.push('micro'));
+const value = await wait(10, 'ok');
+setVar('value', value);
+async function later() {
+  await wait(15);
+  setVar('order', order.join(','));
+}
+later();
+`
+	Execute(script, ctx, "post", Dependencies{
+		AppendLog: func(level, source, message string) {
+			logs = append(logs, logEntry{level: level, source: source, message: message})
+		},
+	})
+	if len(logs) != 0 {
+		t.Fatalf("unexpected logs: %+v", logs)
+	}
+	if ctx.Variables["value"] != "ok" {
+		t.Fatalf("value=%q want ok", ctx.Variables["value"])
+	}
+	if ctx.Variables["order"] != "micro,early,late" {
+		t.Fatalf("order=%q want micro,early,late", ctx.Variables["order"])
+	}
+}
+
+func TestExecute_TimeoutStopsPendingTimers(t *testing.T) {
+	cases := map[string]string{
+		"interval":  "setInterval(() => {}, 5);",
+		"busy loop": "while (true) {}",
+	}
+	for name, script := range cases {
+		t.Run(name, func(t *testing.T) {
+			var logs []logEntry
+			start := time.Now()
+			Execute(script, &sr.ExecutionContext{}, "pre", Dependencies{
+				Timeout: 50 * time.Millisecond,
+				AppendLog: func(level, source, message string) {
+					logs = append(logs, logEntry{level: level, source: source, message: message})
+				},
+			})
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("script ran for %s, want about 50ms", elapsed)
+			}
+			if len(logs) != 1 || !strings.Contains(logs[0].message, "timed out") {
+				t.Fatalf("logs=%+v want a single timeout error", logs)
+			}
+		})
+	}
+}
+
+func TestExecute_ReportsAsyncErrors(t *testing.T) {
+	var logs []logEntry
+	ctx := &sr.ExecutionContext{}
+	script := `
+(async () => { throw new Error('background'); })();
+await null;
+assert(true, 'before');
+missingFn();
+`
+	Execute(script, ctx, "post", Dependencies{
+		AppendLog: func(level, source, message string) {
+			logs = append(logs, logEntry{level: level, source: source, message: message})
+		},
+	})
+	if len(ctx.Assertions) != 1 || !ctx.Assertions[0].Passed {
+		t.Fatalf("assertions=%+v want one passed", ctx.Assertions)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("logs=%+v want 2", logs)
+	}
+	if !strings.Contains(logs[0].message, "runtime error: ReferenceError: missingFn") {
+		t.Fatalf("first log=%q want runtime error for missingFn", logs[0].message)
+	}
+	if !strings.Contains(logs[1].message, "unhandled promise rejection: Error: background") {
+		t.Fatalf("second log=%q want unhandled rejection", logs[1].message)
+	}
+}
+
+func TestExecute_AssertAfterAwaitStopsScript(t *testing.T) {
+	ctx := &sr.ExecutionContext{}
+	Execute("await new Promise(r => setTimeout(r, 1));\nassert(false, 'nope');\nsetVar('after', '1');", ctx, "post", Dependencies{})
+	if len(ctx.Assertions) != 1 || ctx.Assertions[0].Passed || ctx.Assertions[0].Message != "nope" {
+		t.Fatalf("assertions=%+v want single failed 'nope'", ctx.Assertions)
+	}
+	if _, ok := ctx.Variables["after"]; ok {
+		t.Fatalf("script kept running after failed assert")
+	}
+}
+
+func TestExecute_FlowControlAPIs(t *testing.T) {
+	ctx := &sr.ExecutionContext{
+		Request:   map[string]interface{}{"method": "GET", "url": "http://x"},
+		Iteration: 2,
+	}
+	Execute("request.url = 'http://y';\nrequest.skip('exists');\nchain.next('poll');\nchain.stop('done');\nsetVar('iter', String(chain.iteration));\nsetVar('keys', Object.keys(request).sort().join(','));", ctx, "pre", Dependencies{})
+
+	want := sr.FlowControl{Skipped: true, SkipReason: "exists", Stopped: true, StopReason: "done", Next: "poll"}
+	if ctx.Flow != want {
+		t.Fatalf("flow=%+v want %+v", ctx.Flow, want)
+	}
+	if ctx.Request["url"] != "http://y" {
+		t.Fatalf("request.url=%v, writes must reach the request map", ctx.Request["url"])
+	}
+	if _, leaked := ctx.Request["skip"]; leaked {
+		t.Fatalf("skip must not be stored on the request map")
+	}
+	if ctx.Variables["iter"] != "2" || ctx.Variables["keys"] != "method,url" {
+		t.Fatalf("vars=%v", ctx.Variables)
+	}
+}
+
+func TestExecute_SkipIgnoredInPostScript(t *testing.T) {
+	var logs []logEntry
+	ctx := &sr.ExecutionContext{Response: map[string]interface{}{"status": 200}}
+	Execute("request.skip('late')", ctx, "post", Dependencies{
+		AppendLog: func(level, source, message string) {
+			logs = append(logs, logEntry{level: level, source: source, message: message})
+		},
+	})
+	if ctx.Flow.Skipped {
+		t.Fatalf("post-script skip must be ignored")
+	}
+	if len(logs) != 1 || logs[0].level != "warn" {
+		t.Fatalf("logs=%+v want one warning", logs)
+	}
+}
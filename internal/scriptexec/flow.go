@@ -0,0 +1,57 @@
+package scriptexec
+
+import (
+	"strings"
+
+	so "rawrequest/internal/scriptops"
+	sr "rawrequest/internal/scriptruntime"
+
+	"github.com/dop251/goja"
+)
+
+// installFlowControl exposes request.skip() and the chain object. `request`
+// is a proxy over ctx.Request so skip() is callable without being stored in
+// the request map that is sent and serialized.
+func installFlowControl(vm *goja.Runtime, ctx *sr.ExecutionContext, stage string, log func(level, message string)) {
+	skip := vm.ToValue(func(call goja.FunctionCall) goja.Value {
+		if stage != "pre" {
+			log("warn", "request.skip() only has an effect in pre-scripts")
+			return goja.Undefined()
+		}
+		so.Skip(ctx, reasonArg(call))
+		return goja.Undefined()
+	})
+	target := vm.ToValue(ctx.Request).ToObject(vm)
+	proxy := vm.NewProxy(target, &goja.ProxyTrapConfig{
+		Get: func(target *goja.Object, property string, receiver goja.Value) goja.Value {
+			if property == "skip" {
+				return skip
+			}
+			return target.Get(property)
+		},
+	})
+	_ = vm.Set("request", proxy)
+
+	chain := vm.NewObject()
+	_ = chain.Set("iteration", ctx.Iteration)
+	_ = chain.Set("stop", func(call goja.FunctionCall) goja.Value {
+		so.StopChain(ctx, reasonArg(call))
+		return goja.Undefined()
+	})
+	_ = chain.Set("next", func(call goja.FunctionCall) goja.Value {
+		name := strings.TrimSpace(call.Argument(0).String())
+		if goja.IsUndefined(call.Argument(0)) || name == "" {
+			panic(vm.NewTypeError("chain.next() needs a request name"))
+		}
+		so.NextRequest(ctx, name)
+		return goja.Undefined()
+	})
+	_ = vm.Set("chain", chain)
+}
+
+func reasonArg(call goja.FunctionCall) string {
+	if arg := call.Argument(0); !goja.IsUndefined(arg) && !goja.IsNull(arg) {
+		return arg.String()
+	}
+	return ""
+}
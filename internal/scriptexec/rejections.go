@@ -0,0 +1,46 @@
+package scriptexec
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// rejectionTracker records promises rejected without a handler, so failures
+// in fire-and-forget async calls still reach the script log.
+type rejectionTracker struct {
+	unhandled []*goja.Promise
+}
+
+func (t *rejectionTracker) track(p *goja.Promise, op goja.PromiseRejectionOperation) {
+	switch op {
+	case goja.PromiseRejectionReject:
+		t.unhandled = append(t.unhandled, p)
+	case goja.PromiseRejectionHandle:
+		for i, candidate := range t.unhandled {
+			if candidate == p {
+				t.unhandled = append(t.unhandled[:i], t.unhandled[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// describeRejection formats a rejected promise's reason like a goja
+// exception ("TypeError: msg at <eval>:3:5(12)").
+func describeRejection(reason goja.Value) string {
+	if reason == nil || goja.IsUndefined(reason) || goja.IsNull(reason) {
+		return fmt.Sprint(reason)
+	}
+	if obj, ok := reason.(*goja.Object); ok {
+		if stack := obj.Get("stack"); stack != nil && !goja.IsUndefined(stack) {
+			lines := strings.Split(strings.TrimSpace(stack.String()), "\n")
+			if len(lines) > 1 {
+				return lines[0] + " " + strings.TrimSpace(lines[1])
+			}
+			return lines[0]
+		}
+	}
+	return reason.String()
+}
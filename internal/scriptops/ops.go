@@ -80,3 +80,32 @@ func Delay(duration time.Duration, sleep func(time.Duration)) {
 	}
 	sleep(duration)
 }
+
+// Skip marks the current request as skipped. It only has an effect from a
+// pre-script, before the request is sent.
+func Skip(ctx *sr.ExecutionContext, reason string) {
+	if ctx == nil {
+		return
+	}
+	ctx.Flow.Skipped = true
+	ctx.Flow.SkipReason = reason
+}
+
+// StopChain ends the chain after the current request, or before it when
+// called from a pre-script.
+func StopChain(ctx *sr.ExecutionContext, reason string) {
+	if ctx == nil {
+		return
+	}
+	ctx.Flow.Stopped = true
+	ctx.Flow.StopReason = reason
+}
+
+// NextRequest makes the chain continue with the named request instead of the
+// following one. The last call wins.
+func NextRequest(ctx *sr.ExecutionContext, name string) {
+	if ctx == nil {
+		return
+	}
+	ctx.Flow.Next = name
+}
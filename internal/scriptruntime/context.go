@@ -8,6 +8,21 @@ type AssertionResult struct {
 	Stage   string `json:"stage"`
 }
 
+// FlowControl records the chain decisions a script made through
+// request.skip(), chain.stop() and chain.next().
+type FlowControl struct {
+	Skipped    bool   `json:"skipped,omitempty"`
+	SkipReason string `json:"skipReason,omitempty"`
+	Stopped    bool   `json:"stopped,omitempty"`
+	StopReason string `json:"stopReason,omitempty"`
+	Next       string `json:"next,omitempty"`
+}
+
+// IsZero reports whether no flow decision was made.
+func (f FlowControl) IsZero() bool {
+	return f == FlowControl{}
+}
+
 type ExecutionContext struct {
 	Request       map[string]interface{}            `json:"request"`
 	Response      map[string]interface{}            `json:"response"`
@@ -15,6 +30,10 @@ type ExecutionContext struct {
 	ResponseStore map[string]map[string]interface{} `json:"responseStore"`
 	Stage         string                            `json:"stage"`
 	Assertions    []AssertionResult                 `json:"assertions"`
+	Flow          FlowControl                       `json:"flow"`
+	// Iteration is how many times the current request has run in this chain,
+	// starting at 1; chain.next() loops raise it.
+	Iteration int `json:"iteration"`
 }
 
 func BuildSource(ctx *ExecutionContext) string {
@@ -2,9 +2,13 @@ package templating
 
 import (
 	"encoding/json"
+	"math/rand"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 var variableRegex = regexp.MustCompile(`\{\{([^}]+)\}\}`)
@@ -21,6 +25,13 @@ func Resolve(input string, variables map[string]string, envVars map[string]strin
 			return match
 		}
 
+		if strings.HasPrefix(expr, "$") {
+			if val, ok := dynamicValue(expr); ok {
+				return val
+			}
+			return match
+		}
+
 		parts := strings.Split(expr, ".")
 		if len(parts) == 0 {
 			return match
@@ -96,6 +107,33 @@ func Resolve(input string, variables map[string]string, envVars map[string]strin
 	})
 }
 
+// dynamicValue evaluates a {{$function}} placeholder. Values are generated
+// on every call, so each resolved request gets its own.
+func dynamicValue(expr string) (string, bool) {
+	fields := strings.Fields(expr)
+	switch fields[0] {
+	case "$uuid", "$guid", "$randomUUID":
+		return uuid.NewString(), true
+	case "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), true
+	case "$isoTimestamp":
+		return time.Now().UTC().Format(time.RFC3339), true
+	case "$randomInt":
+		// {{$randomInt}} or {{$randomInt min max}}, max exclusive.
+		min, max := 0, 1000
+		if len(fields) == 3 {
+			lo, errLo := strconv.Atoi(fields[1])
+			hi, errHi := strconv.Atoi(fields[2])
+			if errLo != nil || errHi != nil || hi <= lo {
+				return "", false
+			}
+			min, max = lo, hi
+		}
+		return strconv.Itoa(min + rand.Intn(max-min)), true
+	}
+	return "", false
+}
+
 // getJSONValue extracts a value from JSON using dot notation.
 // This matches the existing app behavior: objects-only traversal (no array indexing).
 func getJSONValue(data map[string]interface{}, path string) string {
@@ -79,3 +79,17 @@ func TestResolve_UnknownOrUnparseableLeftUnchanged(t *testing.T) {
 		t.Fatalf("unexpected resolve: %q", got)
 	}
 }
+
+func TestResolve_DynamicFunctions(t *testing.T) {
+	first := Resolve("{{$uuid}}", nil, nil, nil)
+	second := Resolve("{{$uuid}}", nil, nil, nil)
+	if len(first) != 36 || first == second {
+		t.Fatalf("expected a fresh uuid per call, got %q and %q", first, second)
+	}
+	if got := Resolve("{{$randomInt 5 6}}", nil, nil, nil); got != "5" {
+		t.Fatalf("unexpected randomInt: %q", got)
+	}
+	if got := Resolve("{{$nope}} {{$randomInt 9 1}}", nil, nil, nil); got != "{{$nope}} {{$randomInt 9 1}}" {
+		t.Fatalf("unknown functions should stay as-is: %q", got)
+	}
+}